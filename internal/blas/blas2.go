@@ -2,11 +2,12 @@ package blas
 
 import "github.com/tab58/go-optimize/internal/linalg"
 
+// GEMV performs the matrix-vector multiplication y = alpha * A * x + beta * y
 func GEMV(alpha float64, A linalg.Matrix, x linalg.Vector, beta float64, y linalg.Vector) {
-	if A.Rows() != x.Len() {
+	if A.Cols() != x.Len() {
 		panic(linalg.ErrDimensionMismatch)
 	}
-	if A.Cols() != y.Len() {
+	if A.Rows() != y.Len() {
 		panic(linalg.ErrDimensionMismatch)
 	}
 
@@ -17,3 +18,26 @@ func GEMV(alpha float64, A linalg.Matrix, x linalg.Vector, beta float64, y linal
 		y[i] = beta*y[i] + alpha*Ax
 	}
 }
+
+// GEMVT performs the transposed matrix-vector multiplication y = alpha * A^T * x + beta * y
+func GEMVT(alpha float64, A linalg.Matrix, x linalg.Vector, beta float64, y linalg.Vector) {
+	if A.Rows() != x.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if A.Cols() != y.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	for j := range A.Cols() {
+		y[j] *= beta
+	}
+	for i := range A.Rows() {
+		axi := alpha * x[i]
+		if axi == 0 {
+			continue
+		}
+		for j := range A.Cols() {
+			y[j] += axi * A.Get(i, j)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package blas_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+func TestGEMVNonSquare(t *testing.T) {
+	A := linalg.NewDenseMatrix(2, 3)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			A.Set(i, j, float64(3*i+j+1))
+		}
+	}
+	x := linalg.Vector{1, -1, 2}
+	y := linalg.Vector{1, 1}
+	blas.GEMV(2, A, x, 1, y)
+	if y[0] != 11 || y[1] != 23 {
+		t.Errorf("expected [11 23], got %v", y)
+	}
+
+	// the shapes of A^T * x are a mismatch
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, linalg.ErrDimensionMismatch) {
+			t.Errorf("expected ErrDimensionMismatch, got %v", err)
+		}
+	}()
+	blas.GEMV(1, A, linalg.Vector{1, 1}, 0, linalg.NewVector(3))
+	t.Errorf("expected transposed shapes to panic")
+}
@@ -0,0 +1,44 @@
+package blas
+
+import "github.com/tab58/go-optimize/internal/linalg"
+
+// SPMV performs the sparse matrix-vector multiplication y = alpha * A * x + beta * y
+func SPMV(alpha float64, A linalg.SparseMatrix, x linalg.Vector, beta float64, y linalg.Vector) {
+	if A.Cols() != x.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if A.Rows() != y.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	SCAL(beta, y)
+	for j := range A.Cols() {
+		axj := alpha * x[j]
+		if axj == 0 {
+			continue
+		}
+		rows, values := A.Column(j)
+		for k, i := range rows {
+			y[i] += axj * values[k]
+		}
+	}
+}
+
+// SPMVT performs the transposed sparse matrix-vector multiplication y = alpha * A^T * x + beta * y
+func SPMVT(alpha float64, A linalg.SparseMatrix, x linalg.Vector, beta float64, y linalg.Vector) {
+	if A.Rows() != x.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if A.Cols() != y.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	for j := range A.Cols() {
+		rows, values := A.Column(j)
+		sum := 0.0
+		for k, i := range rows {
+			sum += values[k] * x[i]
+		}
+		y[j] = beta*y[j] + alpha*sum
+	}
+}
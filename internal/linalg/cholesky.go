@@ -0,0 +1,93 @@
+package linalg
+
+import "math"
+
+// Cholesky is the Cholesky factorization of a symmetric positive definite matrix: A = L * L^T.
+type Cholesky struct {
+	l Matrix
+}
+
+// FactorCholesky computes the Cholesky factorization of A. Only the lower triangle
+// of A is referenced and A is not modified.
+//
+// Returns ErrNotPositiveDefinite if A is not positive definite.
+func FactorCholesky(A Matrix) (Cholesky, error) {
+	if A.Rows() != A.Cols() {
+		panic(ErrDimensionMismatch)
+	}
+	n := A.Rows()
+	l := NewDenseMatrix(n, n)
+	for j := 0; j < n; j++ {
+		d := A.Get(j, j)
+		for k := 0; k < j; k++ {
+			d -= l.Get(j, k) * l.Get(j, k)
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return Cholesky{}, ErrNotPositiveDefinite
+		}
+		d = math.Sqrt(d)
+		l.Set(j, j, d)
+		for i := j + 1; i < n; i++ {
+			sum := A.Get(i, j)
+			for k := 0; k < j; k++ {
+				sum -= l.Get(i, k) * l.Get(j, k)
+			}
+			l.Set(i, j, sum/d)
+		}
+	}
+	return Cholesky{l: l}, nil
+}
+
+// Size returns the order of the factored matrix.
+func (c Cholesky) Size() int {
+	return c.l.Rows()
+}
+
+// L returns the lower triangular factor.
+func (c Cholesky) L() Matrix {
+	return c.l
+}
+
+// Solve solves A * x = b for x. b and x may be the same vector.
+func (c Cholesky) Solve(b Vector, x Vector) {
+	n := c.l.Rows()
+	if b.Len() != n || x.Len() != n {
+		panic(ErrDimensionMismatch)
+	}
+	copy(x, b)
+
+	// L * y = b
+	for i := 0; i < n; i++ {
+		sum := x[i]
+		for k := 0; k < i; k++ {
+			sum -= c.l.Get(i, k) * x[k]
+		}
+		x[i] = sum / c.l.Get(i, i)
+	}
+
+	// L^T * x = y
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		for k := i + 1; k < n; k++ {
+			sum -= c.l.Get(k, i) * x[k]
+		}
+		x[i] = sum / c.l.Get(i, i)
+	}
+}
+
+// Inverse writes the inverse of the factored matrix into Ainv.
+func (c Cholesky) Inverse(Ainv Matrix) {
+	n := c.l.Rows()
+	if Ainv.Rows() != n || Ainv.Cols() != n {
+		panic(ErrDimensionMismatch)
+	}
+	col := NewVector(n)
+	for j := 0; j < n; j++ {
+		col.Zero()
+		col[j] = 1
+		c.Solve(col, col)
+		for i := 0; i < n; i++ {
+			Ainv.Set(i, j, col[i])
+		}
+	}
+}
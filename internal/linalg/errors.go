@@ -3,6 +3,8 @@ package linalg
 import "errors"
 
 var (
-	ErrIndexOutOfBounds = errors.New("index out of bounds")
-	ErrDimensionMismatch = errors.New("dimension mismatch")
+	ErrIndexOutOfBounds    = errors.New("index out of bounds")
+	ErrDimensionMismatch   = errors.New("dimension mismatch")
+	ErrSingularMatrix      = errors.New("matrix is singular")
+	ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
 )
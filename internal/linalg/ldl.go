@@ -0,0 +1,259 @@
+package linalg
+
+import "math"
+
+// LDL is the Bunch-Kaufman factorization of a symmetric indefinite matrix:
+//
+//	P * A * P^T = L * D * L^T
+//
+// where L is unit lower triangular and D is block diagonal with 1x1 and 2x2 blocks.
+type LDL struct {
+	l     Matrix
+	d     Vector // diagonal of D
+	e     Vector // e[k] is the off-diagonal of the 2x2 block starting at k
+	block []int  // size of the block starting at k, 0 for the second row of a 2x2 block
+	perm  []int
+}
+
+// bunchKaufmanAlpha is (1 + sqrt(17)) / 8, which minimizes the element growth bound.
+var bunchKaufmanAlpha = (1 + math.Sqrt(17)) / 8
+
+// FactorLDL computes the Bunch-Kaufman factorization of the symmetric matrix A. Only
+// the lower triangle of A is referenced and A is not modified.
+//
+// A singular matrix does not cause an error; its zero pivots are reported by Inertia.
+func FactorLDL(A Matrix) LDL {
+	if A.Rows() != A.Cols() {
+		panic(ErrDimensionMismatch)
+	}
+	n := A.Rows()
+
+	// work on a full symmetric copy
+	a := NewDenseMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			a.Set(i, j, A.Get(i, j))
+			a.Set(j, i, A.Get(i, j))
+		}
+	}
+	f := LDL{
+		l:     NewDenseMatrix(n, n),
+		d:     NewVector(n),
+		e:     NewVector(n),
+		block: make([]int, n),
+		perm:  make([]int, n),
+	}
+	for i := range f.perm {
+		f.perm[i] = i
+	}
+
+	swap := func(i, j int) {
+		if i == j {
+			return
+		}
+		for k := 0; k < n; k++ {
+			t := a.Get(i, k)
+			a.Set(i, k, a.Get(j, k))
+			a.Set(j, k, t)
+		}
+		for k := 0; k < n; k++ {
+			t := a.Get(k, i)
+			a.Set(k, i, a.Get(k, j))
+			a.Set(k, j, t)
+		}
+		// swap the already computed columns of L
+		for k := 0; k < n; k++ {
+			t := f.l.Get(i, k)
+			f.l.Set(i, k, f.l.Get(j, k))
+			f.l.Set(j, k, t)
+		}
+		f.perm[i], f.perm[j] = f.perm[j], f.perm[i]
+	}
+
+	k := 0
+	for k < n {
+		akk := math.Abs(a.Get(k, k))
+		lambda := 0.0
+		r := k
+		for i := k + 1; i < n; i++ {
+			if v := math.Abs(a.Get(i, k)); v > lambda {
+				lambda = v
+				r = i
+			}
+		}
+
+		size := 1
+		if math.Max(akk, lambda) == 0 {
+			// zero column, D[k] = 0
+		} else if akk < bunchKaufmanAlpha*lambda {
+			sigma := 0.0
+			for j := k; j < n; j++ {
+				if j != r {
+					sigma = math.Max(sigma, math.Abs(a.Get(r, j)))
+				}
+			}
+			if akk*sigma >= bunchKaufmanAlpha*lambda*lambda {
+				// 1x1 pivot at k
+			} else if math.Abs(a.Get(r, r)) >= bunchKaufmanAlpha*sigma {
+				swap(k, r)
+			} else {
+				swap(k+1, r)
+				size = 2
+			}
+		}
+
+		if size == 1 {
+			dk := a.Get(k, k)
+			f.d[k] = dk
+			f.block[k] = 1
+			f.l.Set(k, k, 1)
+			if dk != 0 {
+				for i := k + 1; i < n; i++ {
+					f.l.Set(i, k, a.Get(i, k)/dk)
+				}
+				for i := k + 1; i < n; i++ {
+					lik := f.l.Get(i, k)
+					if lik == 0 {
+						continue
+					}
+					for j := k + 1; j <= i; j++ {
+						v := a.Get(i, j) - lik*a.Get(j, k)
+						a.Set(i, j, v)
+						a.Set(j, i, v)
+					}
+				}
+			}
+			k++
+			continue
+		}
+
+		// 2x2 pivot on rows k, k+1
+		d11 := a.Get(k, k)
+		d21 := a.Get(k+1, k)
+		d22 := a.Get(k+1, k+1)
+		det := d11*d22 - d21*d21
+		f.d[k] = d11
+		f.d[k+1] = d22
+		f.e[k] = d21
+		f.block[k] = 2
+		f.block[k+1] = 0
+		f.l.Set(k, k, 1)
+		f.l.Set(k+1, k+1, 1)
+		for i := k + 2; i < n; i++ {
+			ai1 := a.Get(i, k)
+			ai2 := a.Get(i, k+1)
+			f.l.Set(i, k, (ai1*d22-ai2*d21)/det)
+			f.l.Set(i, k+1, (ai2*d11-ai1*d21)/det)
+		}
+		for i := k + 2; i < n; i++ {
+			li1 := f.l.Get(i, k)
+			li2 := f.l.Get(i, k+1)
+			for j := k + 2; j <= i; j++ {
+				v := a.Get(i, j) - li1*a.Get(j, k) - li2*a.Get(j, k+1)
+				a.Set(i, j, v)
+				a.Set(j, i, v)
+			}
+		}
+		k += 2
+	}
+	return f
+}
+
+// Size returns the order of the factored matrix.
+func (f LDL) Size() int {
+	return f.l.Rows()
+}
+
+// Inertia returns the number of positive, negative and zero eigenvalues of the factored matrix.
+func (f LDL) Inertia() (positive, negative, zero int) {
+	n := f.d.Len()
+	scale := 0.0
+	for k := 0; k < n; k++ {
+		scale = math.Max(scale, math.Max(math.Abs(f.d[k]), math.Abs(f.e[k])))
+	}
+	tiny := 1e-14 * scale
+	for k := 0; k < n; k++ {
+		switch f.block[k] {
+		case 1:
+			if math.Abs(f.d[k]) <= tiny {
+				zero++
+			} else if f.d[k] > 0 {
+				positive++
+			} else {
+				negative++
+			}
+		case 2:
+			det := f.d[k]*f.d[k+1] - f.e[k]*f.e[k]
+			tr := f.d[k] + f.d[k+1]
+			if math.Abs(det) <= tiny*tiny {
+				zero++
+				if tr > 0 {
+					positive++
+				} else {
+					negative++
+				}
+			} else if det < 0 {
+				positive++
+				negative++
+			} else if tr > 0 {
+				positive += 2
+			} else {
+				negative += 2
+			}
+		}
+	}
+	return positive, negative, zero
+}
+
+// Solve solves A * x = b for x. b and x may be the same vector. Zero pivots are
+// treated as zero components of the solution.
+func (f LDL) Solve(b Vector, x Vector) {
+	n := f.d.Len()
+	if b.Len() != n || x.Len() != n {
+		panic(ErrDimensionMismatch)
+	}
+	y := NewVector(n)
+	for i := 0; i < n; i++ {
+		y[i] = b[f.perm[i]]
+	}
+
+	// L * z = y
+	for i := 0; i < n; i++ {
+		sum := y[i]
+		for j := 0; j < i; j++ {
+			sum -= f.l.Get(i, j) * y[j]
+		}
+		y[i] = sum
+	}
+
+	// D * w = z
+	for k := 0; k < n; k++ {
+		switch f.block[k] {
+		case 1:
+			if f.d[k] != 0 {
+				y[k] /= f.d[k]
+			} else {
+				y[k] = 0
+			}
+		case 2:
+			d11, d21, d22 := f.d[k], f.e[k], f.d[k+1]
+			det := d11*d22 - d21*d21
+			y1, y2 := y[k], y[k+1]
+			y[k] = (d22*y1 - d21*y2) / det
+			y[k+1] = (d11*y2 - d21*y1) / det
+		}
+	}
+
+	// L^T * v = w
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for j := i + 1; j < n; j++ {
+			sum -= f.l.Get(j, i) * y[j]
+		}
+		y[i] = sum
+	}
+
+	for i := 0; i < n; i++ {
+		x[f.perm[i]] = y[i]
+	}
+}
@@ -0,0 +1,149 @@
+package linalg
+
+import "math"
+
+// LU is the LU factorization with partial pivoting of a square matrix: P * A = L * U.
+type LU struct {
+	lu  Matrix
+	piv []int
+}
+
+// FactorLU computes the LU factorization of A. A is not modified.
+//
+// Returns ErrSingularMatrix if a zero pivot is encountered.
+func FactorLU(A Matrix) (LU, error) {
+	if A.Rows() != A.Cols() {
+		panic(ErrDimensionMismatch)
+	}
+	n := A.Rows()
+	lu := NewDenseMatrix(n, n)
+	lu.Copy(A)
+	piv := make([]int, n)
+	for i := range piv {
+		piv[i] = i
+	}
+
+	for k := 0; k < n; k++ {
+		// find the pivot row
+		p := k
+		max := math.Abs(lu.Get(k, k))
+		for i := k + 1; i < n; i++ {
+			v := math.Abs(lu.Get(i, k))
+			if v > max {
+				max = v
+				p = i
+			}
+		}
+		if max == 0 {
+			return LU{}, ErrSingularMatrix
+		}
+		if p != k {
+			for j := 0; j < n; j++ {
+				a := lu.Get(k, j)
+				lu.Set(k, j, lu.Get(p, j))
+				lu.Set(p, j, a)
+			}
+			piv[k], piv[p] = piv[p], piv[k]
+		}
+
+		// eliminate below the pivot
+		pivot := lu.Get(k, k)
+		for i := k + 1; i < n; i++ {
+			lik := lu.Get(i, k) / pivot
+			lu.Set(i, k, lik)
+			if lik == 0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				lu.Set(i, j, lu.Get(i, j)-lik*lu.Get(k, j))
+			}
+		}
+	}
+	return LU{lu: lu, piv: piv}, nil
+}
+
+// Size returns the order of the factored matrix.
+func (f LU) Size() int {
+	return f.lu.Rows()
+}
+
+// Solve solves A * x = b for x. b and x may be the same vector.
+func (f LU) Solve(b Vector, x Vector) {
+	n := f.lu.Rows()
+	if b.Len() != n || x.Len() != n {
+		panic(ErrDimensionMismatch)
+	}
+	y := NewVector(n)
+	for i := 0; i < n; i++ {
+		y[i] = b[f.piv[i]]
+	}
+
+	// forward substitution with unit lower triangle
+	for i := 0; i < n; i++ {
+		sum := y[i]
+		for j := 0; j < i; j++ {
+			sum -= f.lu.Get(i, j) * y[j]
+		}
+		y[i] = sum
+	}
+
+	// back substitution with upper triangle
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for j := i + 1; j < n; j++ {
+			sum -= f.lu.Get(i, j) * y[j]
+		}
+		y[i] = sum / f.lu.Get(i, i)
+	}
+	copy(x, y)
+}
+
+// SolveTranspose solves A^T * x = b for x. b and x may be the same vector.
+func (f LU) SolveTranspose(b Vector, x Vector) {
+	n := f.lu.Rows()
+	if b.Len() != n || x.Len() != n {
+		panic(ErrDimensionMismatch)
+	}
+	y := NewVector(n)
+	copy(y, b)
+
+	// U^T * z = b
+	for i := 0; i < n; i++ {
+		sum := y[i]
+		for j := 0; j < i; j++ {
+			sum -= f.lu.Get(j, i) * y[j]
+		}
+		y[i] = sum / f.lu.Get(i, i)
+	}
+
+	// L^T * w = z
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for j := i + 1; j < n; j++ {
+			sum -= f.lu.Get(j, i) * y[j]
+		}
+		y[i] = sum
+	}
+
+	for i := 0; i < n; i++ {
+		x[f.piv[i]] = y[i]
+	}
+}
+
+// Inverse writes the inverse of the factored matrix into Ainv.
+func (f LU) Inverse(Ainv Matrix) {
+	n := f.lu.Rows()
+	if Ainv.Rows() != n || Ainv.Cols() != n {
+		panic(ErrDimensionMismatch)
+	}
+	e := NewVector(n)
+	col := NewVector(n)
+	for j := 0; j < n; j++ {
+		e.Zero()
+		e[j] = 1
+		f.Solve(e, col)
+		for i := 0; i < n; i++ {
+			Ainv.Set(i, j, col[i])
+		}
+	}
+}
@@ -55,6 +55,12 @@ func (m Matrix) Identity() {
 	}
 }
 
+func (m Matrix) Zero() {
+	for i := range m.data {
+		m.data[i] = 0
+	}
+}
+
 func (m Matrix) Copy(n Matrix) {
 	if m.rows != n.rows || m.cols != n.cols {
 		panic(ErrDimensionMismatch)
@@ -0,0 +1,187 @@
+package linalg
+
+import "math"
+
+// QR is the Householder QR factorization with column pivoting of an m x n matrix with
+// m >= n: A * P = Q * R. The diagonal of R is nonincreasing in magnitude, so the rank
+// of A is the number of diagonal entries above a tolerance.
+type QR struct {
+	qr   Matrix // R in the upper triangle, Householder vectors below the diagonal
+	tau  []float64
+	perm []int // column k of A * P is column perm[k] of A
+	rank int
+}
+
+// FactorQR computes the QR factorization of A. A is not modified.
+func FactorQR(A Matrix) QR {
+	m, n := A.Rows(), A.Cols()
+	if m < n {
+		panic(ErrDimensionMismatch)
+	}
+	qr := NewDenseMatrix(m, n)
+	qr.Copy(A)
+	tau := make([]float64, n)
+	perm := make([]int, n)
+	norms := make([]float64, n)
+	for j := range perm {
+		perm[j] = j
+	}
+
+	for k := 0; k < n; k++ {
+		// move the remaining column of largest norm to position k
+		p := k
+		for j := k; j < n; j++ {
+			norms[j] = 0
+			for i := k; i < m; i++ {
+				norms[j] = math.Hypot(norms[j], qr.Get(i, j))
+			}
+			if norms[j] > norms[p] {
+				p = j
+			}
+		}
+		if p != k {
+			for i := 0; i < m; i++ {
+				a := qr.Get(i, k)
+				qr.Set(i, k, qr.Get(i, p))
+				qr.Set(i, p, a)
+			}
+			perm[k], perm[p] = perm[p], perm[k]
+		}
+
+		// the reflection H = I - tau * v * v^T with v_k = 1 maps column k to beta * e_k
+		x0 := qr.Get(k, k)
+		norm := norms[p]
+		if norm == 0 {
+			continue
+		}
+		beta := -math.Copysign(norm, x0)
+		tau[k] = (beta - x0) / beta
+		scale := 1 / (x0 - beta)
+		for i := k + 1; i < m; i++ {
+			qr.Set(i, k, qr.Get(i, k)*scale)
+		}
+		qr.Set(k, k, beta)
+
+		for j := k + 1; j < n; j++ {
+			w := qr.Get(k, j)
+			for i := k + 1; i < m; i++ {
+				w += qr.Get(i, k) * qr.Get(i, j)
+			}
+			w *= tau[k]
+			qr.Set(k, j, qr.Get(k, j)-w)
+			for i := k + 1; i < m; i++ {
+				qr.Set(i, j, qr.Get(i, j)-w*qr.Get(i, k))
+			}
+		}
+	}
+
+	f := QR{qr: qr, tau: tau, perm: perm}
+	if n > 0 {
+		tol := float64(m) * 2.220446049250313e-16 * math.Abs(qr.Get(0, 0))
+		for f.rank < n && math.Abs(qr.Get(f.rank, f.rank)) > tol {
+			f.rank++
+		}
+	}
+	return f
+}
+
+// Rank returns the numerical rank of the factored matrix.
+func (f QR) Rank() int {
+	return f.rank
+}
+
+// Perm returns the column permutation: column k of A * P is column Perm()[k] of A.
+func (f QR) Perm() []int {
+	return f.perm
+}
+
+// R returns the n x n upper triangular factor.
+func (f QR) R() Matrix {
+	n := f.qr.Cols()
+	R := NewDenseMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			R.Set(i, j, f.qr.Get(i, j))
+		}
+	}
+	return R
+}
+
+// ApplyQT overwrites b with Q^T * b.
+func (f QR) ApplyQT(b Vector) {
+	m, n := f.qr.Rows(), f.qr.Cols()
+	if b.Len() != m {
+		panic(ErrDimensionMismatch)
+	}
+	for k := 0; k < n; k++ {
+		if f.tau[k] == 0 {
+			continue
+		}
+		w := b[k]
+		for i := k + 1; i < m; i++ {
+			w += f.qr.Get(i, k) * b[i]
+		}
+		w *= f.tau[k]
+		b[k] -= w
+		for i := k + 1; i < m; i++ {
+			b[i] -= w * f.qr.Get(i, k)
+		}
+	}
+}
+
+// SolveLeastSquares finds the x minimizing ||A * x - b||. If A is rank deficient, the
+// components of x along the dropped columns are zero. b is not modified.
+func (f QR) SolveLeastSquares(b Vector, x Vector) {
+	m, n := f.qr.Rows(), f.qr.Cols()
+	if b.Len() != m || x.Len() != n {
+		panic(ErrDimensionMismatch)
+	}
+	y := NewVector(m)
+	copy(y, b)
+	f.ApplyQT(y)
+
+	// back substitution with the leading rank x rank block of R
+	z := NewVector(n)
+	for i := f.rank - 1; i >= 0; i-- {
+		sum := y[i]
+		for j := i + 1; j < f.rank; j++ {
+			sum -= f.qr.Get(i, j) * z[j]
+		}
+		z[i] = sum / f.qr.Get(i, i)
+	}
+	for k := 0; k < n; k++ {
+		x[f.perm[k]] = z[k]
+	}
+}
+
+// NormalInverse writes (A^T * A)^-1 = P * R^-1 * R^-T * P^T into C. If A is rank
+// deficient, the rows and columns of the dropped columns are zero.
+func (f QR) NormalInverse(C Matrix) {
+	n := f.qr.Cols()
+	if C.Rows() != n || C.Cols() != n {
+		panic(ErrDimensionMismatch)
+	}
+
+	// Rinv = R^-1 of the leading rank x rank block
+	r := f.rank
+	Rinv := NewDenseMatrix(n, n)
+	for j := 0; j < r; j++ {
+		Rinv.Set(j, j, 1/f.qr.Get(j, j))
+		for i := j - 1; i >= 0; i-- {
+			sum := 0.0
+			for k := i + 1; k <= j; k++ {
+				sum += f.qr.Get(i, k) * Rinv.Get(k, j)
+			}
+			Rinv.Set(i, j, -sum/f.qr.Get(i, i))
+		}
+	}
+	for a := 0; a < n; a++ {
+		for b := 0; b < n; b++ {
+			sum := 0.0
+			for k := max(a, b); k < r; k++ {
+				sum += Rinv.Get(a, k) * Rinv.Get(b, k)
+			}
+			C.Set(f.perm[a], f.perm[b], sum)
+		}
+	}
+}
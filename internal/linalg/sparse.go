@@ -0,0 +1,157 @@
+package linalg
+
+import "sort"
+
+// SparseMatrix is a sparse matrix in compressed sparse column (CSC) format. Row
+// indices within each column are sorted and unique.
+type SparseMatrix struct {
+	rows   int
+	cols   int
+	colPtr []int
+	rowIdx []int
+	values []float64
+}
+
+// TripletMatrix accumulates the entries of a sparse matrix in coordinate form.
+// Duplicate entries are summed when it is compressed.
+type TripletMatrix struct {
+	rows   int
+	cols   int
+	i      []int
+	j      []int
+	values []float64
+}
+
+func NewTripletMatrix(rows, cols int) *TripletMatrix {
+	return &TripletMatrix{rows: rows, cols: cols}
+}
+
+// Append adds value to the entry at row i and column j.
+func (t *TripletMatrix) Append(i, j int, value float64) {
+	if i < 0 || i >= t.rows || j < 0 || j >= t.cols {
+		panic(ErrIndexOutOfBounds)
+	}
+	t.i = append(t.i, i)
+	t.j = append(t.j, j)
+	t.values = append(t.values, value)
+}
+
+func (t *TripletMatrix) Rows() int {
+	return t.rows
+}
+
+func (t *TripletMatrix) Cols() int {
+	return t.cols
+}
+
+// ToCSC compresses the triplets into a SparseMatrix, summing duplicate entries.
+func (t *TripletMatrix) ToCSC() SparseMatrix {
+	order := make([]int, len(t.values))
+	for k := range order {
+		order[k] = k
+	}
+	sort.Slice(order, func(a, b int) bool {
+		ka, kb := order[a], order[b]
+		if t.j[ka] != t.j[kb] {
+			return t.j[ka] < t.j[kb]
+		}
+		return t.i[ka] < t.i[kb]
+	})
+
+	m := SparseMatrix{
+		rows:   t.rows,
+		cols:   t.cols,
+		colPtr: make([]int, t.cols+1),
+		rowIdx: make([]int, 0, len(order)),
+		values: make([]float64, 0, len(order)),
+	}
+	for idx, k := range order {
+		if idx > 0 {
+			prev := order[idx-1]
+			if t.i[prev] == t.i[k] && t.j[prev] == t.j[k] {
+				m.values[len(m.values)-1] += t.values[k]
+				continue
+			}
+		}
+		m.rowIdx = append(m.rowIdx, t.i[k])
+		m.values = append(m.values, t.values[k])
+		m.colPtr[t.j[k]+1]++
+	}
+	for j := 0; j < t.cols; j++ {
+		m.colPtr[j+1] += m.colPtr[j]
+	}
+	return m
+}
+
+// NewSparseFromDense returns the sparse form of A, dropping exact zeros.
+func NewSparseFromDense(A Matrix) SparseMatrix {
+	t := NewTripletMatrix(A.Rows(), A.Cols())
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			if v := A.Get(i, j); v != 0 {
+				t.Append(i, j, v)
+			}
+		}
+	}
+	return t.ToCSC()
+}
+
+func (m SparseMatrix) Rows() int {
+	return m.rows
+}
+
+func (m SparseMatrix) Cols() int {
+	return m.cols
+}
+
+// NNZ returns the number of stored entries.
+func (m SparseMatrix) NNZ() int {
+	return len(m.values)
+}
+
+// Column returns the row indices and values of the stored entries in column j.
+// The returned slices share storage with the matrix.
+func (m SparseMatrix) Column(j int) ([]int, []float64) {
+	if j < 0 || j >= m.cols {
+		panic(ErrIndexOutOfBounds)
+	}
+	start, end := m.colPtr[j], m.colPtr[j+1]
+	return m.rowIdx[start:end], m.values[start:end]
+}
+
+// Get returns the value at the given row and column.
+func (m SparseMatrix) Get(i, j int) float64 {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(ErrIndexOutOfBounds)
+	}
+	rows, values := m.Column(j)
+	k := sort.SearchInts(rows, i)
+	if k < len(rows) && rows[k] == i {
+		return values[k]
+	}
+	return 0
+}
+
+// Transpose returns the transpose of m.
+func (m SparseMatrix) Transpose() SparseMatrix {
+	t := NewTripletMatrix(m.cols, m.rows)
+	for j := 0; j < m.cols; j++ {
+		rows, values := m.Column(j)
+		for k, i := range rows {
+			t.Append(j, i, values[k])
+		}
+	}
+	return t.ToCSC()
+}
+
+// ToDense returns the dense form of m.
+func (m SparseMatrix) ToDense() Matrix {
+	A := NewDenseMatrix(m.rows, m.cols)
+	for j := 0; j < m.cols; j++ {
+		rows, values := m.Column(j)
+		for k, i := range rows {
+			A.Set(i, j, values[k])
+		}
+	}
+	return A
+}
@@ -0,0 +1,159 @@
+package linalg
+
+import "math"
+
+// SparseLDL is the sparse factorization A = L * D * L^T of a symmetric matrix without
+// pivoting, where L is unit lower triangular and D is diagonal. It exists for
+// symmetric quasi-definite matrices, such as regularized KKT systems.
+//
+// Reference: T. A. Davis, "Algorithm 849: A Concise Sparse Cholesky Factorization
+// Package", ACM Trans. Math. Softw., 2005.
+type SparseLDL struct {
+	n      int
+	colPtr []int
+	rowIdx []int
+	values []float64
+	d      Vector
+}
+
+// FactorSparseLDL computes the LDL^T factorization of the symmetric matrix A. Only the
+// upper triangle of A (entries with i <= j) is referenced.
+//
+// Returns ErrSingularMatrix if a zero pivot is encountered.
+func FactorSparseLDL(A SparseMatrix) (SparseLDL, error) {
+	if A.Rows() != A.Cols() {
+		panic(ErrDimensionMismatch)
+	}
+	n := A.Rows()
+
+	// symbolic analysis: elimination tree and column counts of L
+	parent := make([]int, n)
+	lnz := make([]int, n)
+	flag := make([]int, n)
+	for k := 0; k < n; k++ {
+		parent[k] = -1
+		flag[k] = k
+		rows, _ := A.Column(k)
+		for _, i := range rows {
+			for i < k && flag[i] != k {
+				if parent[i] == -1 {
+					parent[i] = k
+				}
+				lnz[i]++
+				flag[i] = k
+				i = parent[i]
+			}
+		}
+	}
+	f := SparseLDL{
+		n:      n,
+		colPtr: make([]int, n+1),
+		d:      NewVector(n),
+	}
+	for k := 0; k < n; k++ {
+		f.colPtr[k+1] = f.colPtr[k] + lnz[k]
+	}
+	f.rowIdx = make([]int, f.colPtr[n])
+	f.values = make([]float64, f.colPtr[n])
+
+	// numeric factorization, one row of L at a time
+	y := NewVector(n)
+	pattern := make([]int, n)
+	for k := 0; k < n; k++ {
+		y[k] = 0
+		top := n
+		flag[k] = k
+		lnz[k] = 0
+		rows, values := A.Column(k)
+		for p, i := range rows {
+			if i > k {
+				continue
+			}
+			y[i] += values[p]
+			length := 0
+			for ; flag[i] != k; i = parent[i] {
+				pattern[length] = i
+				length++
+				flag[i] = k
+			}
+			for length > 0 {
+				top--
+				length--
+				pattern[top] = pattern[length]
+			}
+		}
+
+		dk := y[k]
+		y[k] = 0
+		for ; top < n; top++ {
+			i := pattern[top]
+			yi := y[i]
+			y[i] = 0
+			end := f.colPtr[i] + lnz[i]
+			for p := f.colPtr[i]; p < end; p++ {
+				y[f.rowIdx[p]] -= f.values[p] * yi
+			}
+			lki := yi / f.d[i]
+			dk -= lki * yi
+			f.rowIdx[end] = k
+			f.values[end] = lki
+			lnz[i]++
+		}
+		if dk == 0 || math.IsNaN(dk) {
+			return SparseLDL{}, ErrSingularMatrix
+		}
+		f.d[k] = dk
+	}
+	return f, nil
+}
+
+// Size returns the order of the factored matrix.
+func (f SparseLDL) Size() int {
+	return f.n
+}
+
+// NNZ returns the number of stored entries of L, excluding the unit diagonal.
+func (f SparseLDL) NNZ() int {
+	return len(f.values)
+}
+
+// Inertia returns the number of positive, negative and zero eigenvalues of the factored matrix.
+func (f SparseLDL) Inertia() (positive, negative, zero int) {
+	for _, dk := range f.d {
+		if dk > 0 {
+			positive++
+		} else if dk < 0 {
+			negative++
+		} else {
+			zero++
+		}
+	}
+	return positive, negative, zero
+}
+
+// Solve solves A * x = b for x. b and x may be the same vector.
+func (f SparseLDL) Solve(b Vector, x Vector) {
+	if b.Len() != f.n || x.Len() != f.n {
+		panic(ErrDimensionMismatch)
+	}
+	copy(x, b)
+
+	// L * y = b
+	for j := 0; j < f.n; j++ {
+		for p := f.colPtr[j]; p < f.colPtr[j+1]; p++ {
+			x[f.rowIdx[p]] -= f.values[p] * x[j]
+		}
+	}
+
+	// D * z = y
+	for j := 0; j < f.n; j++ {
+		x[j] /= f.d[j]
+	}
+
+	// L^T * x = z
+	for j := f.n - 1; j >= 0; j-- {
+		for p := f.colPtr[j]; p < f.colPtr[j+1]; p++ {
+			x[j] -= f.values[p] * x[f.rowIdx[p]]
+		}
+	}
+}
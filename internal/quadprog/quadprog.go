@@ -0,0 +1,327 @@
+// Package quadprog solves small to medium dense strictly convex quadratic programs
+// with the dual active-set method of Goldfarb and Idnani.
+//
+// Reference: D. Goldfarb and A. Idnani, "A numerically stable dual method for solving
+// strictly convex quadratic programs", Mathematical Programming 27, 1983.
+package quadprog
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// Problem is the quadratic program
+//
+//	minimize 1/2 * x^T * G * x + c^T * x
+//	subject to Aeq * x = beq, Aineq * x <= bineq
+//
+// G must be symmetric positive definite. Either constraint block may have zero rows.
+type Problem struct {
+	G     linalg.Matrix
+	C     linalg.Vector
+	Aeq   linalg.Matrix
+	Beq   linalg.Vector
+	Aineq linalg.Matrix
+	Bineq linalg.Vector
+}
+
+var TOLERANCE = 1e-10 // default violation, relative to the size of a constraint, taken as satisfied
+
+type Status int
+
+const (
+	Optimal Status = iota
+	Infeasible
+	IterationLimit
+	NotConvex
+)
+
+func (s Status) String() string {
+	switch s {
+	case Optimal:
+		return "optimal"
+	case Infeasible:
+		return "infeasible"
+	case IterationLimit:
+		return "iteration limit"
+	case NotConvex:
+		return "not convex"
+	}
+	return "unknown"
+}
+
+// Result is the solution of a quadratic program.
+//
+// The multipliers are those of the Lagrangian
+//
+//	L(x, lambda, mu) = f(x) + lambda^T * (Aeq * x - beq) + mu^T * (Aineq * x - bineq)
+//
+// with mu >= 0. When the problem is infeasible, the rays satisfy
+// Aeq^T * yeq + Aineq^T * yineq = 0, yineq >= 0 and beq^T * yeq + bineq^T * yineq < 0.
+type Result struct {
+	Status                Status
+	X                     linalg.Vector
+	Objective             float64
+	EqualityMultipliers   linalg.Vector
+	InequalityMultipliers linalg.Vector
+	Active                []int
+	Iterations            int
+
+	EqualityRay   linalg.Vector
+	InequalityRay linalg.Vector
+}
+
+// constraint is a constraint n^T * x >= b in the form used by the dual method.
+type constraint struct {
+	n        linalg.Vector
+	b        float64
+	gn       linalg.Vector // G^-1 * n
+	equality bool
+	index    int
+	sign     float64 // sign applied to the original row
+}
+
+func (c *constraint) flip() {
+	blas.SCAL(-1, c.n)
+	blas.SCAL(-1, c.gn)
+	c.b = -c.b
+	c.sign = -c.sign
+}
+
+// Solve solves the quadratic program. Inequalities listed in warm are tried first when
+// they are violated, which shortens the solve when the active set is known from a
+// nearby problem. A constraint whose violation is below tolerance times the size of
+// its terms counts as satisfied.
+func Solve(p Problem, warm []int, maxIterations int, tolerance float64) Result {
+	n := p.C.Len()
+	if p.G.Rows() != n || p.G.Cols() != n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	me := p.Beq.Len()
+	mi := p.Bineq.Len()
+	if me > 0 && (p.Aeq.Rows() != me || p.Aeq.Cols() != n) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if mi > 0 && (p.Aineq.Rows() != mi || p.Aineq.Cols() != n) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	result := Result{
+		X:                     linalg.NewVector(n),
+		EqualityMultipliers:   linalg.NewVector(me),
+		InequalityMultipliers: linalg.NewVector(mi),
+	}
+
+	chol, err := linalg.FactorCholesky(p.G)
+	if err != nil {
+		result.Status = NotConvex
+		return result
+	}
+
+	// build the constraints as n^T * x >= b
+	constraints := make([]*constraint, 0, me+mi)
+	for i := 0; i < me; i++ {
+		c := &constraint{n: linalg.NewVector(n), gn: linalg.NewVector(n), equality: true, index: i, sign: 1}
+		p.Aeq.GetRow(i, c.n)
+		c.b = p.Beq[i]
+		chol.Solve(c.n, c.gn)
+		constraints = append(constraints, c)
+	}
+	for i := 0; i < mi; i++ {
+		c := &constraint{n: linalg.NewVector(n), gn: linalg.NewVector(n), index: i, sign: -1}
+		p.Aineq.GetRow(i, c.n)
+		blas.SCAL(-1, c.n)
+		c.b = -p.Bineq[i]
+		chol.Solve(c.n, c.gn)
+		constraints = append(constraints, c)
+	}
+	isWarm := make([]bool, mi)
+	for _, i := range warm {
+		if i >= 0 && i < mi {
+			isWarm[i] = true
+		}
+	}
+
+	// unconstrained minimum x = -G^-1 * c
+	x := result.X
+	chol.Solve(p.C, x)
+	blas.SCAL(-1, x)
+
+	active := make([]*constraint, 0, n)
+	u := make([]float64, 0, n)
+	inActive := make([]bool, len(constraints))
+
+	slack := func(c *constraint) float64 {
+		return blas.DOT(c.n, x) - c.b
+	}
+	violationTolerance := func(c *constraint) float64 {
+		return tolerance * math.Max(1, math.Max(math.Abs(c.b), blas.NRM2(c.n)*blas.NRM2(x)))
+	}
+
+	z := linalg.NewVector(n)
+	iter := 0
+	for iter < maxIterations {
+		iter++
+
+		// step 1: choose a violated constraint, equalities first and then the most violated
+		// inequality, preferring those from the warm start
+		next := -1
+		worst := 0.0
+		nextWarm := false
+		for j, c := range constraints {
+			if inActive[j] {
+				continue
+			}
+			s := slack(c)
+			if c.equality {
+				if math.Abs(s) > violationTolerance(c) {
+					next = j
+					break
+				}
+				continue
+			}
+			if s >= -violationTolerance(c) {
+				continue
+			}
+			warm := isWarm[c.index]
+			if next < 0 || warm && !nextWarm || warm == nextWarm && s < worst {
+				worst, next, nextWarm = s, j, warm
+			}
+		}
+		if next < 0 {
+			result.Status = Optimal
+			break
+		}
+		cp := constraints[next]
+		if cp.equality && slack(cp) > 0 {
+			cp.flip()
+		}
+
+		up := 0.0
+		added := false
+		for !added {
+			// step 2a: primal direction z = H * n_p and dual direction r = N* * n_p
+			q := len(active)
+			r := linalg.NewVector(q)
+			if q > 0 {
+				M := linalg.NewDenseMatrix(q, q)
+				rhs := linalg.NewVector(q)
+				for i, ci := range active {
+					rhs[i] = blas.DOT(ci.n, cp.gn)
+					for j, cj := range active {
+						M.Set(i, j, blas.DOT(ci.n, cj.gn))
+					}
+				}
+				lu, err := linalg.FactorLU(M)
+				if err != nil {
+					result.Status = NotConvex
+					result.Iterations = iter
+					return finish(p, result, active, u)
+				}
+				lu.Solve(rhs, r)
+			}
+			blas.COPY(cp.gn, z)
+			for i, ci := range active {
+				blas.AXPY(-r[i], ci.gn, z)
+			}
+
+			// step 2b: partial (dual) and full (primal) step lengths
+			t1 := math.Inf(1)
+			k := -1
+			for i, ci := range active {
+				if !ci.equality && r[i] > 0 {
+					if t := u[i] / r[i]; t < t1 {
+						t1 = t
+						k = i
+					}
+				}
+			}
+			t2 := math.Inf(1)
+			zn := blas.DOT(z, cp.n)
+			if zn > 1e-12*blas.DOT(cp.gn, cp.n) {
+				t2 = -slack(cp) / zn
+			}
+
+			// step 2c: take the step
+			if math.IsInf(t1, 1) && math.IsInf(t2, 1) {
+				result.Status = Infeasible
+				result.Iterations = iter
+				result.EqualityRay = linalg.NewVector(me)
+				result.InequalityRay = linalg.NewVector(mi)
+				setRay(result, cp, 1)
+				for i, ci := range active {
+					setRay(result, ci, -r[i])
+				}
+				return finish(p, result, active, u)
+			}
+			t := math.Min(t1, t2)
+			if !math.IsInf(t2, 1) {
+				blas.AXPY(t, z, x)
+			}
+			for i := range active {
+				u[i] -= t * r[i]
+			}
+			up += t
+
+			if t == t2 {
+				active = append(active, cp)
+				u = append(u, up)
+				inActive[next] = true
+				added = true
+			} else {
+				inActive[indexOf(constraints, active[k])] = false
+				active = append(active[:k], active[k+1:]...)
+				u = append(u[:k], u[k+1:]...)
+			}
+		}
+	}
+	if result.Status != Optimal {
+		result.Status = IterationLimit
+	}
+	result.Iterations = iter
+	return finish(p, result, active, u)
+}
+
+// setRay adds the weight w on constraint c to the infeasibility certificate.
+func setRay(result Result, c *constraint, w float64) {
+	if c.equality {
+		// n = sign * a, b = sign * beq
+		result.EqualityRay[c.index] += -w * c.sign
+	} else {
+		result.InequalityRay[c.index] += w
+	}
+}
+
+func indexOf(constraints []*constraint, c *constraint) int {
+	for j := range constraints {
+		if constraints[j] == c {
+			return j
+		}
+	}
+	return -1
+}
+
+// finish fills the multipliers, active set and objective of the result.
+func finish(p Problem, result Result, active []*constraint, u []float64) Result {
+	result.Objective = p.Objective(result.X)
+	result.Active = result.Active[:0]
+	for i, c := range active {
+		if c.equality {
+			// G * x + c = u * sign * a  =>  lambda = -u * sign
+			result.EqualityMultipliers[c.index] = -u[i] * c.sign
+		} else {
+			result.InequalityMultipliers[c.index] = u[i]
+			result.Active = append(result.Active, c.index)
+		}
+	}
+	return result
+}
+
+// Objective returns 1/2 * x^T * G * x + c^T * x.
+func (p Problem) Objective(x linalg.Vector) float64 {
+	Gx := linalg.NewVector(x.Len())
+	blas.GEMV(1.0, p.G, x, 0.0, Gx)
+	return 0.5*blas.DOT(x, Gx) + blas.DOT(p.C, x)
+}
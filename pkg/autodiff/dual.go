@@ -0,0 +1,133 @@
+// Package autodiff computes exact derivatives of functions written against its number
+// types.
+//
+// Forward mode uses dual numbers, which carry a value and a directional derivative
+// through every operation, and hyper-dual numbers, which also carry a second
+// derivative. Go has no operator overloading, so arithmetic and the elementary
+// functions are methods: x*y + sin(x) is written x.Mul(y).Add(x.Sin()).
+//
+// Reverse mode records the operations on a Tape and gets the whole gradient from one
+// backward sweep, which is cheaper than forward mode when there are many variables.
+package autodiff
+
+import (
+	"math"
+)
+
+// Dual is the dual number Value + Deriv * e with e^2 = 0. Evaluating a function at
+// x + e gives f(x) + f'(x) * e, so Deriv carries the derivative through each operation.
+type Dual struct {
+	Value float64
+	Deriv float64
+}
+
+// Constant returns the dual number of a constant, with a zero derivative.
+func Constant(v float64) Dual {
+	return Dual{Value: v}
+}
+
+// Variable returns the dual number of the variable being differentiated, with a unit
+// derivative.
+func Variable(v float64) Dual {
+	return Dual{Value: v, Deriv: 1}
+}
+
+// chain returns f(a) given f(a.Value) and f'(a.Value).
+func (a Dual) chain(f, df float64) Dual {
+	return Dual{Value: f, Deriv: df * a.Deriv}
+}
+
+func (a Dual) Add(b Dual) Dual {
+	return Dual{a.Value + b.Value, a.Deriv + b.Deriv}
+}
+
+func (a Dual) Sub(b Dual) Dual {
+	return Dual{a.Value - b.Value, a.Deriv - b.Deriv}
+}
+
+func (a Dual) Mul(b Dual) Dual {
+	return Dual{a.Value * b.Value, a.Deriv*b.Value + a.Value*b.Deriv}
+}
+
+func (a Dual) Div(b Dual) Dual {
+	q := a.Value / b.Value
+	return Dual{q, (a.Deriv - q*b.Deriv) / b.Value}
+}
+
+func (a Dual) Neg() Dual {
+	return Dual{-a.Value, -a.Deriv}
+}
+
+// AddConst returns a + c.
+func (a Dual) AddConst(c float64) Dual {
+	return Dual{a.Value + c, a.Deriv}
+}
+
+// Scale returns c * a.
+func (a Dual) Scale(c float64) Dual {
+	return Dual{c * a.Value, c * a.Deriv}
+}
+
+func (a Dual) Exp() Dual {
+	e := math.Exp(a.Value)
+	return a.chain(e, e)
+}
+
+func (a Dual) Log() Dual {
+	return a.chain(math.Log(a.Value), 1/a.Value)
+}
+
+func (a Dual) Sin() Dual {
+	return a.chain(math.Sin(a.Value), math.Cos(a.Value))
+}
+
+func (a Dual) Cos() Dual {
+	return a.chain(math.Cos(a.Value), -math.Sin(a.Value))
+}
+
+func (a Dual) Tan() Dual {
+	t := math.Tan(a.Value)
+	return a.chain(t, 1+t*t)
+}
+
+func (a Dual) Sqrt() Dual {
+	s := math.Sqrt(a.Value)
+	return a.chain(s, 0.5/s)
+}
+
+// PowConst returns a^p for a constant exponent.
+func (a Dual) PowConst(p float64) Dual {
+	if p == 0 {
+		return Constant(1)
+	}
+	return a.chain(math.Pow(a.Value, p), p*math.Pow(a.Value, p-1))
+}
+
+// Pow returns a^b = exp(b * log(a)), which requires a > 0 unless b is a constant.
+func (a Dual) Pow(b Dual) Dual {
+	if b.Deriv == 0 {
+		return a.PowConst(b.Value)
+	}
+	p := math.Pow(a.Value, b.Value)
+	return Dual{p, p * (b.Deriv*math.Log(a.Value) + b.Value*a.Deriv/a.Value)}
+}
+
+// Abs returns |a|, whose derivative at zero is taken as zero.
+func (a Dual) Abs() Dual {
+	switch {
+	case a.Value > 0:
+		return a
+	case a.Value < 0:
+		return a.Neg()
+	}
+	return Dual{0, 0}
+}
+
+func (a Dual) Tanh() Dual {
+	t := math.Tanh(a.Value)
+	return a.chain(t, 1-t*t)
+}
+
+func (a Dual) Atan() Dual {
+	return a.chain(math.Atan(a.Value), 1/(1+a.Value*a.Value))
+}
@@ -0,0 +1,81 @@
+package autodiff
+
+import (
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// DualFunc is a function of several variables written against dual numbers.
+type DualFunc func(x []Dual) Dual
+
+// HyperDualFunc is a function of several variables written against hyper-dual numbers.
+type HyperDualFunc func(x []HyperDual) HyperDual
+
+// Objective returns f as an objective of real numbers.
+func Objective(f DualFunc) optim.ObjectiveFunc {
+	var xd []Dual
+	return func(x linalg.Vector) float64 {
+		xd = duals(xd, x)
+		return f(xd).Value
+	}
+}
+
+// duals returns the constant dual numbers of x, reusing xd if it has the right length.
+func duals(xd []Dual, x linalg.Vector) []Dual {
+	if len(xd) != x.Len() {
+		xd = make([]Dual, x.Len())
+	}
+	for i, v := range x {
+		xd[i] = Constant(v)
+	}
+	return xd
+}
+
+// Gradient returns a gradient function of f for optim.WithGradientFunc. It computes the
+// exact gradient with one forward pass per variable and ignores the objective passed to
+// it. Like the finite-difference gradients it returns the norm of the gradient.
+func Gradient(f DualFunc) func(x linalg.Vector, _ optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+	var xd []Dual
+	return func(x linalg.Vector, _ optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		if gradF.Len() != x.Len() {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		xd = duals(xd, x)
+		for i := range xd {
+			xd[i].Deriv = 1
+			gradF[i] = f(xd).Deriv
+			xd[i].Deriv = 0
+		}
+		return blas.NRM2(gradF)
+	}
+}
+
+// Hessian returns a function that writes the exact Hessian of f at x into H, with one
+// hyper-dual pass per entry of the upper triangle.
+func Hessian(f HyperDualFunc) optim.HessianFunc {
+	var xh []HyperDual
+	return func(x linalg.Vector, H linalg.Matrix) {
+		n := x.Len()
+		if H.Rows() != n || H.Cols() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		if len(xh) != n {
+			xh = make([]HyperDual, n)
+		}
+		for i, v := range x {
+			xh[i] = HyperConstant(v)
+		}
+		for i := 0; i < n; i++ {
+			xh[i].E1 = 1
+			for j := i; j < n; j++ {
+				xh[j].E2 = 1
+				h := f(xh).E12
+				xh[j].E2 = 0
+				H.Set(i, j, h)
+				H.Set(j, i, h)
+			}
+			xh[i].E1 = 0
+		}
+	}
+}
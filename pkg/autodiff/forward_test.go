@@ -0,0 +1,124 @@
+package autodiff_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/autodiff"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func TestDualElementaryFunctions(t *testing.T) {
+	functions := []struct {
+		name  string
+		dual  func(autodiff.Dual) autodiff.Dual
+		hyper func(autodiff.HyperDual) autodiff.HyperDual
+		f     func(float64) float64
+		df    func(float64) float64
+		d2f   func(float64) float64
+	}{
+		{"exp", autodiff.Dual.Exp, autodiff.HyperDual.Exp, math.Exp, math.Exp, math.Exp},
+		{"log", autodiff.Dual.Log, autodiff.HyperDual.Log, math.Log,
+			func(x float64) float64 { return 1 / x }, func(x float64) float64 { return -1 / (x * x) }},
+		{"sin", autodiff.Dual.Sin, autodiff.HyperDual.Sin, math.Sin, math.Cos,
+			func(x float64) float64 { return -math.Sin(x) }},
+		{"cos", autodiff.Dual.Cos, autodiff.HyperDual.Cos, math.Cos,
+			func(x float64) float64 { return -math.Sin(x) }, func(x float64) float64 { return -math.Cos(x) }},
+		{"tan", autodiff.Dual.Tan, autodiff.HyperDual.Tan, math.Tan,
+			func(x float64) float64 { return 1 / (math.Cos(x) * math.Cos(x)) },
+			func(x float64) float64 { return 2 * math.Tan(x) / (math.Cos(x) * math.Cos(x)) }},
+		{"sqrt", autodiff.Dual.Sqrt, autodiff.HyperDual.Sqrt, math.Sqrt,
+			func(x float64) float64 { return 0.5 / math.Sqrt(x) }, func(x float64) float64 { return -0.25 * math.Pow(x, -1.5) }},
+		{"tanh", autodiff.Dual.Tanh, autodiff.HyperDual.Tanh, math.Tanh,
+			func(x float64) float64 { return 1 / (math.Cosh(x) * math.Cosh(x)) },
+			func(x float64) float64 { return -2 * math.Tanh(x) / (math.Cosh(x) * math.Cosh(x)) }},
+		{"atan", autodiff.Dual.Atan, autodiff.HyperDual.Atan, math.Atan,
+			func(x float64) float64 { return 1 / (1 + x*x) }, func(x float64) float64 { return -2 * x / ((1 + x*x) * (1 + x*x)) }},
+		{"pow", func(a autodiff.Dual) autodiff.Dual { return a.PowConst(2.5) },
+			func(a autodiff.HyperDual) autodiff.HyperDual { return a.PowConst(2.5) },
+			func(x float64) float64 { return math.Pow(x, 2.5) },
+			func(x float64) float64 { return 2.5 * math.Pow(x, 1.5) }, func(x float64) float64 { return 3.75 * math.Sqrt(x) }},
+		{"self power", func(a autodiff.Dual) autodiff.Dual { return a.Pow(a) },
+			func(a autodiff.HyperDual) autodiff.HyperDual { return a.Pow(a) },
+			func(x float64) float64 { return math.Pow(x, x) },
+			func(x float64) float64 { return math.Pow(x, x) * (math.Log(x) + 1) },
+			func(x float64) float64 { return math.Pow(x, x) * ((math.Log(x)+1)*(math.Log(x)+1) + 1/x) }},
+		{"quotient", func(a autodiff.Dual) autodiff.Dual { return autodiff.Constant(1).Div(a.Mul(a).AddConst(1)) },
+			func(a autodiff.HyperDual) autodiff.HyperDual {
+				return autodiff.HyperConstant(1).Div(a.Mul(a).AddConst(1))
+			},
+			func(x float64) float64 { return 1 / (1 + x*x) },
+			func(x float64) float64 { return -2 * x / ((1 + x*x) * (1 + x*x)) },
+			func(x float64) float64 { return (6*x*x - 2) / math.Pow(1+x*x, 3) }},
+	}
+	for _, fn := range functions {
+		for _, x := range []float64{0.3, 0.9, 1.7} {
+			d := fn.dual(autodiff.Variable(x))
+			h := fn.hyper(autodiff.HyperDual{Value: x, E1: 1, E2: 1})
+			close := func(got, want float64) bool {
+				return math.Abs(got-want) <= 1e-13*math.Max(1, math.Abs(want))
+			}
+			if !close(d.Value, fn.f(x)) || !close(d.Deriv, fn.df(x)) {
+				t.Errorf("%s(%v): expected (%v, %v), got %+v", fn.name, x, fn.f(x), fn.df(x), d)
+			}
+			if !close(h.Value, fn.f(x)) || !close(h.E1, fn.df(x)) || !close(h.E2, fn.df(x)) || !close(h.E12, fn.d2f(x)) {
+				t.Errorf("%s(%v): expected (%v, %v, %v), got %+v", fn.name, x, fn.f(x), fn.df(x), fn.d2f(x), h)
+			}
+		}
+	}
+}
+
+func rosenbrockDual(x []autodiff.Dual) autodiff.Dual {
+	a := autodiff.Constant(1).Sub(x[0])
+	b := x[1].Sub(x[0].Mul(x[0]))
+	return a.Mul(a).Add(b.Mul(b).Scale(100))
+}
+
+func TestGradient(t *testing.T) {
+	x := linalg.Vector{-1.2, 1}
+	g := linalg.NewVector(2)
+	nrm := autodiff.Gradient(rosenbrockDual)(x, nil, g)
+	expected := linalg.Vector{-2*(1-x[0]) - 400*x[0]*(x[1]-x[0]*x[0]), 200 * (x[1] - x[0]*x[0])}
+	for i := range g {
+		if math.Abs(g[i]-expected[i]) > 1e-12*math.Abs(expected[i]) {
+			t.Errorf("expected gradient %v, got %v", expected, g)
+		}
+	}
+	if math.Abs(nrm-math.Hypot(expected[0], expected[1])) > 1e-9 {
+		t.Errorf("expected norm %v, got %v", math.Hypot(expected[0], expected[1]), nrm)
+	}
+	if v := autodiff.Objective(rosenbrockDual)(x); math.Abs(v-24.2) > 1e-12 {
+		t.Errorf("expected objective 24.2, got %v", v)
+	}
+
+	// the exact gradient plugs into the solvers
+	sol := optim.NewLBFGSBSolver().Solve(autodiff.Objective(rosenbrockDual), linalg.Vector{-1.2, 1},
+		optim.WithGradientFunc(autodiff.Gradient(rosenbrockDual)), optim.WithTolerance(1e-10))
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-1) > 1e-8 || math.Abs(sol.Result[1]-1) > 1e-8 {
+		t.Errorf("expected the minimum [1 1], got %+v", sol)
+	}
+}
+
+func TestHessian(t *testing.T) {
+	// f = exp(x0 * x1) + x0^2 * sin(x2)
+	f := func(x []autodiff.HyperDual) autodiff.HyperDual {
+		return x[0].Mul(x[1]).Exp().Add(x[0].Mul(x[0]).Mul(x[2].Sin()))
+	}
+	x := linalg.Vector{0.5, -0.7, 1.1}
+	H := linalg.NewDenseMatrix(3, 3)
+	autodiff.Hessian(f)(x, H)
+	e := math.Exp(x[0] * x[1])
+	expected := [][]float64{
+		{x[1]*x[1]*e + 2*math.Sin(x[2]), e + x[0]*x[1]*e, 2 * x[0] * math.Cos(x[2])},
+		{e + x[0]*x[1]*e, x[0] * x[0] * e, 0},
+		{2 * x[0] * math.Cos(x[2]), 0, -x[0] * x[0] * math.Sin(x[2])},
+	}
+	for i := range expected {
+		for j := range expected[i] {
+			if math.Abs(H.Get(i, j)-expected[i][j]) > 1e-14 {
+				t.Errorf("H[%d][%d]: expected %v, got %v", i, j, expected[i][j], H.Get(i, j))
+			}
+		}
+	}
+}
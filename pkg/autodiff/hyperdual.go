@@ -0,0 +1,143 @@
+package autodiff
+
+import (
+	"math"
+)
+
+// HyperDual is the hyper-dual number Value + E1 * e1 + E2 * e2 + E12 * e1 * e2 with
+// e1^2 = e2^2 = 0 (Fike and Alonso, The Development of Hyper-Dual Numbers for Exact
+// Second-Derivative Calculations, 2011). Evaluating f at x + u * e1 + v * e2 gives
+// E1 = f'(x) * u, E2 = f'(x) * v and E12 = u^T * H(x) * v, with H the Hessian of f,
+// without truncation or cancellation errors.
+type HyperDual struct {
+	Value float64
+	E1    float64
+	E2    float64
+	E12   float64
+}
+
+// HyperConstant returns the hyper-dual number of a constant.
+func HyperConstant(v float64) HyperDual {
+	return HyperDual{Value: v}
+}
+
+// chain returns f(a) given f and its first and second derivatives at a.Value.
+func (a HyperDual) chain(f, df, d2f float64) HyperDual {
+	return HyperDual{
+		Value: f,
+		E1:    df * a.E1,
+		E2:    df * a.E2,
+		E12:   df*a.E12 + d2f*a.E1*a.E2,
+	}
+}
+
+func (a HyperDual) Add(b HyperDual) HyperDual {
+	return HyperDual{a.Value + b.Value, a.E1 + b.E1, a.E2 + b.E2, a.E12 + b.E12}
+}
+
+func (a HyperDual) Sub(b HyperDual) HyperDual {
+	return HyperDual{a.Value - b.Value, a.E1 - b.E1, a.E2 - b.E2, a.E12 - b.E12}
+}
+
+func (a HyperDual) Mul(b HyperDual) HyperDual {
+	return HyperDual{
+		Value: a.Value * b.Value,
+		E1:    a.E1*b.Value + a.Value*b.E1,
+		E2:    a.E2*b.Value + a.Value*b.E2,
+		E12:   a.E12*b.Value + a.E1*b.E2 + a.E2*b.E1 + a.Value*b.E12,
+	}
+}
+
+func (a HyperDual) Div(b HyperDual) HyperDual {
+	return a.Mul(b.inverse())
+}
+
+// inverse returns 1 / a.
+func (a HyperDual) inverse() HyperDual {
+	r := 1 / a.Value
+	return a.chain(r, -r*r, 2*r*r*r)
+}
+
+func (a HyperDual) Neg() HyperDual {
+	return HyperDual{-a.Value, -a.E1, -a.E2, -a.E12}
+}
+
+// AddConst returns a + c.
+func (a HyperDual) AddConst(c float64) HyperDual {
+	a.Value += c
+	return a
+}
+
+// Scale returns c * a.
+func (a HyperDual) Scale(c float64) HyperDual {
+	return HyperDual{c * a.Value, c * a.E1, c * a.E2, c * a.E12}
+}
+
+func (a HyperDual) Exp() HyperDual {
+	e := math.Exp(a.Value)
+	return a.chain(e, e, e)
+}
+
+func (a HyperDual) Log() HyperDual {
+	r := 1 / a.Value
+	return a.chain(math.Log(a.Value), r, -r*r)
+}
+
+func (a HyperDual) Sin() HyperDual {
+	s, c := math.Sincos(a.Value)
+	return a.chain(s, c, -s)
+}
+
+func (a HyperDual) Cos() HyperDual {
+	s, c := math.Sincos(a.Value)
+	return a.chain(c, -s, -c)
+}
+
+func (a HyperDual) Tan() HyperDual {
+	t := math.Tan(a.Value)
+	d := 1 + t*t
+	return a.chain(t, d, 2*t*d)
+}
+
+func (a HyperDual) Sqrt() HyperDual {
+	s := math.Sqrt(a.Value)
+	return a.chain(s, 0.5/s, -0.25/(s*a.Value))
+}
+
+// PowConst returns a^p for a constant exponent.
+func (a HyperDual) PowConst(p float64) HyperDual {
+	if p == 0 {
+		return HyperConstant(1)
+	}
+	return a.chain(math.Pow(a.Value, p), p*math.Pow(a.Value, p-1), p*(p-1)*math.Pow(a.Value, p-2))
+}
+
+// Pow returns a^b = exp(b * log(a)), which requires a > 0 unless b is a constant.
+func (a HyperDual) Pow(b HyperDual) HyperDual {
+	if b.E1 == 0 && b.E2 == 0 && b.E12 == 0 {
+		return a.PowConst(b.Value)
+	}
+	return b.Mul(a.Log()).Exp()
+}
+
+// Abs returns |a|, whose derivatives at zero are taken as zero.
+func (a HyperDual) Abs() HyperDual {
+	switch {
+	case a.Value > 0:
+		return a
+	case a.Value < 0:
+		return a.Neg()
+	}
+	return HyperDual{}
+}
+
+func (a HyperDual) Tanh() HyperDual {
+	t := math.Tanh(a.Value)
+	d := 1 - t*t
+	return a.chain(t, d, -2*t*d)
+}
+
+func (a HyperDual) Atan() HyperDual {
+	d := 1 / (1 + a.Value*a.Value)
+	return a.chain(math.Atan(a.Value), d, -2*a.Value*d*d)
+}
@@ -0,0 +1,507 @@
+package autodiff
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+var ErrTapeMismatch = errors.New("variables are recorded on different tapes")
+
+type opcode uint8
+
+const (
+	opInput opcode = iota
+	opConst
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opNeg
+	opAddConst
+	opScale
+	opExp
+	opLog
+	opSin
+	opCos
+	opTan
+	opSqrt
+	opPowConst
+	opPow
+	opAbs
+	opTanh
+	opAtan
+	opSum
+)
+
+// node is one entry of the Wengert list. a and b are the indices of the operands, or
+// for opSum the offset and count of its terms in Tape.terms.
+type node struct {
+	op   opcode
+	a, b int
+	c    float64
+}
+
+// term is the entry weight * x of a weighted sum.
+type term struct {
+	index  int
+	weight float64
+}
+
+// Tape records the operations on its variables in evaluation order, so that one
+// backward sweep over the record gives the derivatives of an output with respect to
+// every input, at a cost of a small multiple of evaluating the function.
+//
+// The record holds the operations rather than their local derivatives, so a tape can
+// be replayed at new inputs as long as the function takes the same branches there.
+type Tape struct {
+	nodes    []node
+	values   []float64
+	adjoints []float64
+	terms    []term
+	inputs   []int
+}
+
+// Var is a scalar recorded on a tape.
+type Var struct {
+	tape  *Tape
+	index int
+}
+
+// VarFunc is a function of several variables written against recorded scalars.
+type VarFunc func(x []Var) Var
+
+func NewTape() *Tape {
+	return &Tape{}
+}
+
+// Reset clears the record, keeping its storage for the next recording.
+func (t *Tape) Reset() {
+	t.nodes = t.nodes[:0]
+	t.values = t.values[:0]
+	t.terms = t.terms[:0]
+	t.inputs = t.inputs[:0]
+}
+
+// Len returns the number of recorded operations.
+func (t *Tape) Len() int {
+	return len(t.nodes)
+}
+
+// Variable records an input of the function.
+func (t *Tape) Variable(v float64) Var {
+	t.inputs = append(t.inputs, len(t.nodes))
+	return t.push(node{op: opInput, c: v})
+}
+
+// Variables records the inputs x, in order.
+func (t *Tape) Variables(x linalg.Vector) []Var {
+	vars := make([]Var, x.Len())
+	for i, v := range x {
+		vars[i] = t.Variable(v)
+	}
+	return vars
+}
+
+// Constant records a constant, which takes no part in the derivatives.
+func (t *Tape) Constant(v float64) Var {
+	return t.push(node{op: opConst, c: v})
+}
+
+func (t *Tape) push(n node) Var {
+	t.nodes = append(t.nodes, n)
+	t.values = append(t.values, t.eval(n))
+	return Var{t, len(t.nodes) - 1}
+}
+
+// eval returns the value of n from the values of its operands.
+func (t *Tape) eval(n node) float64 {
+	a, b := 0.0, 0.0
+	switch n.op {
+	case opAdd, opSub, opMul, opDiv, opPow:
+		b = t.values[n.b]
+		fallthrough
+	case opNeg, opAddConst, opScale, opExp, opLog, opSin, opCos, opTan, opSqrt, opPowConst,
+		opAbs, opTanh, opAtan:
+		a = t.values[n.a]
+	}
+	switch n.op {
+	case opInput, opConst:
+		return n.c
+	case opAdd:
+		return a + b
+	case opSub:
+		return a - b
+	case opMul:
+		return a * b
+	case opDiv:
+		return a / b
+	case opNeg:
+		return -a
+	case opAddConst:
+		return a + n.c
+	case opScale:
+		return n.c * a
+	case opExp:
+		return math.Exp(a)
+	case opLog:
+		return math.Log(a)
+	case opSin:
+		return math.Sin(a)
+	case opCos:
+		return math.Cos(a)
+	case opTan:
+		return math.Tan(a)
+	case opSqrt:
+		return math.Sqrt(a)
+	case opPowConst:
+		return math.Pow(a, n.c)
+	case opPow:
+		return math.Pow(a, b)
+	case opAbs:
+		return math.Abs(a)
+	case opTanh:
+		return math.Tanh(a)
+	case opAtan:
+		return math.Atan(a)
+	case opSum:
+		s := 0.0
+		for _, e := range t.terms[n.a : n.a+n.b] {
+			s += e.weight * t.values[e.index]
+		}
+		return s
+	}
+	panic("unknown operation")
+}
+
+// Replay evaluates the recorded operations again at the inputs x. Outputs recorded
+// before then take their new values, which are only correct if the function takes the
+// same branches at x as it did when recorded.
+func (t *Tape) Replay(x linalg.Vector) {
+	if x.Len() != len(t.inputs) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	for i, k := range t.inputs {
+		t.nodes[k].c = x[i]
+	}
+	for k, n := range t.nodes {
+		t.values[k] = t.eval(n)
+	}
+}
+
+// Gradient writes the derivatives of y with respect to the inputs, in the order they
+// were recorded, into grad with one backward sweep over the tape.
+func (t *Tape) Gradient(y Var, grad linalg.Vector) {
+	if y.tape != t {
+		panic(ErrTapeMismatch)
+	}
+	if grad.Len() != len(t.inputs) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if cap(t.adjoints) < len(t.nodes) {
+		t.adjoints = make([]float64, len(t.nodes))
+	}
+	adj := t.adjoints[:len(t.nodes)]
+	for k := range adj {
+		adj[k] = 0
+	}
+	adj[y.index] = 1
+
+	for k := y.index; k >= 0; k-- {
+		w := adj[k]
+		if w == 0 {
+			continue
+		}
+		n := t.nodes[k]
+		v := t.values[k]
+		switch n.op {
+		case opAdd:
+			adj[n.a] += w
+			adj[n.b] += w
+		case opSub:
+			adj[n.a] += w
+			adj[n.b] -= w
+		case opMul:
+			adj[n.a] += w * t.values[n.b]
+			adj[n.b] += w * t.values[n.a]
+		case opDiv:
+			d := t.values[n.b]
+			adj[n.a] += w / d
+			adj[n.b] -= w * v / d
+		case opNeg:
+			adj[n.a] -= w
+		case opAddConst:
+			adj[n.a] += w
+		case opScale:
+			adj[n.a] += w * n.c
+		case opExp:
+			adj[n.a] += w * v
+		case opLog:
+			adj[n.a] += w / t.values[n.a]
+		case opSin:
+			adj[n.a] += w * math.Cos(t.values[n.a])
+		case opCos:
+			adj[n.a] -= w * math.Sin(t.values[n.a])
+		case opTan:
+			adj[n.a] += w * (1 + v*v)
+		case opSqrt:
+			adj[n.a] += w * 0.5 / v
+		case opPowConst:
+			if n.c != 0 {
+				adj[n.a] += w * n.c * math.Pow(t.values[n.a], n.c-1)
+			}
+		case opPow:
+			a, b := t.values[n.a], t.values[n.b]
+			adj[n.a] += w * b * math.Pow(a, b-1)
+			if v != 0 {
+				adj[n.b] += w * v * math.Log(a)
+			}
+		case opAbs:
+			switch a := t.values[n.a]; {
+			case a > 0:
+				adj[n.a] += w
+			case a < 0:
+				adj[n.a] -= w
+			}
+		case opTanh:
+			adj[n.a] += w * (1 - v*v)
+		case opAtan:
+			a := t.values[n.a]
+			adj[n.a] += w / (1 + a*a)
+		case opSum:
+			for _, e := range t.terms[n.a : n.a+n.b] {
+				adj[e.index] += w * e.weight
+			}
+		}
+	}
+
+	for i, k := range t.inputs {
+		grad[i] = adj[k]
+	}
+}
+
+// Objective returns the recorded function with output y as an objective, replaying
+// the tape at each point.
+func (t *Tape) Objective(y Var) optim.ObjectiveFunc {
+	return func(x linalg.Vector) float64 {
+		t.Replay(x)
+		return t.values[y.index]
+	}
+}
+
+// GradientFunc returns a gradient function for optim.WithGradientFunc that replays the
+// tape at x and sweeps it backwards from y, so f is recorded once and the record is
+// reused for every iteration. It ignores the objective passed to it and returns the
+// norm of the gradient.
+func (t *Tape) GradientFunc(y Var) func(x linalg.Vector, _ optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+	return func(x linalg.Vector, _ optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		t.Replay(x)
+		t.Gradient(y, gradF)
+		return blas.NRM2(gradF)
+	}
+}
+
+// ReverseGradient returns a gradient function of f for optim.WithGradientFunc that
+// records f afresh at each point, so f may branch on its inputs, and computes the
+// gradient with one backward sweep. It returns the norm of the gradient.
+func ReverseGradient(f VarFunc) func(x linalg.Vector, _ optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+	t := NewTape()
+	return func(x linalg.Vector, _ optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		t.Reset()
+		y := f(t.Variables(x))
+		t.Gradient(y, gradF)
+		return blas.NRM2(gradF)
+	}
+}
+
+// Value returns the value of a as last recorded or replayed.
+func (a Var) Value() float64 {
+	return a.tape.values[a.index]
+}
+
+// Tape returns the tape a is recorded on, for the vector operations inside a VarFunc.
+func (a Var) Tape() *Tape {
+	return a.tape
+}
+
+func (a Var) unary(op opcode, c float64) Var {
+	return a.tape.push(node{op: op, a: a.index, c: c})
+}
+
+func (a Var) binary(op opcode, b Var) Var {
+	if a.tape != b.tape {
+		panic(ErrTapeMismatch)
+	}
+	return a.tape.push(node{op: op, a: a.index, b: b.index})
+}
+
+func (a Var) Add(b Var) Var {
+	return a.binary(opAdd, b)
+}
+
+func (a Var) Sub(b Var) Var {
+	return a.binary(opSub, b)
+}
+
+func (a Var) Mul(b Var) Var {
+	return a.binary(opMul, b)
+}
+
+func (a Var) Div(b Var) Var {
+	return a.binary(opDiv, b)
+}
+
+func (a Var) Neg() Var {
+	return a.unary(opNeg, 0)
+}
+
+// AddConst returns a + c.
+func (a Var) AddConst(c float64) Var {
+	return a.unary(opAddConst, c)
+}
+
+// Scale returns c * a.
+func (a Var) Scale(c float64) Var {
+	return a.unary(opScale, c)
+}
+
+func (a Var) Exp() Var {
+	return a.unary(opExp, 0)
+}
+
+func (a Var) Log() Var {
+	return a.unary(opLog, 0)
+}
+
+func (a Var) Sin() Var {
+	return a.unary(opSin, 0)
+}
+
+func (a Var) Cos() Var {
+	return a.unary(opCos, 0)
+}
+
+func (a Var) Tan() Var {
+	return a.unary(opTan, 0)
+}
+
+func (a Var) Sqrt() Var {
+	return a.unary(opSqrt, 0)
+}
+
+// PowConst returns a^p for a constant exponent.
+func (a Var) PowConst(p float64) Var {
+	return a.unary(opPowConst, p)
+}
+
+// Pow returns a^b, which requires a > 0 for the derivative in b.
+func (a Var) Pow(b Var) Var {
+	return a.binary(opPow, b)
+}
+
+// Abs returns |a|, whose derivative at zero is taken as zero.
+func (a Var) Abs() Var {
+	return a.unary(opAbs, 0)
+}
+
+func (a Var) Tanh() Var {
+	return a.unary(opTanh, 0)
+}
+
+func (a Var) Atan() Var {
+	return a.unary(opAtan, 0)
+}
+
+// sum records the weighted sum of x with the weights w, or unit weights if w is nil.
+func (t *Tape) sum(x []Var, w []float64) Var {
+	offset := len(t.terms)
+	for i, v := range x {
+		if v.tape != t {
+			panic(ErrTapeMismatch)
+		}
+		weight := 1.0
+		if w != nil {
+			weight = w[i]
+		}
+		t.terms = append(t.terms, term{v.index, weight})
+	}
+	return t.push(node{op: opSum, a: offset, b: len(x)})
+}
+
+// Sum returns the sum of x as a single recorded operation.
+func (t *Tape) Sum(x []Var) Var {
+	return t.sum(x, nil)
+}
+
+// Dot returns the dot product of x and y.
+func (t *Tape) Dot(x, y []Var) Var {
+	if len(x) != len(y) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	p := make([]Var, len(x))
+	for i := range x {
+		p[i] = x[i].Mul(y[i])
+	}
+	return t.sum(p, nil)
+}
+
+// DotConst returns the dot product of the constant vector c and x.
+func (t *Tape) DotConst(c linalg.Vector, x []Var) Var {
+	if c.Len() != len(x) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	return t.sum(x, c)
+}
+
+// SquaredNorm returns the squared Euclidean norm of x.
+func (t *Tape) SquaredNorm(x []Var) Var {
+	return t.Dot(x, x)
+}
+
+// MatVec returns A * x for the constant matrix A, with one recorded operation per row.
+func (t *Tape) MatVec(A linalg.Matrix, x []Var) []Var {
+	if A.Cols() != len(x) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	y := make([]Var, A.Rows())
+	row := make([]float64, A.Cols())
+	for i := range y {
+		for j := range row {
+			row[j] = A.Get(i, j)
+		}
+		y[i] = t.sum(x, row)
+	}
+	return y
+}
+
+// AddVec returns x + y.
+func (t *Tape) AddVec(x, y []Var) []Var {
+	if len(x) != len(y) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	z := make([]Var, len(x))
+	for i := range x {
+		z[i] = x[i].Add(y[i])
+	}
+	return z
+}
+
+// SubVec returns x - y.
+func (t *Tape) SubVec(x, y []Var) []Var {
+	if len(x) != len(y) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	z := make([]Var, len(x))
+	for i := range x {
+		z[i] = x[i].Sub(y[i])
+	}
+	return z
+}
+
+// QuadForm returns x^T * A * x for the constant matrix A.
+func (t *Tape) QuadForm(A linalg.Matrix, x []Var) Var {
+	return t.Dot(x, t.MatVec(A, x))
+}
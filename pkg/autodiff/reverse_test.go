@@ -0,0 +1,149 @@
+package autodiff_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/autodiff"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// mixedDual and mixedVar are the same function of three variables, touching every
+// elementary operation.
+func mixedDual(x []autodiff.Dual) autodiff.Dual {
+	a := x[0].Mul(x[1]).Exp().Add(x[2].Sin().Mul(x[0].Cos()))
+	b := x[1].Tan().Sub(x[2].Sqrt().Div(x[0].AddConst(2)))
+	c := x[0].Pow(x[2]).Add(x[1].Tanh().Scale(3)).Sub(x[2].Atan().Neg())
+	d := x[1].Sub(x[2]).Abs().PowConst(1.5).Add(x[2].Log())
+	return a.Mul(b).Add(c).Add(d)
+}
+
+func mixedVar(x []autodiff.Var) autodiff.Var {
+	a := x[0].Mul(x[1]).Exp().Add(x[2].Sin().Mul(x[0].Cos()))
+	b := x[1].Tan().Sub(x[2].Sqrt().Div(x[0].AddConst(2)))
+	c := x[0].Pow(x[2]).Add(x[1].Tanh().Scale(3)).Sub(x[2].Atan().Neg())
+	d := x[1].Sub(x[2]).Abs().PowConst(1.5).Add(x[2].Log())
+	return a.Mul(b).Add(c).Add(d)
+}
+
+func TestReverseMatchesForward(t *testing.T) {
+	points := []linalg.Vector{{0.7, 0.4, 1.3}, {1.2, -0.3, 0.6}, {0.4, 0.9, 2.1}}
+	forward := autodiff.Gradient(mixedDual)
+	reverse := autodiff.ReverseGradient(mixedVar)
+
+	// record once at the first point and replay the tape at the others
+	tape := autodiff.NewTape()
+	y := mixedVar(tape.Variables(points[0]))
+	replayed := tape.GradientFunc(y)
+	objective := tape.Objective(y)
+
+	want, got := linalg.NewVector(3), linalg.NewVector(3)
+	for _, x := range points {
+		wantNorm := forward(x, nil, want)
+		for name, grad := range map[string]func(linalg.Vector, optim.ObjectiveFunc, linalg.Vector) float64{
+			"recorded": reverse,
+			"replayed": replayed,
+		} {
+			nrm := grad(x, nil, got)
+			for i := range got {
+				if math.Abs(got[i]-want[i]) > 1e-12*math.Max(1, math.Abs(want[i])) {
+					t.Errorf("%s at %v: expected gradient %v, got %v", name, x, want, got)
+					break
+				}
+			}
+			if math.Abs(nrm-wantNorm) > 1e-12*wantNorm {
+				t.Errorf("%s at %v: expected norm %v, got %v", name, x, wantNorm, nrm)
+			}
+		}
+		if v, w := objective(x), autodiff.Objective(mixedDual)(x); math.Abs(v-w) > 1e-12*math.Abs(w) {
+			t.Errorf("replayed objective at %v: expected %v, got %v", x, w, v)
+		}
+		if y.Value() != objective(x) {
+			t.Errorf("expected the output to follow the replay")
+		}
+	}
+}
+
+func TestReverseVectorOperations(t *testing.T) {
+	A := linalg.NewDenseMatrix(3, 3)
+	for i, v := range []float64{4, 1, -2, 0, 3, 1, 2, -1, 5} {
+		A.Set(i/3, i%3, v)
+	}
+	c := linalg.Vector{1, -2, 0.5}
+	x := linalg.Vector{0.3, -1.1, 2}
+
+	// f = x^T * A * x + c^T * x + |A * x - x|^2 / 2 + sum(x)
+	tape := autodiff.NewTape()
+	xv := tape.Variables(x)
+	Ax := tape.MatVec(A, xv)
+	f := tape.QuadForm(A, xv).Add(tape.DotConst(c, xv)).
+		Add(tape.SquaredNorm(tape.SubVec(Ax, xv)).Scale(0.5)).Add(tape.Sum(xv))
+	g := linalg.NewVector(3)
+	tape.Gradient(f, g)
+
+	// gradient (A + A^T) x + c + (A - I)^T (A - I) x + 1
+	r := linalg.NewVector(3)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i] += A.Get(i, j) * x[j]
+		}
+		r[i] -= x[i]
+	}
+	for i := 0; i < 3; i++ {
+		want := c[i] + 1 - r[i]
+		for j := 0; j < 3; j++ {
+			want += (A.Get(i, j)+A.Get(j, i))*x[j] + A.Get(j, i)*r[j]
+		}
+		if math.Abs(g[i]-want) > 1e-12 {
+			t.Errorf("g[%d]: expected %v, got %v", i, want, g[i])
+		}
+	}
+
+	// the sums are single operations, so the tape stays linear in the size of A
+	if tape.Len() > 40 {
+		t.Errorf("expected a short tape, got %d operations", tape.Len())
+	}
+}
+
+func TestReverseGradientSolve(t *testing.T) {
+	// the extended Rosenbrock function in 40 variables, recorded once
+	n := 40
+	f := func(x []autodiff.Var) autodiff.Var {
+		terms := make([]autodiff.Var, 0, n)
+		for i := 0; i < n; i += 2 {
+			a := x[i].Neg().AddConst(1)
+			b := x[i+1].Sub(x[i].Mul(x[i]))
+			terms = append(terms, a.Mul(a), b.Mul(b).Scale(100))
+		}
+		return x[0].Tape().Sum(terms)
+	}
+	x0 := linalg.NewVector(n)
+	for i := 0; i < n; i += 2 {
+		x0[i], x0[i+1] = -1.2, 1
+	}
+	tape := autodiff.NewTape()
+	y := f(tape.Variables(x0))
+
+	sol := optim.NewLBFGSBSolver().Solve(tape.Objective(y), x0,
+		optim.WithGradientFunc(tape.GradientFunc(y)), optim.WithTolerance(1e-10), optim.WithMaxIterations(1000))
+	if !sol.ValidSolution {
+		t.Fatalf("expected a solution, got %+v", sol)
+	}
+	for i, v := range sol.Result {
+		if math.Abs(v-1) > 1e-6 {
+			t.Fatalf("x[%d]: expected 1, got %v", i, v)
+		}
+	}
+}
+
+func TestReverseTapeMismatch(t *testing.T) {
+	defer func() {
+		if r := recover(); r != autodiff.ErrTapeMismatch {
+			t.Errorf("expected ErrTapeMismatch, got %v", r)
+		}
+	}()
+	a := autodiff.NewTape().Variable(1)
+	b := autodiff.NewTape().Variable(2)
+	a.Add(b)
+}
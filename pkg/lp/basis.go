@@ -0,0 +1,86 @@
+package lp
+
+import (
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var SIMPLEX_REFACTOR_INTERVAL = 50 // basis updates between refactorizations
+
+// eta is the elementary matrix of one basis change: column p of the basis was replaced
+// by a column whose representation in the previous basis is alpha.
+type eta struct {
+	p     int
+	alpha linalg.Vector
+}
+
+// basisFactor is the product form of the inverse of a basis
+//
+//	B^-1 = E_k^-1 * ... * E_1^-1 * B_0^-1
+//
+// where B_0 = L * U is the basis at the last refactorization.
+type basisFactor struct {
+	lu   linalg.LU
+	etas []eta
+}
+
+// factorBasis factors the basis whose columns are the variables in head. Variable j < n
+// is column j of A and variable n + i is the slack of row i, with column -e_i.
+func factorBasis(A linalg.SparseMatrix, head []int) (*basisFactor, error) {
+	n := A.Cols()
+	m := A.Rows()
+	B := linalg.NewDenseMatrix(m, m)
+	for k, j := range head {
+		if j >= n {
+			B.Set(j-n, k, -1)
+			continue
+		}
+		rows, values := A.Column(j)
+		for t, i := range rows {
+			B.Set(i, k, values[t])
+		}
+	}
+	lu, err := linalg.FactorLU(B)
+	if err != nil {
+		return nil, err
+	}
+	return &basisFactor{lu: lu}, nil
+}
+
+// ftran solves B * x = v in place.
+func (f *basisFactor) ftran(v linalg.Vector) {
+	f.lu.Solve(v, v)
+	for _, e := range f.etas {
+		vp := v[e.p] / e.alpha[e.p]
+		for i := range v {
+			v[i] -= e.alpha[i] * vp
+		}
+		v[e.p] = vp
+	}
+}
+
+// btran solves B^T * x = v in place.
+func (f *basisFactor) btran(v linalg.Vector) {
+	for k := len(f.etas) - 1; k >= 0; k-- {
+		e := f.etas[k]
+		sum := v[e.p]
+		for i := range v {
+			if i != e.p {
+				sum -= e.alpha[i] * v[i]
+			}
+		}
+		v[e.p] = sum / e.alpha[e.p]
+	}
+	f.lu.SolveTranspose(v, v)
+}
+
+// update replaces column p of the basis by the column with representation alpha.
+func (f *basisFactor) update(p int, alpha linalg.Vector) {
+	a := linalg.NewVector(alpha.Len())
+	copy(a, alpha)
+	f.etas = append(f.etas, eta{p: p, alpha: a})
+}
+
+// updates returns the number of basis changes since the last refactorization.
+func (f *basisFactor) updates() int {
+	return len(f.etas)
+}
@@ -0,0 +1,113 @@
+package lp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// crossover moves an interior solution to an optimal basic solution. A basis is built
+// from the variables farthest from their bounds, with the others placed at their nearest
+// bound, and the simplex method is warm started from it. Since the nonbasic variables
+// are close to their optimal values only a few simplex iterations are usually needed.
+func crossover(p *Problem, interior *solution, opts *solveOptions) *solution {
+	n := p.C.Len()
+	m := p.A.Rows()
+	lower, upper := p.bounds()
+	value := func(j int) float64 {
+		if j < n {
+			return interior.X[j]
+		}
+		return interior.RowActivities[j-n]
+	}
+
+	// order the variables by their distance to the nearest bound
+	distance := make([]float64, n+m)
+	order := make([]int, n+m)
+	for j := range order {
+		order[j] = j
+		v := value(j)
+		distance[j] = math.Min(v-lower[j], upper[j]-v)
+		if lower[j] == upper[j] {
+			distance[j] = math.Inf(-1)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return distance[order[a]] > distance[order[b]]
+	})
+
+	// greedily select linearly independent columns by Gaussian elimination
+	basis := Basis{
+		Columns: make([]BasisStatus, n),
+		Rows:    make([]BasisStatus, m),
+	}
+	setStatus := func(j int, st BasisStatus) {
+		if j < n {
+			basis.Columns[j] = st
+		} else {
+			basis.Rows[j-n] = st
+		}
+	}
+	reduced := make([]linalg.Vector, 0, m)
+	pivots := make([]int, 0, m)
+	pivoted := make([]bool, m)
+	col := linalg.NewVector(m)
+	for _, j := range order {
+		v := value(j)
+		switch {
+		case math.IsInf(lower[j], -1) && math.IsInf(upper[j], 1):
+			setStatus(j, Free)
+		case v-lower[j] <= upper[j]-v:
+			setStatus(j, AtLower)
+		default:
+			setStatus(j, AtUpper)
+		}
+		if len(pivots) == m {
+			continue
+		}
+
+		col.Zero()
+		scale := 1.0
+		if j < n {
+			rows, values := p.A.Column(j)
+			for k, i := range rows {
+				col[i] = values[k]
+				scale = math.Max(scale, math.Abs(values[k]))
+			}
+		} else {
+			col[j-n] = -1
+		}
+		for k, w := range reduced {
+			if f := col[pivots[k]] / w[pivots[k]]; f != 0 {
+				for i := range col {
+					col[i] -= f * w[i]
+				}
+			}
+		}
+		pivot, best := -1, 1e-9*scale
+		for i := range col {
+			if !pivoted[i] && math.Abs(col[i]) > best {
+				pivot, best = i, math.Abs(col[i])
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		w := linalg.NewVector(m)
+		copy(w, col)
+		reduced = append(reduced, w)
+		pivots = append(pivots, pivot)
+		pivoted[pivot] = true
+		setStatus(j, Basic)
+	}
+
+	simplexOpts := *opts
+	simplexOpts.basis = &basis
+	simplexOpts.maxIterations = 0
+	sol := (&simplexSolver{}).solve(p, &simplexOpts)
+	sol.Crossover = sol.Iterations
+	sol.Phase1 = 0
+	sol.Iterations = interior.Iterations
+	return sol
+}
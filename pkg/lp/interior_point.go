@@ -0,0 +1,495 @@
+package lp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var IPM_MAX_ITERATIONS = 200
+var IPM_STEP_FRACTION = 0.995  // fraction of the step to the boundary
+var IPM_REGULARIZATION = 1e-10 // primal regularization of free variables
+var IPM_DIVERGENCE = 1e8       // relative iterate size taken as evidence of infeasibility
+
+// interiorPointSolver is Mehrotra's predictor-corrector primal-dual interior point
+// method. Rows with distinct bounds get a slack so that the problem becomes
+//
+//	minimize c^T * v subject to A' * v = b, lower <= v <= upper
+//
+// and each iteration solves the normal equations A' * D^-1 * A'^T * dy = r with a
+// dense Cholesky factorization, where D holds the barrier terms of the bounds.
+//
+// Reference: S. Mehrotra, "On the implementation of a primal-dual interior point
+// method", SIAM Journal on Optimization 2, 1992.
+type interiorPointSolver struct{}
+
+// standardForm is a linear program with equality rows and bounded variables. Fixed
+// structural columns are left out, since they would start on both bounds at once.
+type standardForm struct {
+	A       linalg.SparseMatrix
+	b       linalg.Vector
+	c       linalg.Vector
+	lower   linalg.Vector
+	upper   linalg.Vector
+	columns []int         // structural column of each leading column of A
+	fixed   linalg.Vector // values of the structural columns, set for fixed ones
+}
+
+func newStandardForm(p *Problem) *standardForm {
+	n := p.C.Len()
+	m := p.A.Rows()
+	lower, upper := p.bounds()
+	sf := &standardForm{
+		fixed: linalg.NewVector(n),
+	}
+	for j := 0; j < n; j++ {
+		if lower[j] == upper[j] {
+			sf.fixed[j] = lower[j]
+		} else {
+			sf.columns = append(sf.columns, j)
+		}
+	}
+	cols := len(sf.columns)
+	for i := 0; i < m; i++ {
+		if lower[n+i] != upper[n+i] {
+			cols++
+		}
+	}
+
+	sf.b = linalg.NewVector(m)
+	sf.c = linalg.NewVector(cols)
+	sf.lower = linalg.NewVector(cols)
+	sf.upper = linalg.NewVector(cols)
+	t := linalg.NewTripletMatrix(m, cols)
+	for k, j := range sf.columns {
+		sf.c[k] = p.C[j]
+		sf.lower[k] = lower[j]
+		sf.upper[k] = upper[j]
+		rows, values := p.A.Column(j)
+		for a, i := range rows {
+			t.Append(i, k, values[a])
+		}
+	}
+
+	// the activity of the fixed columns moves to the right-hand side
+	activity := linalg.NewVector(m)
+	blas.SPMV(1.0, p.A, sf.fixed, 0.0, activity)
+	j := len(sf.columns)
+	for i := 0; i < m; i++ {
+		rl, ru := lower[n+i]-activity[i], upper[n+i]-activity[i]
+		if rl == ru {
+			sf.b[i] = rl
+			continue
+		}
+		// slack s_i = A_i * x with the row bounds
+		t.Append(i, j, -1)
+		sf.lower[j] = rl
+		sf.upper[j] = ru
+		j++
+	}
+	sf.A = t.ToCSC()
+	return sf
+}
+
+func (s *interiorPointSolver) Solve(p *Problem, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	return s.solve(p, opts)
+}
+
+func (s *interiorPointSolver) solve(p *Problem, opts *solveOptions) *solution {
+	var sol *solution
+	if opts.presolve {
+		ps := presolve(p)
+		if ps.status != Optimal {
+			sol = &solution{Status: ps.status, X: linalg.NewVector(p.C.Len()), RowDuals: linalg.NewVector(p.A.Rows())}
+			finishSolution(p, sol)
+		} else {
+			sol = ps.postsolve(s.solveStandardForm(newStandardForm(ps.reduced), opts))
+		}
+	} else {
+		sol = s.solveStandardForm(newStandardForm(p), opts)
+		finishSolution(p, sol)
+	}
+
+	// the interior point method and presolve detect infeasibility and unboundedness
+	// without the certificates of the simplex method, and a ray alone does not tell
+	// unboundedness from infeasibility, so the simplex method settles these cases
+	switch sol.Status {
+	case Infeasible, Unbounded, NumericalError:
+		iterations := sol.Iterations
+		simplexOpts := *opts
+		simplexOpts.basis = nil
+		simplexOpts.maxIterations = 0
+		sol = (&simplexSolver{}).solve(p, &simplexOpts)
+		sol.Iterations += iterations
+		return sol
+	}
+
+	if opts.crossover && sol.Status == Optimal {
+		return crossover(p, sol, opts)
+	}
+	return sol
+}
+
+// solveStandardForm returns the structural columns and row duals of the solution.
+func (s *interiorPointSolver) solveStandardForm(sf *standardForm, opts *solveOptions) *solution {
+	A := sf.A
+	m := A.Rows()
+	N := A.Cols()
+	maxIterations := opts.maxIterations
+	if maxIterations == 0 {
+		maxIterations = IPM_MAX_ITERATIONS
+	}
+	tolerance := opts.tolerance
+
+	hasLower := make([]bool, N)
+	hasUpper := make([]bool, N)
+	bounded := 0
+	for j := 0; j < N; j++ {
+		hasLower[j] = !math.IsInf(sf.lower[j], -1)
+		hasUpper[j] = !math.IsInf(sf.upper[j], 1)
+		if hasLower[j] {
+			bounded++
+		}
+		if hasUpper[j] {
+			bounded++
+		}
+	}
+
+	// starting point strictly inside the bounds
+	v := linalg.NewVector(N)
+	y := linalg.NewVector(m)
+	zl := linalg.NewVector(N)
+	zu := linalg.NewVector(N)
+	for j := 0; j < N; j++ {
+		l, u := sf.lower[j], sf.upper[j]
+		switch {
+		case hasLower[j] && hasUpper[j]:
+			v[j] = math.Min(math.Max(0, l+math.Min(1, (u-l)/2)), u-math.Min(1, (u-l)/2))
+		case hasLower[j]:
+			v[j] = math.Max(0, l+1)
+		case hasUpper[j]:
+			v[j] = math.Min(0, u-1)
+		}
+		if hasLower[j] {
+			zl[j] = 1
+		}
+		if hasUpper[j] {
+			zu[j] = 1
+		}
+	}
+
+	gl := linalg.NewVector(N)
+	gu := linalg.NewVector(N)
+	rb := linalg.NewVector(m)
+	rc := linalg.NewVector(N)
+	rl := linalg.NewVector(N)
+	ru := linalg.NewVector(N)
+	d := linalg.NewVector(N)
+	dv := linalg.NewVector(N)
+	dy := linalg.NewVector(m)
+	dzl := linalg.NewVector(N)
+	dzu := linalg.NewVector(N)
+	dvAff := linalg.NewVector(N)
+	dzlAff := linalg.NewVector(N)
+	dzuAff := linalg.NewVector(N)
+	r := linalg.NewVector(N)
+	rhs := linalg.NewVector(m)
+
+	// direction solves the Newton system for the complementarity right-hand sides rl, ru.
+	var chol linalg.Cholesky
+	direction := func() {
+		for j := 0; j < N; j++ {
+			r[j] = rc[j]
+			if hasLower[j] {
+				r[j] -= rl[j] / gl[j]
+			}
+			if hasUpper[j] {
+				r[j] += ru[j] / gu[j]
+			}
+		}
+		blas.COPY(rb, rhs)
+		for j := 0; j < N; j++ {
+			r[j] /= d[j]
+		}
+		blas.SPMV(1.0, A, r, 1.0, rhs)
+		for j := 0; j < N; j++ {
+			r[j] *= d[j]
+		}
+		chol.Solve(rhs, dy)
+		blas.SPMVT(1.0, A, dy, 0.0, dv)
+		for j := 0; j < N; j++ {
+			dv[j] = (dv[j] - r[j]) / d[j]
+			dzl[j], dzu[j] = 0, 0
+			if hasLower[j] {
+				dzl[j] = (rl[j] - zl[j]*dv[j]) / gl[j]
+			}
+			if hasUpper[j] {
+				dzu[j] = (ru[j] + zu[j]*dv[j]) / gu[j]
+			}
+		}
+	}
+
+	// stepLengths returns the largest primal and dual steps that keep the bounds.
+	stepLengths := func() (float64, float64) {
+		ap, ad := 1.0, 1.0
+		for j := 0; j < N; j++ {
+			if hasLower[j] {
+				if dv[j] < 0 {
+					ap = math.Min(ap, -gl[j]/dv[j])
+				}
+				if dzl[j] < 0 {
+					ad = math.Min(ad, -zl[j]/dzl[j])
+				}
+			}
+			if hasUpper[j] {
+				if dv[j] > 0 {
+					ap = math.Min(ap, gu[j]/dv[j])
+				}
+				if dzu[j] < 0 {
+					ad = math.Min(ad, -zu[j]/dzu[j])
+				}
+			}
+		}
+		return ap, ad
+	}
+
+	complementarity := func(ap, ad float64) float64 {
+		sum := 0.0
+		for j := 0; j < N; j++ {
+			if hasLower[j] {
+				sum += (gl[j] + ap*dv[j]) * (zl[j] + ad*dzl[j])
+			}
+			if hasUpper[j] {
+				sum += (gu[j] - ap*dv[j]) * (zu[j] + ad*dzu[j])
+			}
+		}
+		return sum / float64(bounded)
+	}
+
+	bNorm := infNorm(sf.b)
+	cNorm := infNorm(sf.c)
+	boundNorm := bNorm
+	for j := 0; j < N; j++ {
+		if hasLower[j] {
+			boundNorm = math.Max(boundNorm, math.Abs(sf.lower[j]))
+		}
+		if hasUpper[j] {
+			boundNorm = math.Max(boundNorm, math.Abs(sf.upper[j]))
+		}
+	}
+	status := IterationLimit
+	iter := 0
+	for ; iter <= maxIterations; iter++ {
+		for j := 0; j < N; j++ {
+			gl[j] = v[j] - sf.lower[j]
+			gu[j] = sf.upper[j] - v[j]
+		}
+
+		// residuals rb = b - A * v and rc = c - A^T * y - zl + zu
+		blas.COPY(sf.b, rb)
+		blas.SPMV(-1.0, A, v, 1.0, rb)
+		blas.COPY(sf.c, rc)
+		blas.SPMVT(-1.0, A, y, 1.0, rc)
+		blas.AXPY(-1.0, zl, rc)
+		blas.AXPY(1.0, zu, rc)
+
+		mu := 0.0
+		if bounded > 0 {
+			mu = complementarity(0, 0)
+		}
+		primalObjective := blas.DOT(sf.c, v)
+		dualObjective := blas.DOT(sf.b, y)
+		for j := 0; j < N; j++ {
+			if hasLower[j] {
+				dualObjective += sf.lower[j] * zl[j]
+			}
+			if hasUpper[j] {
+				dualObjective -= sf.upper[j] * zu[j]
+			}
+		}
+		primalFeasible := infNorm(rb) <= tolerance*(1+bNorm)
+		dualFeasible := infNorm(rc) <= tolerance*(1+cNorm)
+		gap := math.Abs(primalObjective-dualObjective) <= tolerance*(1+math.Abs(primalObjective))
+		if primalFeasible && dualFeasible && gap {
+			status = Optimal
+			break
+		}
+		// the dual iterate of an infeasible problem turns into a Farkas certificate, and
+		// one that diverges without becoming a certificate is a numerical failure. A
+		// primal iterate diverging along a ray proves dual infeasibility, which solve
+		// tells apart from infeasibility.
+		if !primalFeasible && isFarkas(sf, y, hasLower, hasUpper, tolerance*(1+boundNorm)) {
+			status = Infeasible
+			break
+		}
+		if !primalFeasible && math.Max(infNorm(y), math.Max(infNorm(zl), infNorm(zu))) > IPM_DIVERGENCE*(1+cNorm) {
+			status = NumericalError
+			break
+		}
+		if !dualFeasible && infNorm(v) > IPM_DIVERGENCE*(1+boundNorm) && isRay(sf, v, hasLower, hasUpper, tolerance) {
+			status = Unbounded
+			break
+		}
+		if math.IsNaN(mu) || math.IsNaN(primalObjective) {
+			status = NumericalError
+			break
+		}
+		if iter == maxIterations {
+			break
+		}
+
+		// normal matrix A * D^-1 * A^T
+		for j := 0; j < N; j++ {
+			d[j] = IPM_REGULARIZATION
+			if hasLower[j] {
+				d[j] += zl[j] / gl[j]
+			}
+			if hasUpper[j] {
+				d[j] += zu[j] / gu[j]
+			}
+		}
+		M := linalg.NewDenseMatrix(m, m)
+		maxDiag := 0.0
+		for j := 0; j < N; j++ {
+			rows, values := A.Column(j)
+			for a, i := range rows {
+				for b, k := range rows[:a+1] {
+					M.Set(i, k, M.Get(i, k)+values[a]*values[b]/d[j])
+				}
+			}
+		}
+		for i := 0; i < m; i++ {
+			maxDiag = math.Max(maxDiag, M.Get(i, i))
+		}
+		var err error
+		for delta := 0.0; ; {
+			chol, err = linalg.FactorCholesky(M)
+			if err == nil {
+				break
+			}
+			// regularize a rank deficient normal matrix
+			if delta == 0 {
+				delta = 1e-12 * math.Max(maxDiag, 1)
+			} else {
+				delta *= 100
+			}
+			if !(delta <= math.Max(maxDiag, 1)) {
+				break
+			}
+			for i := 0; i < m; i++ {
+				M.Set(i, i, M.Get(i, i)+delta)
+			}
+		}
+		if err != nil {
+			status = NumericalError
+			break
+		}
+
+		// predictor
+		for j := 0; j < N; j++ {
+			rl[j], ru[j] = -gl[j]*zl[j], -gu[j]*zu[j]
+		}
+		direction()
+		ap, ad := stepLengths()
+		sigma := 0.0
+		if bounded > 0 {
+			sigma = math.Pow(complementarity(ap, ad)/mu, 3)
+		}
+		blas.COPY(dv, dvAff)
+		blas.COPY(dzl, dzlAff)
+		blas.COPY(dzu, dzuAff)
+
+		// corrector
+		for j := 0; j < N; j++ {
+			if hasLower[j] {
+				rl[j] = sigma*mu - gl[j]*zl[j] - dvAff[j]*dzlAff[j]
+			}
+			if hasUpper[j] {
+				ru[j] = sigma*mu - gu[j]*zu[j] + dvAff[j]*dzuAff[j]
+			}
+		}
+		direction()
+		ap, ad = stepLengths()
+		ap = math.Min(1, IPM_STEP_FRACTION*ap)
+		ad = math.Min(1, IPM_STEP_FRACTION*ad)
+
+		blas.AXPY(ap, dv, v)
+		blas.AXPY(ad, dy, y)
+		blas.AXPY(ad, dzl, zl)
+		blas.AXPY(ad, dzu, zu)
+	}
+
+	x := linalg.NewVector(sf.fixed.Len())
+	blas.COPY(sf.fixed, x)
+	for k, j := range sf.columns {
+		x[j] = v[k]
+	}
+	return &solution{
+		Status:     status,
+		X:          x,
+		RowDuals:   y,
+		Iterations: iter,
+	}
+}
+
+// isRay reports whether the direction of v is a ray of the standard form: A * d = 0,
+// c^T * d < 0 and d keeps the finite bounds, with d = v / ||v||_inf.
+func isRay(sf *standardForm, v linalg.Vector, hasLower, hasUpper []bool, tolerance float64) bool {
+	d := linalg.NewVector(v.Len())
+	blas.COPY(v, d)
+	blas.SCAL(1/infNorm(v), d)
+	for j := range d {
+		if hasLower[j] && d[j] < -tolerance || hasUpper[j] && d[j] > tolerance {
+			return false
+		}
+	}
+	Ad := linalg.NewVector(sf.A.Rows())
+	blas.SPMV(1.0, sf.A, d, 0.0, Ad)
+	return infNorm(Ad) <= math.Sqrt(tolerance) && blas.DOT(sf.c, d) < 0
+}
+
+// isFarkas reports whether the direction of y certifies that the standard form is
+// infeasible: with d = y / ||y||_inf and w = A^T * d, every v within the bounds has
+// w^T * v < b^T * d by more than margin. Components of w below the margin are ignored
+// on unbounded sides.
+func isFarkas(sf *standardForm, y linalg.Vector, hasLower, hasUpper []bool, margin float64) bool {
+	norm := infNorm(y)
+	if norm == 0 {
+		return false
+	}
+	d := linalg.NewVector(y.Len())
+	blas.COPY(y, d)
+	blas.SCAL(1/norm, d)
+	w := linalg.NewVector(sf.A.Cols())
+	blas.SPMVT(1.0, sf.A, d, 0.0, w)
+	bound := 0.0
+	for j := range w {
+		switch {
+		case w[j] > 0 && hasUpper[j]:
+			bound += w[j] * sf.upper[j]
+		case w[j] < 0 && hasLower[j]:
+			bound += w[j] * sf.lower[j]
+		case math.Abs(w[j]) > margin:
+			return false
+		}
+	}
+	return blas.DOT(sf.b, d)-bound > margin
+}
+
+func infNorm(v linalg.Vector) float64 {
+	if v.Len() == 0 {
+		return 0
+	}
+	return math.Abs(v[blas.IAMAX(v)])
+}
+
+// NewInteriorPointSolver returns a primal-dual interior point solver for large linear
+// programs. The solution is interior unless WithCrossover is given, in which case it is
+// moved to an optimal basis with the simplex method. A problem the interior point method
+// finds infeasible or unbounded, or fails on, is solved again by the simplex method, whose
+// status comes with a certificate.
+func NewInteriorPointSolver() *interiorPointSolver {
+	return &interiorPointSolver{}
+}
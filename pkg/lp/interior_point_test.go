@@ -0,0 +1,279 @@
+package lp_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+)
+
+// coveringProblem returns the covering dual of packingProblem: A * x >= b, x >= 0.
+func coveringProblem(n, m int) *lp.Problem {
+	p := packingProblem(n, m, 0)
+	p.RowLower, p.RowUpper = p.RowUpper, nil
+	for j := range p.C {
+		p.C[j] = -p.C[j]
+	}
+	p.Upper = nil
+	return p
+}
+
+func TestInteriorPointMatchesSimplex(t *testing.T) {
+	problems := map[string]*lp.Problem{
+		"packing":  packingProblem(60, 40, 0),
+		"covering": coveringProblem(60, 40),
+		"ranged":   rangedProblem(-3),
+	}
+	for name, problem := range problems {
+		simplex := lp.NewSimplexSolver().Solve(problem)
+		for _, presolve := range []bool{true, false} {
+			sol := lp.NewInteriorPointSolver().Solve(problem, lp.WithPresolve(presolve))
+			if sol.Status != lp.Optimal {
+				t.Fatalf("%s, presolve %v: expected optimal, got %v", name, presolve, sol.Status)
+			}
+			if math.Abs(sol.Objective-simplex.Objective) > 1e-6*(1+math.Abs(simplex.Objective)) {
+				t.Errorf("%s, presolve %v: objective %v differs from simplex %v", name, presolve, sol.Objective, simplex.Objective)
+			}
+			if sol.Infeasibility > 1e-6 || sol.DualInfeasible > 1e-6 {
+				t.Errorf("%s, presolve %v: infeasibility %v, dual infeasibility %v",
+					name, presolve, sol.Infeasibility, sol.DualInfeasible)
+			}
+		}
+	}
+}
+
+func TestInteriorPointPresolve(t *testing.T) {
+	// x3 is fixed, row 2 is a singleton giving x2 >= 1, row 3 is empty and x4 is an
+	// empty column with a positive cost
+	inf := math.Inf(1)
+	problem := &lp.Problem{
+		C: linalg.Vector{1, 2, 2, 1},
+		A: sparseMatrix([][]float64{
+			{1, 1, 1, 0},
+			{0, 2, 0, 0},
+			{0, 0, 0, 0},
+		}),
+		RowLower: linalg.Vector{4, 2, -1},
+		RowUpper: linalg.Vector{inf, inf, 1},
+		Lower:    linalg.Vector{0, 0, 1, 3},
+		Upper:    linalg.Vector{inf, inf, 1, 5},
+	}
+	sol := lp.NewInteriorPointSolver().Solve(problem)
+	if sol.Status != lp.Optimal {
+		t.Fatalf("expected optimal, got %v", sol.Status)
+	}
+	expected := linalg.Vector{2, 1, 1, 3}
+	for j := range expected {
+		if math.Abs(sol.X[j]-expected[j]) > 1e-6 {
+			t.Fatalf("expected %v, got %v", expected, sol.X)
+		}
+	}
+	// the reduced cost of x2 moves to the dual of its singleton row
+	if math.Abs(sol.RowDuals[0]-1) > 1e-6 || math.Abs(sol.RowDuals[1]-0.5) > 1e-6 || math.Abs(sol.ReducedCosts[1]) > 1e-6 {
+		t.Errorf("expected row duals (1, 0.5, 0) and a zero reduced cost for x2, got %v and %v", sol.RowDuals, sol.ReducedCosts)
+	}
+
+	// the singleton row conflicts with an upper bound
+	problem.Upper[1] = 0.5
+	if sol := lp.NewInteriorPointSolver().Solve(problem); sol.Status != lp.Infeasible {
+		t.Errorf("expected infeasible, got %v", sol.Status)
+	}
+}
+
+func TestInteriorPointFixedColumn(t *testing.T) {
+	// x2 is fixed at 1 and must not start on both of its bounds without presolve
+	inf := math.Inf(1)
+	problem := &lp.Problem{
+		C:        linalg.Vector{1, 2, 2},
+		A:        sparseMatrix([][]float64{{1, 1, 1}, {0, 2, 1}}),
+		RowLower: linalg.Vector{4, 3},
+		RowUpper: linalg.Vector{inf, inf},
+		Lower:    linalg.Vector{0, 0, 1},
+		Upper:    linalg.Vector{inf, inf, 1},
+	}
+	sol := lp.NewInteriorPointSolver().Solve(problem, lp.WithPresolve(false))
+	if sol.Status != lp.Optimal {
+		t.Fatalf("expected optimal, got %v", sol.Status)
+	}
+	expected := linalg.Vector{2, 1, 1}
+	for j := range expected {
+		if math.Abs(sol.X[j]-expected[j]) > 1e-6 {
+			t.Fatalf("expected %v, got %v", expected, sol.X)
+		}
+	}
+	if sol.Infeasibility > 1e-6 || sol.DualInfeasible > 1e-6 {
+		t.Errorf("infeasibility %v, dual infeasibility %v", sol.Infeasibility, sol.DualInfeasible)
+	}
+}
+
+func TestInteriorPointCrossover(t *testing.T) {
+	problem := coveringProblem(60, 40)
+	simplex := lp.NewSimplexSolver().Solve(problem)
+	sol := lp.NewInteriorPointSolver().Solve(problem, lp.WithCrossover(true))
+	if sol.Status != lp.Optimal {
+		t.Fatalf("expected optimal, got %v", sol.Status)
+	}
+	if math.Abs(sol.Objective-simplex.Objective) > 1e-9*(1+math.Abs(simplex.Objective)) {
+		t.Errorf("objective %v differs from simplex %v", sol.Objective, simplex.Objective)
+	}
+	if sol.Crossover >= simplex.Iterations {
+		t.Errorf("crossover took %d simplex iterations, a cold simplex %d", sol.Crossover, simplex.Iterations)
+	}
+	basic := 0
+	for _, st := range append(sol.Basis.Columns, sol.Basis.Rows...) {
+		if st == lp.Basic {
+			basic++
+		}
+	}
+	if basic != 40 {
+		t.Errorf("expected 40 basic variables, got %d", basic)
+	}
+}
+
+func TestInteriorPointDetection(t *testing.T) {
+	infeasible := lp.NewInteriorPointSolver().Solve(rangedProblem(2), lp.WithPresolve(false))
+	if infeasible.Status != lp.Infeasible {
+		t.Errorf("expected infeasible, got %v", infeasible.Status)
+	}
+
+	unbounded := &lp.Problem{
+		C:        linalg.Vector{-1, 0},
+		A:        sparseMatrix([][]float64{{1, -1}}),
+		RowUpper: linalg.Vector{1},
+		Lower:    linalg.Vector{0, 0},
+	}
+	if sol := lp.NewInteriorPointSolver().Solve(unbounded); sol.Status != lp.Unbounded {
+		t.Errorf("expected unbounded, got %v", sol.Status)
+	}
+}
+
+func TestInteriorPointInfeasibleFreeColumn(t *testing.T) {
+	// the rows x2 <= 1 and x1 + x2 >= 2, x2 >= 3 conflict while the free x1 improves the
+	// objective without bound, which must not be taken as unboundedness
+	inf := math.Inf(1)
+	problem := &lp.Problem{
+		C:        linalg.Vector{-1, 0},
+		A:        sparseMatrix([][]float64{{0, 1}, {1, 1}, {0, 1}}),
+		RowLower: linalg.Vector{-inf, 2, 3},
+		RowUpper: linalg.Vector{1, inf, inf},
+	}
+	for _, presolve := range []bool{true, false} {
+		if sol := lp.NewInteriorPointSolver().Solve(problem, lp.WithPresolve(presolve)); sol.Status != lp.Infeasible {
+			t.Errorf("presolve %v: expected infeasible, got %v", presolve, sol.Status)
+		}
+	}
+}
+
+func TestInteriorPointPresolveUnboundedColumn(t *testing.T) {
+	// column 2 is free with a positive cost and only an explicit zero entry, so presolve
+	// finds it unbounded, but rows 1 and 2 force 24 * x3 <= -1 against x3 >= 0
+	inf := math.Inf(1)
+	A := linalg.NewTripletMatrix(3, 7)
+	for _, e := range []struct {
+		i, j int
+		v    float64
+	}{
+		{0, 0, 1}, {0, 1, 1}, {0, 2, 0}, {0, 4, 1}, {0, 5, -1},
+		{1, 1, 1}, {1, 3, 12},
+		{2, 1, -1}, {2, 3, 12}, {2, 6, 1},
+	} {
+		A.Append(e.i, e.j, e.v)
+	}
+	problem := &lp.Problem{
+		C:        linalg.Vector{5, 4, 5, 0, -3, 5, -5},
+		A:        A.ToCSC(),
+		RowLower: linalg.Vector{-inf, -3, -inf},
+		RowUpper: linalg.Vector{6, -1, 1},
+		Lower:    linalg.Vector{-inf, -inf, -inf, 0, 1, 2, 1},
+		Upper:    linalg.Vector{0, inf, inf, inf, 1, 2, 1},
+	}
+	if sol := lp.NewSimplexSolver().Solve(problem); sol.Status != lp.Infeasible {
+		t.Fatalf("simplex: expected infeasible, got %v", sol.Status)
+	}
+	for _, presolve := range []bool{true, false} {
+		if sol := lp.NewInteriorPointSolver().Solve(problem, lp.WithPresolve(presolve)); sol.Status != lp.Infeasible {
+			t.Errorf("presolve %v: expected infeasible, got %v", presolve, sol.Status)
+		}
+	}
+}
+
+// randomProblem returns a small sparse linear program with a random mix of free, one-sided,
+// ranged and fixed rows and columns, which is often infeasible or unbounded.
+func randomProblem(r *rand.Rand) *lp.Problem {
+	n, m := 2+r.Intn(6), 1+r.Intn(4)
+	inf := math.Inf(1)
+	rows := make([][]float64, m)
+	for i := range rows {
+		rows[i] = make([]float64, n)
+		for j := range rows[i] {
+			if r.Float64() < 0.6 {
+				rows[i][j] = float64(r.Intn(7) - 3)
+			}
+		}
+	}
+	p := &lp.Problem{
+		C:        linalg.NewVector(n),
+		A:        sparseMatrix(rows),
+		RowLower: linalg.NewVector(m),
+		RowUpper: linalg.NewVector(m),
+		Lower:    linalg.NewVector(n),
+		Upper:    linalg.NewVector(n),
+	}
+	for j := 0; j < n; j++ {
+		p.C[j] = float64(r.Intn(11) - 5)
+		l, u := -inf, inf
+		switch r.Intn(5) {
+		case 1:
+			l = float64(r.Intn(5) - 2)
+		case 2:
+			u = float64(r.Intn(5) - 2)
+		case 3:
+			l = float64(r.Intn(5) - 2)
+			u = l + float64(r.Intn(4))
+		case 4:
+			l = 0
+		}
+		p.Lower[j], p.Upper[j] = l, u
+	}
+	for i := 0; i < m; i++ {
+		l, u := -inf, inf
+		switch r.Intn(4) {
+		case 0:
+			u = float64(r.Intn(13) - 6)
+		case 1:
+			l = float64(r.Intn(13) - 6)
+		case 2:
+			l = float64(r.Intn(13) - 6)
+			u = l + float64(r.Intn(4))
+		case 3:
+			l = float64(r.Intn(13) - 6)
+			u = l
+		}
+		p.RowLower[i], p.RowUpper[i] = l, u
+	}
+	return p
+}
+
+func TestInteriorPointRandomStatus(t *testing.T) {
+	counts := map[lp.Status]int{}
+	for seed := int64(0); seed < 400; seed++ {
+		problem := randomProblem(rand.New(rand.NewSource(seed)))
+		simplex := lp.NewSimplexSolver().Solve(problem)
+		counts[simplex.Status]++
+		for _, presolve := range []bool{true, false} {
+			sol := lp.NewInteriorPointSolver().Solve(problem, lp.WithPresolve(presolve))
+			if sol.Status != simplex.Status {
+				t.Errorf("seed %d, presolve %v: expected %v, got %v", seed, presolve, simplex.Status, sol.Status)
+				continue
+			}
+			if sol.Status == lp.Optimal && math.Abs(sol.Objective-simplex.Objective) > 1e-6*(1+math.Abs(simplex.Objective)) {
+				t.Errorf("seed %d, presolve %v: objective %v differs from simplex %v", seed, presolve, sol.Objective, simplex.Objective)
+			}
+		}
+	}
+	if counts[lp.Infeasible] < 50 || counts[lp.Unbounded] < 50 {
+		t.Errorf("expected many infeasible and unbounded problems, got %v", counts)
+	}
+}
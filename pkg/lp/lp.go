@@ -0,0 +1,215 @@
+// Package lp solves linear programs
+//
+//	minimize c^T * x
+//	subject to rowLower <= A * x <= rowUpper, lower <= x <= upper
+//
+// with a bounded-variable revised simplex method or a primal-dual interior point method.
+package lp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// Problem is a linear program with a sparse constraint matrix. A nil bound vector
+// leaves that side of the rows or columns unbounded; equal lower and upper bounds fix
+// a row or column.
+type Problem struct {
+	C        linalg.Vector
+	A        linalg.SparseMatrix
+	RowLower linalg.Vector
+	RowUpper linalg.Vector
+	Lower    linalg.Vector
+	Upper    linalg.Vector
+}
+
+type Status int
+
+const (
+	Optimal Status = iota
+	Infeasible
+	Unbounded
+	IterationLimit
+	NumericalError
+)
+
+func (s Status) String() string {
+	switch s {
+	case Optimal:
+		return "optimal"
+	case Infeasible:
+		return "infeasible"
+	case Unbounded:
+		return "unbounded"
+	case IterationLimit:
+		return "iteration limit"
+	case NumericalError:
+		return "numerical error"
+	}
+	return "unknown"
+}
+
+// BasisStatus is the status of a column or of the slack of a row in a simplex basis.
+type BasisStatus int
+
+const (
+	Basic BasisStatus = iota
+	AtLower
+	AtUpper
+	Free // nonbasic at zero without finite bounds
+)
+
+func (s BasisStatus) String() string {
+	switch s {
+	case Basic:
+		return "basic"
+	case AtLower:
+		return "at lower"
+	case AtUpper:
+		return "at upper"
+	case Free:
+		return "free"
+	}
+	return "unknown"
+}
+
+// Basis is a simplex basis. Exactly one status per row must be Basic across Columns
+// and Rows.
+type Basis struct {
+	Columns []BasisStatus
+	Rows    []BasisStatus
+}
+
+type Pricing int
+
+const (
+	SteepestEdge Pricing = iota
+	Dantzig
+	Bland
+)
+
+// solution is the result of a linear program.
+//
+// The duals satisfy c = A^T * RowDuals + ReducedCosts. If the problem is infeasible,
+// DualRay is a vector y with
+//
+//	max { (A^T * y)^T * x : lower <= x <= upper } < min { y^T * r : rowLower <= r <= rowUpper }
+//
+// and if it is unbounded, Ray is a direction of the feasible set along which the
+// objective decreases without bound.
+type solution struct {
+	Status         Status
+	X              linalg.Vector
+	Objective      float64
+	RowActivities  linalg.Vector
+	RowDuals       linalg.Vector
+	ReducedCosts   linalg.Vector
+	Basis          Basis
+	Iterations     int
+	Phase1         int // simplex iterations spent reaching feasibility
+	Crossover      int // simplex iterations of the crossover from an interior solution
+	DualRay        linalg.Vector
+	Ray            linalg.Vector
+	Infeasibility  float64 // largest row or column bound violation
+	DualInfeasible float64 // largest reduced cost of the wrong sign
+}
+
+// solveOptions are the options for a linear program.
+type solveOptions struct {
+	tolerance     float64
+	maxIterations int // zero selects the default of each solver
+	pricing       Pricing
+	basis         *Basis
+	presolve      bool
+	crossover     bool
+}
+
+func newSolveOptions(options ...func(*solveOptions)) *solveOptions {
+	opts := &solveOptions{
+		tolerance: 1e-8,
+		pricing:   SteepestEdge,
+		presolve:  true,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithTolerance sets the relative tolerance on the residuals and the duality gap of the
+// interior point method.
+func WithTolerance(tolerance float64) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.tolerance = tolerance
+	}
+}
+
+func WithMaxIterations(maxIterations int) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.maxIterations = maxIterations
+	}
+}
+
+// WithPricing sets the rule that chooses the entering variable.
+func WithPricing(pricing Pricing) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.pricing = pricing
+	}
+}
+
+// WithBasis starts the simplex method from a basis, usually that of a previous solve
+// of a similar problem. An invalid or singular basis is replaced by the slack basis.
+func WithBasis(basis Basis) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.basis = &basis
+	}
+}
+
+// WithPresolve enables or disables the presolve of the interior point method.
+func WithPresolve(presolve bool) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.presolve = presolve
+	}
+}
+
+// WithCrossover enables a crossover from the interior point solution to an optimal basic
+// solution with the simplex method.
+func WithCrossover(crossover bool) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.crossover = crossover
+	}
+}
+
+// bounds returns the lower and upper bounds of the columns followed by the rows.
+func (p *Problem) bounds() (lower, upper linalg.Vector) {
+	n := p.C.Len()
+	m := p.A.Rows()
+	if p.A.Cols() != n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	lower = linalg.NewVector(n + m)
+	upper = linalg.NewVector(n + m)
+	fill := func(dst linalg.Vector, src linalg.Vector, value float64) {
+		if src == nil {
+			dst.Set(value)
+			return
+		}
+		if src.Len() != dst.Len() {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		blas.COPY(src, dst)
+	}
+	fill(lower[:n], p.Lower, math.Inf(-1))
+	fill(upper[:n], p.Upper, math.Inf(1))
+	fill(lower[n:], p.RowLower, math.Inf(-1))
+	fill(upper[n:], p.RowUpper, math.Inf(1))
+	return lower, upper
+}
+
+// Objective returns c^T * x.
+func (p *Problem) Objective(x linalg.Vector) float64 {
+	return blas.DOT(p.C, x)
+}
@@ -0,0 +1,273 @@
+package lp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var PRESOLVE_TOLERANCE = 1e-9 // bound violation accepted by the presolve
+
+// singletonRow is a row with one nonzero that was turned into bounds on its column.
+type singletonRow struct {
+	row   int
+	col   int
+	a     float64
+	lower float64 // column bounds before the row was removed
+	upper float64
+}
+
+// presolved is a reduced problem and the information needed to recover a solution of
+// the original problem from a solution of the reduced one.
+type presolved struct {
+	original *Problem
+	reduced  *Problem
+	status   Status // Infeasible or Unbounded if detected by the presolve
+	cols     []int  // original index of each reduced column
+	rows     []int  // original index of each reduced row
+	colKept  []bool
+	rowKept  []bool
+	x        linalg.Vector // values of the removed columns
+	lower    linalg.Vector // column bounds tightened by singleton rows
+	upper    linalg.Vector
+
+	singletons []singletonRow
+}
+
+// presolve removes empty rows and columns, free rows, singleton rows and fixed columns
+// until no more reductions apply.
+func presolve(p *Problem) *presolved {
+	n := p.C.Len()
+	m := p.A.Rows()
+	bounds, ubounds := p.bounds()
+	ps := &presolved{
+		original: p,
+		status:   Optimal,
+		colKept:  make([]bool, n),
+		rowKept:  make([]bool, m),
+		x:        linalg.NewVector(n),
+		lower:    bounds[:n],
+		upper:    ubounds[:n],
+	}
+	rowLower := bounds[n:]
+	rowUpper := ubounds[n:]
+	for j := range ps.colKept {
+		ps.colKept[j] = true
+	}
+	for i := range ps.rowKept {
+		ps.rowKept[i] = true
+	}
+	At := p.A.Transpose()
+
+	for changed := true; changed && ps.status == Optimal; {
+		changed = false
+
+		for i := 0; i < m && ps.status == Optimal; i++ {
+			if !ps.rowKept[i] {
+				continue
+			}
+			cols, values := At.Column(i)
+			count, col, a := 0, -1, 0.0
+			for k, j := range cols {
+				if ps.colKept[j] && values[k] != 0 {
+					count++
+					col, a = j, values[k]
+				}
+			}
+			switch {
+			case math.IsInf(rowLower[i], -1) && math.IsInf(rowUpper[i], 1):
+				// free row
+			case count == 0:
+				if rowLower[i] > PRESOLVE_TOLERANCE || rowUpper[i] < -PRESOLVE_TOLERANCE {
+					ps.status = Infeasible
+				}
+			case count == 1:
+				ps.singletons = append(ps.singletons, singletonRow{
+					row: i, col: col, a: a, lower: ps.lower[col], upper: ps.upper[col],
+				})
+				lo, hi := rowLower[i]/a, rowUpper[i]/a
+				if a < 0 {
+					lo, hi = hi, lo
+				}
+				ps.lower[col] = math.Max(ps.lower[col], lo)
+				ps.upper[col] = math.Min(ps.upper[col], hi)
+				if ps.lower[col] > ps.upper[col]+PRESOLVE_TOLERANCE {
+					ps.status = Infeasible
+				} else if ps.lower[col] > ps.upper[col] {
+					ps.upper[col] = ps.lower[col]
+				}
+			default:
+				continue
+			}
+			ps.rowKept[i] = false
+			changed = true
+		}
+
+		for j := 0; j < n && ps.status == Optimal; j++ {
+			if !ps.colKept[j] {
+				continue
+			}
+			rows, values := p.A.Column(j)
+			count := 0
+			for k, i := range rows {
+				if ps.rowKept[i] && values[k] != 0 {
+					count++
+				}
+			}
+			l, u := ps.lower[j], ps.upper[j]
+			switch {
+			case l == u:
+				ps.x[j] = l
+			case count == 0:
+				// an empty column goes to the bound that minimizes its cost
+				switch c := p.C[j]; {
+				case c > 0 && math.IsInf(l, -1), c < 0 && math.IsInf(u, 1):
+					ps.status = Unbounded
+				case c > 0:
+					ps.x[j] = l
+				case c < 0:
+					ps.x[j] = u
+				case !math.IsInf(l, -1):
+					ps.x[j] = l
+				case !math.IsInf(u, 1):
+					ps.x[j] = u
+				}
+			default:
+				continue
+			}
+			for k, i := range rows {
+				if ps.rowKept[i] {
+					rowLower[i] -= values[k] * ps.x[j]
+					rowUpper[i] -= values[k] * ps.x[j]
+				}
+			}
+			ps.colKept[j] = false
+			changed = true
+		}
+	}
+
+	// build the reduced problem
+	colIndex := make([]int, n)
+	for j := range colIndex {
+		colIndex[j] = -1
+		if ps.colKept[j] {
+			colIndex[j] = len(ps.cols)
+			ps.cols = append(ps.cols, j)
+		}
+	}
+	rowIndex := make([]int, m)
+	for i := range rowIndex {
+		rowIndex[i] = -1
+		if ps.rowKept[i] {
+			rowIndex[i] = len(ps.rows)
+			ps.rows = append(ps.rows, i)
+		}
+	}
+	r := &Problem{
+		C:        linalg.NewVector(len(ps.cols)),
+		RowLower: linalg.NewVector(len(ps.rows)),
+		RowUpper: linalg.NewVector(len(ps.rows)),
+		Lower:    linalg.NewVector(len(ps.cols)),
+		Upper:    linalg.NewVector(len(ps.cols)),
+	}
+	t := linalg.NewTripletMatrix(len(ps.rows), len(ps.cols))
+	for k, j := range ps.cols {
+		r.C[k] = p.C[j]
+		r.Lower[k] = ps.lower[j]
+		r.Upper[k] = ps.upper[j]
+		rows, values := p.A.Column(j)
+		for t2, i := range rows {
+			if rowIndex[i] >= 0 {
+				t.Append(rowIndex[i], k, values[t2])
+			}
+		}
+	}
+	for k, i := range ps.rows {
+		r.RowLower[k] = rowLower[i]
+		r.RowUpper[k] = rowUpper[i]
+	}
+	r.A = t.ToCSC()
+	ps.reduced = r
+	return ps
+}
+
+// postsolve returns the solution of the original problem from that of the reduced one.
+// Only X and RowDuals of the reduced solution are used.
+func (ps *presolved) postsolve(reduced *solution) *solution {
+	p := ps.original
+	n := p.C.Len()
+	m := p.A.Rows()
+	x := linalg.NewVector(n)
+	y := linalg.NewVector(m)
+	for j := range x {
+		x[j] = ps.x[j]
+	}
+	for k, j := range ps.cols {
+		x[j] = reduced.X[k]
+	}
+	for k, i := range ps.rows {
+		y[i] = reduced.RowDuals[k]
+	}
+
+	// a singleton row whose bound is the tightest on its column takes over the reduced
+	// cost of the column, which is nonzero only if that bound is active
+	for k := len(ps.singletons) - 1; k >= 0; k-- {
+		s := ps.singletons[k]
+		d := p.C[s.col]
+		rows, values := p.A.Column(s.col)
+		for t, i := range rows {
+			d -= values[t] * y[i]
+		}
+		if (d > 0 && ps.lower[s.col] != s.lower) || (d < 0 && ps.upper[s.col] != s.upper) {
+			y[s.row] += d / s.a
+		}
+	}
+
+	sol := &solution{
+		Status:     reduced.Status,
+		X:          x,
+		RowDuals:   y,
+		Iterations: reduced.Iterations,
+	}
+	finishSolution(p, sol)
+	return sol
+}
+
+// finishSolution fills the objective, row activities, reduced costs and residuals of a
+// solution from its primal values and row duals.
+func finishSolution(p *Problem, sol *solution) {
+	n := p.C.Len()
+	m := p.A.Rows()
+	lower, upper := p.bounds()
+	sol.Objective = p.Objective(sol.X)
+	sol.RowActivities = linalg.NewVector(m)
+	sol.ReducedCosts = linalg.NewVector(n)
+	sol.Infeasibility = 0
+	sol.DualInfeasible = 0
+	for j := 0; j < n; j++ {
+		d := p.C[j]
+		rows, values := p.A.Column(j)
+		for k, i := range rows {
+			sol.RowActivities[i] += values[k] * sol.X[j]
+			d -= values[k] * sol.RowDuals[i]
+		}
+		sol.ReducedCosts[j] = d
+	}
+	for j := 0; j < n+m; j++ {
+		v, d := 0.0, 0.0
+		if j < n {
+			v, d = sol.X[j], sol.ReducedCosts[j]
+		} else {
+			v, d = sol.RowActivities[j-n], sol.RowDuals[j-n]
+		}
+		sol.Infeasibility = math.Max(sol.Infeasibility, math.Max(lower[j]-v, v-upper[j]))
+
+		// a positive reduced cost needs a lower bound, a negative one an upper bound
+		if math.IsInf(lower[j], -1) {
+			sol.DualInfeasible = math.Max(sol.DualInfeasible, d)
+		}
+		if math.IsInf(upper[j], 1) {
+			sol.DualInfeasible = math.Max(sol.DualInfeasible, -d)
+		}
+	}
+}
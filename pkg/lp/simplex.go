@@ -0,0 +1,592 @@
+package lp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var SIMPLEX_FEASIBILITY_TOLERANCE = 1e-9 // primal bound violation accepted as feasible
+var SIMPLEX_OPTIMALITY_TOLERANCE = 1e-9  // reduced cost accepted as optimal
+var SIMPLEX_PIVOT_TOLERANCE = 1e-9       // smallest pivot accepted by the ratio test
+var SIMPLEX_MAX_ITERATIONS = 100000
+
+// simplexSolver is the bounded-variable primal revised simplex method. Each row i gets
+// a slack s_i = A_i * x with the row bounds, so the constraints become [A -I] * v = 0
+// with simple bounds on every variable and the slack basis is always a valid start.
+//
+// Phase 1 minimizes the sum of the bound violations of the basic variables and phase 2
+// the objective. The ratio test is the two-pass test of Harris, which trades tiny bound
+// violations for larger pivots.
+type simplexSolver struct{}
+
+// simplex is the state of the simplex method on the variables v = (x, s).
+type simplex struct {
+	A      linalg.SparseMatrix
+	c      linalg.Vector
+	n      int
+	m      int
+	lower  linalg.Vector
+	upper  linalg.Vector
+	x      linalg.Vector
+	head   []int // head[k] is the variable at position k of the basis
+	status []BasisStatus
+	factor *basisFactor
+
+	weights linalg.Vector // steepest edge weights of the nonbasic variables
+}
+
+func (s *simplexSolver) Solve(p *Problem, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	return s.solve(p, opts)
+}
+
+func (s *simplexSolver) solve(p *Problem, opts *solveOptions) *solution {
+	lower, upper := p.bounds()
+	n := p.C.Len()
+	m := p.A.Rows()
+	sx := &simplex{
+		A:       p.A,
+		c:       p.C,
+		n:       n,
+		m:       m,
+		lower:   lower,
+		upper:   upper,
+		x:       linalg.NewVector(n + m),
+		head:    make([]int, m),
+		status:  make([]BasisStatus, n+m),
+		weights: linalg.NewVector(n + m),
+	}
+
+	if opts.basis == nil || !sx.setBasis(opts.basis) || sx.refactor() != nil {
+		sx.setSlackBasis()
+		if sx.refactor() != nil {
+			return &solution{Status: NumericalError}
+		}
+	}
+	if opts.pricing == SteepestEdge {
+		sx.initWeights()
+	}
+	return sx.run(p, opts)
+}
+
+// setBasis sets the basis from the given statuses. Returns false if it does not have
+// exactly one basic variable per row.
+func (sx *simplex) setBasis(basis *Basis) bool {
+	if len(basis.Columns) != sx.n || len(basis.Rows) != sx.m {
+		return false
+	}
+	k := 0
+	for j := 0; j < sx.n+sx.m; j++ {
+		var st BasisStatus
+		if j < sx.n {
+			st = basis.Columns[j]
+		} else {
+			st = basis.Rows[j-sx.n]
+		}
+		if st == Basic {
+			if k == sx.m {
+				return false
+			}
+			sx.head[k] = j
+			k++
+		}
+		sx.status[j] = st
+	}
+	if k != sx.m {
+		return false
+	}
+	for j := range sx.status {
+		if sx.status[j] != Basic {
+			sx.setNonbasic(j, sx.status[j])
+		}
+	}
+	return true
+}
+
+func (sx *simplex) setSlackBasis() {
+	for j := 0; j < sx.n; j++ {
+		sx.setNonbasic(j, AtLower)
+	}
+	for i := 0; i < sx.m; i++ {
+		sx.head[i] = sx.n + i
+		sx.status[sx.n+i] = Basic
+	}
+}
+
+// setNonbasic places the nonbasic variable j at the requested bound, or at another
+// bound if that one is infinite.
+func (sx *simplex) setNonbasic(j int, st BasisStatus) {
+	l, u := sx.lower[j], sx.upper[j]
+	switch {
+	case st == AtUpper && !math.IsInf(u, 1):
+		sx.status[j], sx.x[j] = AtUpper, u
+	case !math.IsInf(l, -1):
+		sx.status[j], sx.x[j] = AtLower, l
+	case !math.IsInf(u, 1):
+		sx.status[j], sx.x[j] = AtUpper, u
+	default:
+		sx.status[j], sx.x[j] = Free, 0
+	}
+}
+
+// refactor factors the basis and recomputes the basic variables from the nonbasic ones.
+func (sx *simplex) refactor() error {
+	f, err := factorBasis(sx.A, sx.head)
+	if err != nil {
+		return err
+	}
+	sx.factor = f
+
+	// B * xB = -N * xN
+	rhs := linalg.NewVector(sx.m)
+	for j := range sx.status {
+		if sx.status[j] != Basic && sx.x[j] != 0 {
+			sx.addColumn(j, -sx.x[j], rhs)
+		}
+	}
+	f.ftran(rhs)
+	for k, j := range sx.head {
+		sx.x[j] = rhs[k]
+	}
+	return nil
+}
+
+// addColumn adds alpha times the column of variable j to v.
+func (sx *simplex) addColumn(j int, alpha float64, v linalg.Vector) {
+	if j >= sx.n {
+		v[j-sx.n] -= alpha
+		return
+	}
+	rows, values := sx.A.Column(j)
+	for k, i := range rows {
+		v[i] += alpha * values[k]
+	}
+}
+
+// dotColumn returns y^T times the column of variable j.
+func (sx *simplex) dotColumn(j int, y linalg.Vector) float64 {
+	if j >= sx.n {
+		return -y[j-sx.n]
+	}
+	rows, values := sx.A.Column(j)
+	sum := 0.0
+	for k, i := range rows {
+		sum += values[k] * y[i]
+	}
+	return sum
+}
+
+// initWeights sets the steepest edge weights 1 + |B^-1 * a_j|^2 of the nonbasic variables.
+func (sx *simplex) initWeights() {
+	col := linalg.NewVector(sx.m)
+	for j := range sx.status {
+		if sx.status[j] == Basic {
+			continue
+		}
+		col.Zero()
+		sx.addColumn(j, 1, col)
+		sx.factor.ftran(col)
+		sx.weights[j] = 1 + blas.DOT(col, col)
+	}
+}
+
+// cost returns the phase 1 or phase 2 cost of the basic variable at position k.
+func (sx *simplex) cost(k int, phase1 bool) float64 {
+	j := sx.head[k]
+	if phase1 {
+		switch {
+		case sx.x[j] < sx.lower[j]-SIMPLEX_FEASIBILITY_TOLERANCE:
+			return -1
+		case sx.x[j] > sx.upper[j]+SIMPLEX_FEASIBILITY_TOLERANCE:
+			return 1
+		}
+		return 0
+	}
+	if j < sx.n {
+		return sx.c[j]
+	}
+	return 0
+}
+
+// infeasibility returns the sum of the bound violations of the basic variables.
+func (sx *simplex) infeasibility() float64 {
+	sum := 0.0
+	for _, j := range sx.head {
+		if v := sx.lower[j] - sx.x[j]; v > SIMPLEX_FEASIBILITY_TOLERANCE {
+			sum += v
+		}
+		if v := sx.x[j] - sx.upper[j]; v > SIMPLEX_FEASIBILITY_TOLERANCE {
+			sum += v
+		}
+	}
+	return sum
+}
+
+// reducedCost returns the reduced cost of variable j for the duals y.
+func (sx *simplex) reducedCost(j int, y linalg.Vector, phase1 bool) float64 {
+	d := -sx.dotColumn(j, y)
+	if !phase1 && j < sx.n {
+		d += sx.c[j]
+	}
+	return d
+}
+
+// price returns the entering variable and its direction of change, or -1 if the
+// reduced costs are optimal.
+func (sx *simplex) price(y linalg.Vector, phase1 bool, pricing Pricing) (int, float64) {
+	entering := -1
+	dir := 0.0
+	best := 0.0
+	for j, st := range sx.status {
+		if st == Basic || sx.lower[j] == sx.upper[j] {
+			continue
+		}
+		d := sx.reducedCost(j, y, phase1)
+		var dj float64
+		switch {
+		case d < -SIMPLEX_OPTIMALITY_TOLERANCE && st != AtUpper:
+			dj = 1
+		case d > SIMPLEX_OPTIMALITY_TOLERANCE && st != AtLower:
+			dj = -1
+		default:
+			continue
+		}
+		var score float64
+		switch pricing {
+		case Bland:
+			return j, dj
+		case Dantzig:
+			score = math.Abs(d)
+		case SteepestEdge:
+			score = d * d / sx.weights[j]
+		}
+		if score > best {
+			entering, dir, best = j, dj, score
+		}
+	}
+	return entering, dir
+}
+
+// ratioTest returns the basis position of the leaving variable, the bound it reaches and
+// the step length for the entering variable q moving in direction dir with basic rates
+// -dir * alpha. The position is -1 for a bound flip of q and -2 if the step is unbounded.
+func (sx *simplex) ratioTest(q int, dir float64, alpha linalg.Vector, phase1 bool, harris bool) (int, float64, float64) {
+	tol := SIMPLEX_FEASIBILITY_TOLERANCE
+	relax := tol
+	if !harris {
+		relax = 0
+	}
+
+	// target returns the bound reached by the basic variable at position k.
+	target := func(k int, rate float64) float64 {
+		j := sx.head[k]
+		xj, l, u := sx.x[j], sx.lower[j], sx.upper[j]
+		if rate < 0 {
+			if phase1 && xj > u+tol {
+				return u
+			}
+			if xj < l-tol {
+				return math.Inf(-1)
+			}
+			return l
+		}
+		if phase1 && xj < l-tol {
+			return l
+		}
+		if xj > u+tol {
+			return math.Inf(1)
+		}
+		return u
+	}
+
+	// pass 1: the largest step with the bounds relaxed by the tolerance
+	thetaMax := math.Inf(1)
+	for k := range sx.head {
+		rate := -dir * alpha[k]
+		if math.Abs(rate) < SIMPLEX_PIVOT_TOLERANCE {
+			continue
+		}
+		t := target(k, rate)
+		if math.IsInf(t, 0) {
+			continue
+		}
+		relaxed := t + relax
+		if rate < 0 {
+			relaxed = t - relax
+		}
+		thetaMax = math.Min(thetaMax, (relaxed-sx.x[sx.head[k]])/rate)
+	}
+	// a basic variable just beyond its bound would give a negative step, which pass 2
+	// clamps to zero
+	thetaMax = math.Max(thetaMax, 0)
+
+	flip := math.Inf(1)
+	if dir > 0 {
+		flip = sx.upper[q] - sx.x[q]
+	} else {
+		flip = sx.x[q] - sx.lower[q]
+	}
+	if flip <= thetaMax {
+		if math.IsInf(flip, 1) {
+			return -2, 0, 0
+		}
+		bound := sx.upper[q]
+		if dir < 0 {
+			bound = sx.lower[q]
+		}
+		return -1, bound, flip
+	}
+
+	// pass 2: the largest pivot among the steps within the relaxed bound
+	leaving := -1
+	var bound, theta, pivot float64
+	for k := range sx.head {
+		rate := -dir * alpha[k]
+		if math.Abs(rate) < SIMPLEX_PIVOT_TOLERANCE {
+			continue
+		}
+		t := target(k, rate)
+		if math.IsInf(t, 0) {
+			continue
+		}
+		ratio := math.Max((t-sx.x[sx.head[k]])/rate, 0)
+		if ratio > thetaMax {
+			continue
+		}
+		better := math.Abs(rate) > pivot
+		if !harris {
+			// smallest ratio, ties to the smallest variable index
+			better = leaving < 0 || ratio < theta || (ratio == theta && sx.head[k] < sx.head[leaving])
+		}
+		if better {
+			leaving, bound, theta, pivot = k, t, ratio, math.Abs(rate)
+		}
+	}
+	if leaving < 0 {
+		return -2, 0, 0
+	}
+	return leaving, bound, theta
+}
+
+// updateWeights applies the Goldfarb-Reid update of the steepest edge weights for q
+// entering the basis at position p, before the basis is changed.
+func (sx *simplex) updateWeights(q, p int, alpha linalg.Vector) {
+	rho := linalg.NewVector(sx.m)
+	rho[p] = 1
+	sx.factor.btran(rho)
+	w := linalg.NewVector(sx.m)
+	blas.COPY(alpha, w)
+	sx.factor.btran(w)
+
+	gammaQ := 1 + blas.DOT(alpha, alpha)
+	ap := alpha[p]
+	for j, st := range sx.status {
+		if st == Basic || j == q {
+			continue
+		}
+		apj := sx.dotColumn(j, rho)
+		if apj == 0 {
+			continue
+		}
+		r := apj / ap
+		sx.weights[j] = math.Max(sx.weights[j]-2*r*sx.dotColumn(j, w)+r*r*gammaQ, 1+r*r)
+	}
+	sx.weights[sx.head[p]] = math.Max(gammaQ/(ap*ap), 1)
+}
+
+func (sx *simplex) run(p *Problem, opts *solveOptions) *solution {
+	n, m := sx.n, sx.m
+	y := linalg.NewVector(m)
+	alpha := linalg.NewVector(m)
+	harris := opts.pricing != Bland
+
+	maxIterations := opts.maxIterations
+	if maxIterations == 0 {
+		maxIterations = SIMPLEX_MAX_ITERATIONS
+	}
+
+	sol := &solution{Status: IterationLimit}
+	iter := 0
+	phase1Iterations := 0
+	phase1 := false
+	for {
+		phase1 = sx.infeasibility() > 0
+
+		// duals y = B^-T * cB
+		for k := range sx.head {
+			y[k] = sx.cost(k, phase1)
+		}
+		sx.factor.btran(y)
+
+		q, dir := sx.price(y, phase1, opts.pricing)
+		if q < 0 {
+			if phase1 {
+				sol.Status = Infeasible
+				sol.DualRay = sx.farkas(y)
+			} else {
+				sol.Status = Optimal
+			}
+			break
+		}
+		if iter >= maxIterations {
+			break
+		}
+		iter++
+		if phase1 {
+			phase1Iterations++
+		}
+
+		alpha.Zero()
+		sx.addColumn(q, 1, alpha)
+		sx.factor.ftran(alpha)
+
+		k, bound, theta := sx.ratioTest(q, dir, alpha, phase1, harris)
+		if k == -2 {
+			if phase1 {
+				sol.Status = NumericalError
+			} else {
+				sol.Status = Unbounded
+				sol.Ray = linalg.NewVector(n)
+				if q < n {
+					sol.Ray[q] = dir
+				}
+				for i, j := range sx.head {
+					if j < n {
+						sol.Ray[j] = -dir * alpha[i]
+					}
+				}
+			}
+			break
+		}
+
+		for i, j := range sx.head {
+			sx.x[j] -= dir * theta * alpha[i]
+		}
+		sx.x[q] += dir * theta
+		if k == -1 {
+			// bound flip of the entering variable
+			sx.x[q] = bound
+			if dir > 0 {
+				sx.status[q] = AtUpper
+			} else {
+				sx.status[q] = AtLower
+			}
+			continue
+		}
+
+		if opts.pricing == SteepestEdge {
+			sx.updateWeights(q, k, alpha)
+		}
+		leaving := sx.head[k]
+		sx.x[leaving] = bound
+		if bound == sx.lower[leaving] {
+			sx.status[leaving] = AtLower
+		} else {
+			sx.status[leaving] = AtUpper
+		}
+		sx.head[k] = q
+		sx.status[q] = Basic
+		sx.factor.update(k, alpha)
+
+		if sx.factor.updates() >= SIMPLEX_REFACTOR_INTERVAL {
+			if sx.refactor() != nil {
+				sol.Status = NumericalError
+				break
+			}
+		}
+	}
+
+	if sol.Status == Optimal && sx.factor.updates() > 0 {
+		// recompute the solution from a fresh factorization
+		if sx.refactor() == nil {
+			for k := range sx.head {
+				y[k] = sx.cost(k, false)
+			}
+			sx.factor.btran(y)
+		}
+	}
+	return sx.finish(p, sol, y, iter, phase1Iterations, phase1)
+}
+
+// farkas orients the phase 1 duals y so that they prove infeasibility, if they do.
+func (sx *simplex) farkas(y linalg.Vector) linalg.Vector {
+	ray := linalg.NewVector(sx.m)
+	blas.COPY(y, ray)
+	if sx.farkasGap(ray) >= 0 {
+		blas.SCAL(-1, ray)
+	}
+	return ray
+}
+
+// farkasGap returns max { (A^T * y)^T * x } - min { y^T * r } over the column and row
+// bounds, which is negative for a certificate of infeasibility.
+func (sx *simplex) farkasGap(y linalg.Vector) float64 {
+	gap := 0.0
+	for j := 0; j < sx.n+sx.m; j++ {
+		// the row terms enter through the slack columns -e_i
+		a := sx.dotColumn(j, y)
+		switch {
+		case a > 0:
+			gap += a * sx.upper[j]
+		case a < 0:
+			gap += a * sx.lower[j]
+		}
+	}
+	return gap
+}
+
+func (sx *simplex) finish(p *Problem, sol *solution, y linalg.Vector, iter, phase1Iterations int, phase1 bool) *solution {
+	n, m := sx.n, sx.m
+	sol.Iterations = iter
+	sol.Phase1 = phase1Iterations
+	sol.X = linalg.NewVector(n)
+	blas.COPY(sx.x[:n], sol.X)
+	sol.Objective = p.Objective(sol.X)
+	sol.RowActivities = linalg.NewVector(m)
+	blas.SPMV(1.0, sx.A, sol.X, 0.0, sol.RowActivities)
+
+	sol.RowDuals = linalg.NewVector(m)
+	sol.ReducedCosts = linalg.NewVector(n)
+	if !phase1 {
+		blas.COPY(y, sol.RowDuals)
+		for j := 0; j < n; j++ {
+			sol.ReducedCosts[j] = sx.reducedCost(j, y, false)
+		}
+		for j, st := range sx.status {
+			d := sx.reducedCost(j, y, false)
+			switch st {
+			case AtLower:
+				d = math.Max(-d, 0)
+			case AtUpper:
+				d = math.Max(d, 0)
+			case Free:
+				d = math.Abs(d)
+			default:
+				d = 0
+			}
+			if sx.lower[j] == sx.upper[j] {
+				d = 0
+			}
+			sol.DualInfeasible = math.Max(sol.DualInfeasible, d)
+		}
+	}
+
+	for j := range sx.status {
+		sol.Infeasibility = math.Max(sol.Infeasibility, math.Max(sx.lower[j]-sx.x[j], sx.x[j]-sx.upper[j]))
+	}
+	sol.Basis = Basis{
+		Columns: make([]BasisStatus, n),
+		Rows:    make([]BasisStatus, m),
+	}
+	copy(sol.Basis.Columns, sx.status[:n])
+	copy(sol.Basis.Rows, sx.status[n:])
+	return sol
+}
+
+// NewSimplexSolver returns a bounded-variable revised simplex solver. The returned
+// basis can be passed to WithBasis to warm start a later solve.
+func NewSimplexSolver() *simplexSolver {
+	return &simplexSolver{}
+}
@@ -0,0 +1,223 @@
+package lp_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+)
+
+func sparseMatrix(rows [][]float64) linalg.SparseMatrix {
+	t := linalg.NewTripletMatrix(len(rows), len(rows[0]))
+	for i := range rows {
+		for j, v := range rows[i] {
+			if v != 0 {
+				t.Append(i, j, v)
+			}
+		}
+	}
+	return t.ToCSC()
+}
+
+var pricings = []lp.Pricing{lp.SteepestEdge, lp.Dantzig, lp.Bland}
+
+func TestSimplexTextbook(t *testing.T) {
+	// maximize 3x + 5y subject to x <= 4, 2y <= 12, 3x + 2y <= 18, x, y >= 0
+	inf := math.Inf(1)
+	problem := &lp.Problem{
+		C:        linalg.Vector{-3, -5},
+		A:        sparseMatrix([][]float64{{1, 0}, {0, 2}, {3, 2}}),
+		RowUpper: linalg.Vector{4, 12, 18},
+		Lower:    linalg.Vector{0, 0},
+		Upper:    linalg.Vector{inf, inf},
+	}
+	for _, pricing := range pricings {
+		sol := lp.NewSimplexSolver().Solve(problem, lp.WithPricing(pricing))
+		if sol.Status != lp.Optimal {
+			t.Fatalf("pricing %d: expected optimal, got %v", pricing, sol.Status)
+		}
+		if math.Abs(sol.X[0]-2) > 1e-9 || math.Abs(sol.X[1]-6) > 1e-9 || math.Abs(sol.Objective+36) > 1e-9 {
+			t.Errorf("pricing %d: expected (2, 6) with objective -36, got %v and %v", pricing, sol.X, sol.Objective)
+		}
+		expected := linalg.Vector{0, -1.5, -1}
+		for i := range expected {
+			if math.Abs(sol.RowDuals[i]-expected[i]) > 1e-9 {
+				t.Errorf("pricing %d: expected duals %v, got %v", pricing, expected, sol.RowDuals)
+			}
+		}
+		if sol.Basis.Rows[0] != lp.Basic || sol.Basis.Rows[1] != lp.AtUpper || sol.Basis.Rows[2] != lp.AtUpper {
+			t.Errorf("pricing %d: unexpected row basis %v", pricing, sol.Basis.Rows)
+		}
+	}
+}
+
+// rangedProblem has an equality row, a ranged row, upper bounds and a free variable.
+func rangedProblem(rangeLower float64) *lp.Problem {
+	inf := math.Inf(1)
+	return &lp.Problem{
+		C: linalg.Vector{1, 2, 3, 0},
+		A: sparseMatrix([][]float64{
+			{1, 1, 1, 0},
+			{0, 1, -1, 0},
+			{-1, 0, 0, 1},
+		}),
+		RowLower: linalg.Vector{10, rangeLower, 0.5},
+		RowUpper: linalg.Vector{10, 3, 0.5},
+		Lower:    linalg.Vector{0, 0, 0, -inf},
+		Upper:    linalg.Vector{3, 4, inf, inf},
+	}
+}
+
+func TestSimplexRangedAndFree(t *testing.T) {
+	problem := rangedProblem(-3)
+	expected := linalg.Vector{3, 4, 3, 3.5}
+	for _, pricing := range pricings {
+		sol := lp.NewSimplexSolver().Solve(problem, lp.WithPricing(pricing))
+		if sol.Status != lp.Optimal {
+			t.Fatalf("pricing %d: expected optimal, got %v", pricing, sol.Status)
+		}
+		for j := range expected {
+			if math.Abs(sol.X[j]-expected[j]) > 1e-9 {
+				t.Fatalf("pricing %d: expected %v, got %v", pricing, expected, sol.X)
+			}
+		}
+		if sol.Infeasibility > 1e-9 || sol.DualInfeasible > 1e-9 {
+			t.Errorf("pricing %d: infeasibility %v, dual infeasibility %v", pricing, sol.Infeasibility, sol.DualInfeasible)
+		}
+		// c = A^T * y + d
+		for j := range problem.C {
+			sum := sol.ReducedCosts[j]
+			for i := range sol.RowDuals {
+				sum += problem.A.Get(i, j) * sol.RowDuals[i]
+			}
+			if math.Abs(sum-problem.C[j]) > 1e-9 {
+				t.Errorf("pricing %d: dual residual %v in column %d", pricing, sum-problem.C[j], j)
+			}
+		}
+	}
+}
+
+func TestSimplexInfeasible(t *testing.T) {
+	// x2 - x3 >= 2 with x2 <= 4 forces x3 <= 2, but x1 <= 3 forces x2 + x3 >= 7
+	problem := rangedProblem(2)
+	sol := lp.NewSimplexSolver().Solve(problem)
+	if sol.Status != lp.Infeasible {
+		t.Fatalf("expected infeasible, got %v", sol.Status)
+	}
+
+	// max over the column bounds of (A^T y)^T x < min over the row bounds of y^T r
+	y := sol.DualRay
+	gap := 0.0
+	for j := range problem.C {
+		a := 0.0
+		for i := range y {
+			a += problem.A.Get(i, j) * y[i]
+		}
+		if a > 0 {
+			gap += a * problem.Upper[j]
+		} else if a < 0 {
+			gap += a * problem.Lower[j]
+		}
+	}
+	for i := range y {
+		if y[i] > 0 {
+			gap -= y[i] * problem.RowLower[i]
+		} else if y[i] < 0 {
+			gap -= y[i] * problem.RowUpper[i]
+		}
+	}
+	if !(gap < 0) {
+		t.Errorf("invalid certificate %v with gap %v", y, gap)
+	}
+}
+
+func TestSimplexUnbounded(t *testing.T) {
+	// minimize -x1 subject to x1 - x2 <= 1, x >= 0
+	problem := &lp.Problem{
+		C:        linalg.Vector{-1, 0},
+		A:        sparseMatrix([][]float64{{1, -1}}),
+		RowUpper: linalg.Vector{1},
+		Lower:    linalg.Vector{0, 0},
+	}
+	sol := lp.NewSimplexSolver().Solve(problem)
+	if sol.Status != lp.Unbounded {
+		t.Fatalf("expected unbounded, got %v", sol.Status)
+	}
+	r := sol.Ray
+	if r[0]*problem.C[0]+r[1]*problem.C[1] >= 0 || r[0]-r[1] > 1e-12 || r[0] < 0 || r[1] < 0 {
+		t.Errorf("invalid ray %v", r)
+	}
+}
+
+// packingProblem returns a dense packing problem with n columns and m rows.
+func packingProblem(n, m int, shift float64) *lp.Problem {
+	rows := make([][]float64, m)
+	b := linalg.NewVector(m)
+	for i := range rows {
+		rows[i] = make([]float64, n)
+		for j := range rows[i] {
+			rows[i][j] = 1 + math.Abs(math.Sin(float64(3*i+7*j)))
+		}
+		b[i] = 10 + float64(i%3)
+	}
+	c := linalg.NewVector(n)
+	upper := linalg.NewVector(n)
+	for j := range c {
+		c[j] = -1 - math.Abs(math.Cos(float64(j))) - shift*float64(j%2)
+		upper[j] = 2
+	}
+	return &lp.Problem{
+		C:        c,
+		A:        sparseMatrix(rows),
+		RowUpper: b,
+		Lower:    linalg.NewVector(n),
+		Upper:    upper,
+	}
+}
+
+func TestSimplexWarmStart(t *testing.T) {
+	solver := lp.NewSimplexSolver()
+	first := solver.Solve(packingProblem(30, 20, 0))
+	if first.Status != lp.Optimal {
+		t.Fatalf("expected optimal, got %v", first.Status)
+	}
+	for _, pricing := range pricings[1:] {
+		other := solver.Solve(packingProblem(30, 20, 0), lp.WithPricing(pricing))
+		if math.Abs(other.Objective-first.Objective) > 1e-9 {
+			t.Errorf("pricing %d: objective %v differs from %v", pricing, other.Objective, first.Objective)
+		}
+	}
+
+	perturbed := packingProblem(30, 20, 0.05)
+	cold := solver.Solve(perturbed)
+	warm := solver.Solve(perturbed, lp.WithBasis(first.Basis))
+	if cold.Status != lp.Optimal || warm.Status != lp.Optimal {
+		t.Fatalf("expected optimal, got %v and %v", cold.Status, warm.Status)
+	}
+	if math.Abs(cold.Objective-warm.Objective) > 1e-9 {
+		t.Errorf("warm objective %v differs from cold %v", warm.Objective, cold.Objective)
+	}
+	if warm.Iterations >= cold.Iterations {
+		t.Errorf("warm start took %d iterations, cold start %d", warm.Iterations, cold.Iterations)
+	}
+}
+
+func TestSimplexBlandDegenerate(t *testing.T) {
+	// at the lower bounds the row activity 0.1 + 0.2 rounds to just above its upper bound
+	// 0.3, so the first pivot is degenerate with a slightly negative ratio
+	problem := &lp.Problem{
+		C:        linalg.Vector{-1, -1},
+		A:        sparseMatrix([][]float64{{1, 1}}),
+		RowUpper: linalg.Vector{0.3},
+		Lower:    linalg.Vector{0.1, 0.2},
+		Upper:    linalg.Vector{1, 1},
+	}
+	sol := lp.NewSimplexSolver().Solve(problem, lp.WithPricing(lp.Bland), lp.WithPresolve(false))
+	if sol.Status != lp.Optimal {
+		t.Fatalf("expected optimal, got %v", sol.Status)
+	}
+	if math.Abs(sol.Objective+0.3) > 1e-12 {
+		t.Errorf("expected objective -0.3, got %v", sol.Objective)
+	}
+}
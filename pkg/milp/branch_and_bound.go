@@ -0,0 +1,297 @@
+package milp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+)
+
+var MILP_MAX_NODES = 100000
+var MILP_GOMORY_ROUNDS = 5
+var MILP_HEURISTIC_FREQUENCY = 10 // nodes between calls of the rounding heuristic
+
+// branchAndBoundSolver is LP-based branch-and-bound. The root relaxation is strengthened
+// by rounds of Gomory mixed-integer cuts, and each node then solves its relaxation with
+// the simplex method warm started from the basis of its parent. A node is pruned when
+// its relaxation is infeasible or its bound is within the gap of the incumbent.
+type branchAndBoundSolver struct{}
+
+// node is an open node of the search tree, with the column bounds of its subproblem.
+type node struct {
+	lower    linalg.Vector
+	upper    linalg.Vector
+	bound    float64 // relaxation objective of the parent
+	depth    int
+	basis    lp.Basis
+	variable int     // branching variable, or -1 at the root
+	up       bool    // the node raised the lower bound of variable
+	distance float64 // distance from the parent's value of variable to the new bound
+}
+
+// pseudocosts are the average objective increases per unit change of each variable,
+// observed when branching down (0) and up (1).
+type pseudocosts struct {
+	sum   [2]linalg.Vector
+	count [2][]int
+}
+
+func newPseudocosts(n int) *pseudocosts {
+	return &pseudocosts{
+		sum:   [2]linalg.Vector{linalg.NewVector(n), linalg.NewVector(n)},
+		count: [2][]int{make([]int, n), make([]int, n)},
+	}
+}
+
+func (pc *pseudocosts) update(j int, up bool, gain float64) {
+	d := 0
+	if up {
+		d = 1
+	}
+	pc.sum[d][j] += gain
+	pc.count[d][j]++
+}
+
+// estimate returns the pseudocost of j in direction d, or the average over the
+// initialized variables if j has not been branched on in that direction.
+func (pc *pseudocosts) estimate(j, d int) float64 {
+	if pc.count[d][j] > 0 {
+		return pc.sum[d][j] / float64(pc.count[d][j])
+	}
+	total, count := 0.0, 0
+	for k, c := range pc.count[d] {
+		if c > 0 {
+			total += pc.sum[d][k] / float64(c)
+			count++
+		}
+	}
+	if count == 0 {
+		return 1
+	}
+	return total / float64(count)
+}
+
+func (s *branchAndBoundSolver) Solve(p *Problem, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	return s.solve(p, opts)
+}
+
+func (s *branchAndBoundSolver) solve(p *Problem, opts *solveOptions) *solution {
+	n := p.C.Len()
+	lower, upper := p.columnBounds()
+	sol := &solution{
+		Status:    Infeasible,
+		Objective: math.Inf(1),
+		Bound:     math.Inf(1),
+		Gap:       math.Inf(1),
+	}
+	for j := 0; j < n; j++ {
+		if lower[j] > upper[j] {
+			return sol
+		}
+	}
+	work := p.withCuts(nil)
+	simplex := lp.NewSimplexSolver()
+
+	// root relaxation and cuts
+	root := simplex.Solve(work.relaxation(lower, upper))
+	sol.LPIterations += root.Iterations
+	switch root.Status {
+	case lp.Optimal:
+	case lp.Infeasible:
+		return sol
+	case lp.Unbounded:
+		sol.Status = Unbounded
+		sol.Bound = math.Inf(-1)
+		return sol
+	default:
+		sol.Status = NumericalError
+		return sol
+	}
+	for round := 0; round < opts.cutRounds; round++ {
+		cuts := gomoryCuts(work, lower, upper, root.X, root.RowActivities, root.Basis)
+		if len(cuts) == 0 {
+			break
+		}
+		cutWork := work.withCuts(cuts)
+		basis := root.Basis
+		basis.Rows = append(append([]lp.BasisStatus(nil), basis.Rows...), make([]lp.BasisStatus, len(cuts))...)
+		next := simplex.Solve(cutWork.relaxation(lower, upper), lp.WithBasis(basis))
+		sol.LPIterations += next.Iterations
+		if next.Status != lp.Optimal {
+			break
+		}
+		improvement := next.Objective - root.Objective
+		work, root = cutWork, next
+		sol.Cuts += len(cuts)
+		if improvement <= 1e-6*(1+math.Abs(root.Objective)) {
+			break
+		}
+	}
+
+	// incumbent records an integer solution if it improves on the incumbent
+	incumbent := func(x linalg.Vector, objective, bound float64, heuristic bool) {
+		if objective >= sol.Objective {
+			return
+		}
+		sol.X = linalg.NewVector(n)
+		for j := range x {
+			sol.X[j] = x[j]
+			if p.isInteger(j) {
+				sol.X[j] = math.Round(x[j])
+			}
+		}
+		sol.Objective = p.Objective(sol.X)
+		sol.Incumbents = append(sol.Incumbents, Incumbent{
+			Objective: sol.Objective,
+			Bound:     bound,
+			Node:      sol.Nodes,
+			Heuristic: heuristic,
+		})
+	}
+	cutoff := func() float64 {
+		if sol.X == nil {
+			return math.Inf(1)
+		}
+		return sol.Objective - opts.gap*math.Max(1, math.Abs(sol.Objective))
+	}
+
+	pc := newPseudocosts(n)
+	open := []*node{{lower: lower, upper: upper, bound: root.Objective, basis: root.Basis, variable: -1}}
+	pruned := math.Inf(1) // lowest bound of the nodes pruned against the incumbent
+	failed := false
+	sol.Status = Optimal
+	for len(open) > 0 {
+		bound := pruned
+		for _, nd := range open {
+			bound = math.Min(bound, nd.bound)
+		}
+		sol.Bound = math.Min(bound, sol.Objective)
+		if sol.Bound >= cutoff() {
+			break
+		}
+		if sol.Nodes >= opts.maxNodes {
+			sol.Status = NodeLimit
+			break
+		}
+
+		k := selectNode(open, opts.nodeSelection, sol.X != nil)
+		nd := open[k]
+		open = append(open[:k], open[k+1:]...)
+		if nd.bound >= cutoff() {
+			pruned = math.Min(pruned, nd.bound)
+			continue
+		}
+
+		sol.Nodes++
+		r := simplex.Solve(work.relaxation(nd.lower, nd.upper), lp.WithBasis(nd.basis))
+		sol.LPIterations += r.Iterations
+		if r.Status == lp.Infeasible {
+			continue
+		}
+		if r.Status != lp.Optimal {
+			failed = true
+			continue
+		}
+		if nd.variable >= 0 {
+			pc.update(nd.variable, nd.up, math.Max(r.Objective-nd.bound, 0)/nd.distance)
+		}
+		if r.Objective >= cutoff() {
+			pruned = math.Min(pruned, r.Objective)
+			continue
+		}
+
+		j := s.branchVariable(p, r.X, pc, opts)
+		if j < 0 {
+			incumbent(r.X, r.Objective, sol.Bound, false)
+			continue
+		}
+		if opts.heuristic && (sol.Nodes-1)%MILP_HEURISTIC_FREQUENCY == 0 {
+			x, it := roundingHeuristic(work, lower, upper, r.X)
+			sol.LPIterations += it
+			if x != nil {
+				incumbent(x, p.Objective(x), sol.Bound, true)
+			}
+		}
+
+		// the child toward the nearest integer is explored first by a depth-first search
+		f := r.X[j] - math.Floor(r.X[j])
+		down := &node{
+			lower: nd.lower, upper: linalg.NewVector(n), bound: r.Objective, depth: nd.depth + 1,
+			basis: r.Basis, variable: j, distance: f,
+		}
+		copy(down.upper, nd.upper)
+		down.upper[j] = math.Floor(r.X[j])
+		up := &node{
+			lower: linalg.NewVector(n), upper: nd.upper, bound: r.Objective, depth: nd.depth + 1,
+			basis: r.Basis, variable: j, up: true, distance: 1 - f,
+		}
+		copy(up.lower, nd.lower)
+		up.lower[j] = math.Ceil(r.X[j])
+		if f > 0.5 {
+			open = append(open, down, up)
+		} else {
+			open = append(open, up, down)
+		}
+	}
+	if len(open) == 0 {
+		sol.Bound = math.Min(pruned, sol.Objective)
+	}
+
+	switch {
+	case sol.X == nil && sol.Status == Optimal:
+		sol.Status = Infeasible
+	case failed && sol.Status == Optimal:
+		// a relaxation could not be solved, so its subtree is unexplored
+		sol.Status = NumericalError
+	}
+	if sol.X != nil {
+		sol.Gap = (sol.Objective - sol.Bound) / math.Max(1, math.Abs(sol.Objective))
+	}
+	return sol
+}
+
+// selectNode returns the index of the next open node to explore.
+func selectNode(open []*node, rule NodeSelection, haveIncumbent bool) int {
+	if rule == DepthFirst || (rule == Hybrid && !haveIncumbent) {
+		return len(open) - 1
+	}
+	best := 0
+	for k, nd := range open {
+		if nd.bound < open[best].bound || (nd.bound == open[best].bound && nd.depth > open[best].depth) {
+			best = k
+		}
+	}
+	return best
+}
+
+// branchVariable returns the integer column to branch on, or -1 if x is integral.
+func (s *branchAndBoundSolver) branchVariable(p *Problem, x linalg.Vector, pc *pseudocosts, opts *solveOptions) int {
+	best, bestScore := -1, math.Inf(-1)
+	for j := range x {
+		if !p.isInteger(j) {
+			continue
+		}
+		f := x[j] - math.Floor(x[j])
+		if f <= opts.integerTolerance || f >= 1-opts.integerTolerance {
+			continue
+		}
+		var score float64
+		switch opts.branching {
+		case Pseudocost:
+			down := math.Max(pc.estimate(j, 0)*f, 1e-6)
+			up := math.Max(pc.estimate(j, 1)*(1-f), 1e-6)
+			score = down * up
+		default:
+			score = math.Min(f, 1-f)
+		}
+		if score > bestScore {
+			best, bestScore = j, score
+		}
+	}
+	return best
+}
+
+func NewBranchAndBoundSolver() *branchAndBoundSolver {
+	return &branchAndBoundSolver{}
+}
@@ -0,0 +1,270 @@
+package milp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+)
+
+var MILP_MAX_CUTS_PER_ROUND = 50
+var MILP_GOMORY_MIN_FRACTION = 0.01 // smallest distance of a basic value to an integer for a cut
+var MILP_MAX_DYNAMISM = 1e6         // largest ratio of the cut coefficients
+
+// cut is the inequality coef^T * x >= rhs.
+type cut struct {
+	coef linalg.Vector
+	rhs  float64
+}
+
+// withCuts returns the problem with the cuts appended as rows. The row bounds of the
+// result are never nil.
+func (p *Problem) withCuts(cuts []cut) *Problem {
+	n := p.C.Len()
+	m := p.A.Rows()
+	t := linalg.NewTripletMatrix(m+len(cuts), n)
+	for j := 0; j < n; j++ {
+		rows, values := p.A.Column(j)
+		for k, i := range rows {
+			t.Append(i, j, values[k])
+		}
+	}
+	rowLower := linalg.NewVector(m + len(cuts))
+	rowUpper := linalg.NewVector(m + len(cuts))
+	for i := 0; i < m; i++ {
+		rowLower[i], rowUpper[i] = math.Inf(-1), math.Inf(1)
+		if p.RowLower != nil {
+			rowLower[i] = p.RowLower[i]
+		}
+		if p.RowUpper != nil {
+			rowUpper[i] = p.RowUpper[i]
+		}
+	}
+	for k, c := range cuts {
+		for j, v := range c.coef {
+			if v != 0 {
+				t.Append(m+k, j, v)
+			}
+		}
+		rowLower[m+k], rowUpper[m+k] = c.rhs, math.Inf(1)
+	}
+	return &Problem{
+		C:        p.C,
+		A:        t.ToCSC(),
+		RowLower: rowLower,
+		RowUpper: rowUpper,
+		Lower:    p.Lower,
+		Upper:    p.Upper,
+		Integer:  p.Integer,
+	}
+}
+
+// gomoryCuts returns Gomory mixed-integer cuts from the rows of the optimal simplex
+// tableau whose basic variable is an integer column with a fractional value.
+//
+// With the slacks s = A * x, a tableau row expresses the basic variable through the
+// nonbasic variables v_j = bound_j +- t_j, t_j >= 0:
+//
+//	x_B + sum_j abar_j * t_j = b
+//
+// and with f_0 the fractional part of b and f_j that of abar_j the cut is
+//
+//	sum_{integer, f_j <= f_0} f_j / f_0 * t_j + sum_{integer, f_j > f_0} (1 - f_j) / (1 - f_0) * t_j
+//	  + sum_{continuous, abar_j > 0} abar_j / f_0 * t_j - sum_{continuous, abar_j < 0} abar_j / (1 - f_0) * t_j >= 1
+//
+// which is then written in terms of x. The slacks are treated as continuous.
+func gomoryCuts(p *Problem, lower, upper, x, activities linalg.Vector, basis lp.Basis) []cut {
+	n := p.C.Len()
+	m := p.A.Rows()
+	value := func(j int) float64 {
+		if j < n {
+			return x[j]
+		}
+		return activities[j-n]
+	}
+	bound := func(j int) (float64, float64) {
+		if j < n {
+			return lower[j], upper[j]
+		}
+		return p.RowLower[j-n], p.RowUpper[j-n]
+	}
+	status := func(j int) lp.BasisStatus {
+		if j < n {
+			return basis.Columns[j]
+		}
+		return basis.Rows[j-n]
+	}
+
+	// the basis matrix from the columns of [A -I]
+	var head []int
+	for j := 0; j < n+m; j++ {
+		if status(j) == lp.Basic {
+			head = append(head, j)
+		}
+	}
+	if len(head) != m {
+		return nil
+	}
+	B := linalg.NewDenseMatrix(m, m)
+	for k, j := range head {
+		if j >= n {
+			B.Set(j-n, k, -1)
+			continue
+		}
+		rows, values := p.A.Column(j)
+		for t, i := range rows {
+			B.Set(i, k, values[t])
+		}
+	}
+	lu, err := linalg.FactorLU(B)
+	if err != nil {
+		return nil
+	}
+
+	// the rows with the most fractional basic integer columns first
+	type candidate struct {
+		k    int
+		frac float64
+	}
+	var candidates []candidate
+	for k, j := range head {
+		if j >= n || !p.isInteger(j) {
+			continue
+		}
+		f := x[j] - math.Floor(x[j])
+		if f >= MILP_GOMORY_MIN_FRACTION && f <= 1-MILP_GOMORY_MIN_FRACTION {
+			candidates = append(candidates, candidate{k, f})
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return math.Abs(candidates[a].frac-0.5) < math.Abs(candidates[b].frac-0.5)
+	})
+
+	At := p.A.Transpose()
+	e := linalg.NewVector(m)
+	r := linalg.NewVector(m)
+	coefV := linalg.NewVector(n + m)
+	var cuts []cut
+	for _, c := range candidates {
+		if len(cuts) == MILP_MAX_CUTS_PER_ROUND {
+			break
+		}
+		// row k of B^-1, and the tableau row v_B = -r^T * N * v_N
+		e.Zero()
+		e[c.k] = 1
+		lu.SolveTranspose(e, r)
+		f0 := c.frac
+		rhs := 1.0
+		coefV.Zero()
+		valid := true
+		for j := 0; j < n+m && valid; j++ {
+			st := status(j)
+			if st == lp.Basic {
+				continue
+			}
+			alpha := 0.0
+			if j < n {
+				rows, values := p.A.Column(j)
+				for t, i := range rows {
+					alpha -= r[i] * values[t]
+				}
+			} else {
+				alpha = r[j-n]
+			}
+			l, u := bound(j)
+			if math.Abs(alpha) < 1e-12 || l == u {
+				continue
+			}
+			if st == lp.Free {
+				valid = false
+				continue
+			}
+
+			// abar_j = -alpha for v_j = l_j + t_j and alpha for v_j = u_j - t_j
+			abar := -alpha
+			if st == lp.AtUpper {
+				abar = alpha
+			}
+			integral := j < n && p.isInteger(j) && value(j) == math.Round(value(j))
+			var g float64
+			switch fj := abar - math.Floor(abar); {
+			case integral && fj <= f0:
+				g = fj / f0
+			case integral:
+				g = (1 - fj) / (1 - f0)
+			case abar >= 0:
+				g = abar / f0
+			default:
+				g = -abar / (1 - f0)
+			}
+			if st == lp.AtUpper {
+				coefV[j] = -g
+				rhs -= g * u
+			} else {
+				coefV[j] = g
+				rhs += g * l
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		// substitute the slacks s_i = A_i * x
+		coef := linalg.NewVector(n)
+		copy(coef, coefV[:n])
+		for i := 0; i < m; i++ {
+			if coefV[n+i] == 0 {
+				continue
+			}
+			cols, values := At.Column(i)
+			for t, j := range cols {
+				coef[j] += coefV[n+i] * values[t]
+			}
+		}
+		if ct, ok := cleanCut(coef, rhs, lower, upper); ok {
+			// keep only cuts that separate the current solution
+			activity := 0.0
+			for j := range ct.coef {
+				activity += ct.coef[j] * x[j]
+			}
+			if activity < ct.rhs-1e-6 {
+				cuts = append(cuts, ct)
+			}
+		}
+	}
+	return cuts
+}
+
+// cleanCut scales a cut to a largest coefficient of one and removes the tiny
+// coefficients by relaxing the right-hand side with the column bounds. Returns false if
+// the cut is empty or badly scaled.
+func cleanCut(coef linalg.Vector, rhs float64, lower, upper linalg.Vector) (cut, bool) {
+	largest := 0.0
+	for _, v := range coef {
+		largest = math.Max(largest, math.Abs(v))
+	}
+	if largest == 0 || math.IsInf(rhs, 0) || math.IsNaN(rhs) {
+		return cut{}, false
+	}
+	smallest := largest
+	for j, v := range coef {
+		coef[j] = v / largest
+		switch {
+		case coef[j] == 0:
+		case math.Abs(coef[j]) < 1e-9 && coef[j] > 0 && !math.IsInf(upper[j], 1):
+			// coef_j * x_j <= coef_j * u_j
+			rhs -= v * upper[j]
+			coef[j] = 0
+		case math.Abs(coef[j]) < 1e-9 && coef[j] < 0 && !math.IsInf(lower[j], -1):
+			rhs -= v * lower[j]
+			coef[j] = 0
+		default:
+			smallest = math.Min(smallest, math.Abs(v))
+		}
+	}
+	if largest/smallest > MILP_MAX_DYNAMISM {
+		return cut{}, false
+	}
+	return cut{coef: coef, rhs: rhs / largest}, true
+}
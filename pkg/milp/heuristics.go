@@ -0,0 +1,54 @@
+package milp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+)
+
+var MILP_FEASIBILITY_TOLERANCE = 1e-6 // row violation accepted by the rounding heuristic
+
+// roundingHeuristic rounds the integer columns of a relaxation solution to the nearest
+// integers and, if there are continuous columns, solves for them with the integer
+// columns fixed. Returns the rounded solution if it is feasible, or nil, and the number
+// of simplex iterations used.
+func roundingHeuristic(p *Problem, lower, upper, x linalg.Vector) (linalg.Vector, int) {
+	n := p.C.Len()
+	fixedLower := linalg.NewVector(n)
+	fixedUpper := linalg.NewVector(n)
+	continuous := false
+	for j := 0; j < n; j++ {
+		if !p.isInteger(j) {
+			fixedLower[j], fixedUpper[j] = lower[j], upper[j]
+			continuous = true
+			continue
+		}
+		v := math.Min(math.Max(math.Round(x[j]), lower[j]), upper[j])
+		fixedLower[j], fixedUpper[j] = v, v
+	}
+
+	if continuous {
+		r := lp.NewSimplexSolver().Solve(p.relaxation(fixedLower, fixedUpper))
+		if r.Status != lp.Optimal {
+			return nil, r.Iterations
+		}
+		return r.X, r.Iterations
+	}
+
+	// a pure integer problem only needs a check of the rows
+	activity := linalg.NewVector(p.A.Rows())
+	for j := 0; j < n; j++ {
+		rows, values := p.A.Column(j)
+		for k, i := range rows {
+			activity[i] += values[k] * fixedLower[j]
+		}
+	}
+	for i, a := range activity {
+		tol := MILP_FEASIBILITY_TOLERANCE * (1 + math.Abs(a))
+		if a < p.RowLower[i]-tol || a > p.RowUpper[i]+tol {
+			return nil, 0
+		}
+	}
+	return fixedLower, 0
+}
@@ -0,0 +1,224 @@
+// Package milp solves mixed-integer linear programs
+//
+//	minimize c^T * x
+//	subject to rowLower <= A * x <= rowUpper, lower <= x <= upper,
+//	x_j integer for the integer columns j
+//
+// by branch-and-bound on linear relaxations solved with the simplex method of package
+// lp, strengthened at the root by Gomory mixed-integer cuts.
+package milp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+)
+
+// Problem is a mixed-integer linear program. The fields are those of lp.Problem, and
+// Integer marks the columns that must take integer values.
+type Problem struct {
+	C        linalg.Vector
+	A        linalg.SparseMatrix
+	RowLower linalg.Vector
+	RowUpper linalg.Vector
+	Lower    linalg.Vector
+	Upper    linalg.Vector
+	Integer  []bool
+}
+
+type Status int
+
+const (
+	Optimal Status = iota // optimal within the gap tolerance
+	Infeasible
+	Unbounded // the linear relaxation is unbounded
+	NodeLimit
+	NumericalError
+)
+
+func (s Status) String() string {
+	switch s {
+	case Optimal:
+		return "optimal"
+	case Infeasible:
+		return "infeasible"
+	case Unbounded:
+		return "unbounded"
+	case NodeLimit:
+		return "node limit"
+	case NumericalError:
+		return "numerical error"
+	}
+	return "unknown"
+}
+
+// NodeSelection is the rule that chooses the next open node of the search tree.
+type NodeSelection int
+
+const (
+	// BestBound explores the node with the lowest relaxation bound, which raises the
+	// global bound fastest.
+	BestBound NodeSelection = iota
+	// DepthFirst explores the most recently created node, which finds incumbents quickly
+	// and keeps the tree small.
+	DepthFirst
+	// Hybrid dives depth-first until the first incumbent is found and then switches to
+	// best-bound.
+	Hybrid
+)
+
+// Branching is the rule that chooses the fractional variable to branch on.
+type Branching int
+
+const (
+	// MostFractional branches on the variable whose fractional part is closest to 1/2.
+	MostFractional Branching = iota
+	// Pseudocost branches on the variable with the largest product of the estimated
+	// objective increases of its two children, learned from earlier branchings.
+	Pseudocost
+)
+
+// Incumbent is an improved integer solution found during the search.
+type Incumbent struct {
+	Objective float64
+	Bound     float64 // global lower bound when the incumbent was found
+	Node      int     // number of nodes explored when the incumbent was found
+	Heuristic bool    // found by the rounding heuristic rather than at a node
+}
+
+// solution is the result of a mixed-integer linear program. X is the best integer
+// solution found, or nil if there is none. Bound is a lower bound on the optimal
+// objective and Gap the relative gap (Objective - Bound) / max(1, |Objective|).
+type solution struct {
+	Status       Status
+	X            linalg.Vector
+	Objective    float64
+	Bound        float64
+	Gap          float64
+	Nodes        int
+	LPIterations int
+	Cuts         int
+	Incumbents   []Incumbent
+}
+
+// solveOptions are the options for a mixed-integer linear program.
+type solveOptions struct {
+	nodeSelection    NodeSelection
+	branching        Branching
+	gap              float64
+	maxNodes         int
+	cutRounds        int
+	heuristic        bool
+	integerTolerance float64
+}
+
+func newSolveOptions(options ...func(*solveOptions)) *solveOptions {
+	opts := &solveOptions{
+		nodeSelection:    Hybrid,
+		branching:        Pseudocost,
+		gap:              1e-6,
+		maxNodes:         MILP_MAX_NODES,
+		cutRounds:        MILP_GOMORY_ROUNDS,
+		heuristic:        true,
+		integerTolerance: 1e-6,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+func WithNodeSelection(nodeSelection NodeSelection) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.nodeSelection = nodeSelection
+	}
+}
+
+func WithBranching(branching Branching) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.branching = branching
+	}
+}
+
+// WithGap sets the relative optimality gap at which the search stops.
+func WithGap(gap float64) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.gap = gap
+	}
+}
+
+// WithNodeLimit sets the largest number of nodes explored.
+func WithNodeLimit(maxNodes int) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.maxNodes = maxNodes
+	}
+}
+
+// WithCutRounds sets the number of rounds of Gomory mixed-integer cuts added at the root;
+// zero disables the cuts.
+func WithCutRounds(rounds int) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.cutRounds = rounds
+	}
+}
+
+// WithHeuristic enables or disables the rounding heuristic.
+func WithHeuristic(heuristic bool) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.heuristic = heuristic
+	}
+}
+
+// WithIntegerTolerance sets the distance to the nearest integer accepted as integral.
+func WithIntegerTolerance(tolerance float64) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.integerTolerance = tolerance
+	}
+}
+
+// relaxation returns the linear relaxation with the given column bounds.
+func (p *Problem) relaxation(lower, upper linalg.Vector) *lp.Problem {
+	return &lp.Problem{
+		C:        p.C,
+		A:        p.A,
+		RowLower: p.RowLower,
+		RowUpper: p.RowUpper,
+		Lower:    lower,
+		Upper:    upper,
+	}
+}
+
+// columnBounds returns the column bounds, rounded inward for the integer columns.
+func (p *Problem) columnBounds() (lower, upper linalg.Vector) {
+	n := p.C.Len()
+	if p.A.Cols() != n || (p.Integer != nil && len(p.Integer) != n) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	lower = linalg.NewVector(n)
+	upper = linalg.NewVector(n)
+	for j := 0; j < n; j++ {
+		lower[j], upper[j] = math.Inf(-1), math.Inf(1)
+		if p.Lower != nil {
+			lower[j] = p.Lower[j]
+		}
+		if p.Upper != nil {
+			upper[j] = p.Upper[j]
+		}
+		if p.isInteger(j) {
+			lower[j] = math.Ceil(lower[j] - 1e-9)
+			upper[j] = math.Floor(upper[j] + 1e-9)
+		}
+	}
+	return lower, upper
+}
+
+func (p *Problem) isInteger(j int) bool {
+	return p.Integer != nil && p.Integer[j]
+}
+
+// Objective returns c^T * x.
+func (p *Problem) Objective(x linalg.Vector) float64 {
+	return p.relaxation(nil, nil).Objective(x)
+}
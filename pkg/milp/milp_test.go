@@ -0,0 +1,145 @@
+package milp_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/milp"
+)
+
+func sparseMatrix(rows [][]float64) linalg.SparseMatrix {
+	t := linalg.NewTripletMatrix(len(rows), len(rows[0]))
+	for i := range rows {
+		for j, v := range rows[i] {
+			if v != 0 {
+				t.Append(i, j, v)
+			}
+		}
+	}
+	return t.ToCSC()
+}
+
+// knapsackProblem returns a random 0-1 knapsack problem with n items, written as a
+// minimization, and its optimal objective found by enumeration.
+func knapsackProblem(n int, seed int64) (*milp.Problem, float64) {
+	rng := rand.New(rand.NewSource(seed))
+	values := make([]float64, n)
+	weights := make([]float64, n)
+	capacity := 0.0
+	for j := range values {
+		weights[j] = float64(10 + rng.Intn(40))
+		values[j] = weights[j] + float64(rng.Intn(20))
+		capacity += weights[j] / 2
+	}
+	capacity = math.Floor(capacity)
+
+	best := 0.0
+	for mask := 0; mask < 1<<n; mask++ {
+		w, v := 0.0, 0.0
+		for j := 0; j < n; j++ {
+			if mask&(1<<j) != 0 {
+				w += weights[j]
+				v += values[j]
+			}
+		}
+		if w <= capacity {
+			best = math.Max(best, v)
+		}
+	}
+
+	p := &milp.Problem{
+		C:        linalg.NewVector(n),
+		A:        sparseMatrix([][]float64{weights}),
+		RowUpper: linalg.Vector{capacity},
+		Lower:    linalg.NewVector(n),
+		Upper:    linalg.NewVector(n).Set(1),
+		Integer:  make([]bool, n),
+	}
+	for j := range values {
+		p.C[j] = -values[j]
+		p.Integer[j] = true
+	}
+	return p, -best
+}
+
+func TestKnapsack(t *testing.T) {
+	problem, optimum := knapsackProblem(14, 1)
+	for _, selection := range []milp.NodeSelection{milp.BestBound, milp.DepthFirst, milp.Hybrid} {
+		for _, branching := range []milp.Branching{milp.MostFractional, milp.Pseudocost} {
+			sol := milp.NewBranchAndBoundSolver().Solve(problem,
+				milp.WithNodeSelection(selection), milp.WithBranching(branching), milp.WithGap(0))
+			if sol.Status != milp.Optimal || math.Abs(sol.Objective-optimum) > 1e-6 {
+				t.Errorf("selection %v, branching %v: expected optimum %v, got %v with %v",
+					selection, branching, optimum, sol.Status, sol.Objective)
+				continue
+			}
+			if sol.Gap > 1e-9 || sol.Bound > sol.Objective+1e-9 {
+				t.Errorf("selection %v, branching %v: bound %v and gap %v", selection, branching, sol.Bound, sol.Gap)
+			}
+			// the incumbents improve monotonically and end at the optimum
+			for k := 1; k < len(sol.Incumbents); k++ {
+				if sol.Incumbents[k].Objective >= sol.Incumbents[k-1].Objective {
+					t.Errorf("incumbent history is not improving: %v", sol.Incumbents)
+				}
+			}
+			if last := sol.Incumbents[len(sol.Incumbents)-1]; last.Objective != sol.Objective {
+				t.Errorf("the last incumbent %v is not the solution %v", last.Objective, sol.Objective)
+			}
+		}
+	}
+}
+
+func TestGomoryCuts(t *testing.T) {
+	// minimize -x - y subject to y >= x + 1/2, 10y <= 8x + 13, whose relaxation optimum
+	// (4, 4.5) is far from the integer optimum (1, 2)
+	inf := math.Inf(1)
+	problem := &milp.Problem{
+		C:        linalg.Vector{-1, -1},
+		A:        sparseMatrix([][]float64{{-2, 2}, {-8, 10}}),
+		RowLower: linalg.Vector{1, -inf},
+		RowUpper: linalg.Vector{inf, 13},
+		Lower:    linalg.Vector{0, 0},
+		Integer:  []bool{true, true},
+	}
+	plain := milp.NewBranchAndBoundSolver().Solve(problem, milp.WithCutRounds(0), milp.WithHeuristic(false))
+	cut := milp.NewBranchAndBoundSolver().Solve(problem, milp.WithHeuristic(false))
+	if plain.Status != milp.Optimal || cut.Status != milp.Optimal || plain.Objective != -3 || cut.Objective != -3 {
+		t.Fatalf("expected the optimum -3, got %v and %v", plain.Objective, cut.Objective)
+	}
+	if cut.Cuts == 0 || cut.Nodes > plain.Nodes {
+		t.Errorf("expected the cuts to shrink the tree: %d cuts, %d nodes with cuts, %d without", cut.Cuts, cut.Nodes, plain.Nodes)
+	}
+}
+
+func TestLimitsAndInfeasibility(t *testing.T) {
+	problem, optimum := knapsackProblem(14, 2)
+	sol := milp.NewBranchAndBoundSolver().Solve(problem, milp.WithNodeLimit(3), milp.WithCutRounds(0))
+	if sol.Status != milp.NodeLimit || sol.Nodes != 3 {
+		t.Errorf("expected the node limit after 3 nodes, got %v after %d", sol.Status, sol.Nodes)
+	}
+	if sol.X != nil && (sol.Bound > optimum+1e-9 || sol.Objective < optimum-1e-9 || sol.Gap < 0) {
+		t.Errorf("bound %v and incumbent %v do not bracket the optimum %v", sol.Bound, sol.Objective, optimum)
+	}
+
+	// a large gap stops at the first incumbent close enough to the bound
+	sol = milp.NewBranchAndBoundSolver().Solve(problem, milp.WithGap(0.5))
+	if sol.Status != milp.Optimal || sol.Gap > 0.5 {
+		t.Errorf("expected a gap below 0.5, got %v with %v", sol.Status, sol.Gap)
+	}
+
+	// 0.2 <= x <= 0.8 has no integer solution
+	infeasible := &milp.Problem{
+		C:        linalg.Vector{1, 1},
+		A:        sparseMatrix([][]float64{{5, 0}}),
+		RowLower: linalg.Vector{1},
+		RowUpper: linalg.Vector{4},
+		Lower:    linalg.Vector{0, 0},
+		Upper:    linalg.Vector{10, 10},
+		Integer:  []bool{true, false},
+	}
+	if sol := milp.NewBranchAndBoundSolver().Solve(infeasible); sol.Status != milp.Infeasible {
+		t.Errorf("expected infeasible, got %v", sol.Status)
+	}
+}
@@ -0,0 +1,797 @@
+package modelio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// lpToken is a token of an LP file. Numbers, including inf and infinity, have number
+// set; operators and names are kept as text.
+type lpToken struct {
+	text   string
+	line   int
+	number bool
+	value  float64
+}
+
+const lpOperators = "+-*^:[]/<>="
+
+// lpSection is the tokens of one section of an LP file.
+type lpSection struct {
+	kind   string
+	line   int
+	tokens []lpToken
+}
+
+// lpKeyword returns the section started by a line and the rest of the line, or an
+// empty section if the line does not start with a keyword.
+func lpKeyword(text string) (string, string) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 {
+		return "", text
+	}
+	section, words := "", 1
+	if len(fields) > 1 && (fields[0]+" "+fields[1] == "subject to" || fields[0]+" "+fields[1] == "such that") {
+		section, words = "constraints", 2
+	} else {
+		switch fields[0] {
+		case "minimize", "minimise", "minimum", "min":
+			section = "minimize"
+		case "maximize", "maximise", "maximum", "max":
+			section = "maximize"
+		case "st", "s.t.", "st.":
+			section = "constraints"
+		case "bounds", "bound":
+			section = "bounds"
+		case "generals", "general", "gen", "integers":
+			section = "general"
+		case "binaries", "binary", "bin":
+			section = "binary"
+		case "semi-continuous", "semis", "semi":
+			section = "semi"
+		case "sos", "sos1", "sos2":
+			section = "sos"
+		case "end":
+			section = "end"
+		default:
+			return "", text
+		}
+	}
+	rest := strings.TrimLeft(text, " \t")
+	for ; words > 0; words-- {
+		if k := strings.IndexAny(rest, " \t"); k >= 0 {
+			rest = strings.TrimLeft(rest[k:], " \t")
+		} else {
+			rest = ""
+		}
+	}
+	return section, rest
+}
+
+// lpTokenize splits a line of an LP file into tokens.
+func lpTokenize(text string, line int) ([]lpToken, error) {
+	var tokens []lpToken
+	for k := 0; k < len(text); {
+		c := text[k]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			k++
+		case c == '<' || c == '>' || c == '=':
+			// <=, =<, <, >=, =>, > and =
+			op := string(c)
+			end := k + 1
+			if end < len(text) && (text[end] == '=' || (c == '=' && (text[end] == '<' || text[end] == '>'))) {
+				op += string(text[end])
+				end++
+			}
+			switch op {
+			case "<", "<=", "=<":
+				op = "<="
+			case ">", ">=", "=>":
+				op = ">="
+			case "=", "==":
+				op = "="
+			}
+			tokens = append(tokens, lpToken{text: op, line: line})
+			k = end
+		case strings.IndexByte(lpOperators, c) >= 0:
+			tokens = append(tokens, lpToken{text: string(c), line: line})
+			k++
+		case c >= '0' && c <= '9' || c == '.':
+			end := k
+			for end < len(text) && (text[end] >= '0' && text[end] <= '9' || text[end] == '.') {
+				end++
+			}
+			// an exponent needs digits after it, otherwise the e starts a name
+			if end < len(text) && (text[end] == 'e' || text[end] == 'E') {
+				e := end + 1
+				if e < len(text) && (text[e] == '+' || text[e] == '-') {
+					e++
+				}
+				if e < len(text) && text[e] >= '0' && text[e] <= '9' {
+					for e < len(text) && text[e] >= '0' && text[e] <= '9' {
+						e++
+					}
+					end = e
+				}
+			}
+			v, err := strconv.ParseFloat(text[k:end], 64)
+			if err != nil {
+				return nil, syntaxError(line, "invalid number %q", text[k:end])
+			}
+			tokens = append(tokens, lpToken{text: text[k:end], line: line, number: true, value: v})
+			k = end
+		default:
+			end := k
+			for end < len(text) && text[end] != ' ' && text[end] != '\t' && strings.IndexByte(lpOperators, text[end]) < 0 {
+				end++
+			}
+			name := text[k:end]
+			if lower := strings.ToLower(name); lower == "inf" || lower == "infinity" {
+				tokens = append(tokens, lpToken{text: name, line: line, number: true, value: math.Inf(1)})
+			} else {
+				tokens = append(tokens, lpToken{text: name, line: line})
+			}
+			k = end
+		}
+	}
+	return tokens, nil
+}
+
+// lpParser parses the tokens of one section.
+type lpParser struct {
+	b      *modelBuilder
+	tokens []lpToken
+	pos    int
+	line   int // line of the section header, reported at the end of the section
+}
+
+func (p *lpParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *lpParser) peek() lpToken {
+	if p.done() {
+		return lpToken{line: p.lastLine()}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *lpParser) lastLine() int {
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1].line
+	}
+	return p.line
+}
+
+func (p *lpParser) next() lpToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *lpParser) isOperator(t lpToken) bool {
+	return !t.number && (t.text == "<=" || t.text == ">=" || t.text == "=")
+}
+
+func (p *lpParser) isName(t lpToken) bool {
+	return !t.number && t.text != "" && strings.IndexByte(lpOperators, t.text[0]) < 0
+}
+
+func (p *lpParser) atLabel() bool {
+	return p.pos+1 < len(p.tokens) && p.isName(p.tokens[p.pos]) && p.tokens[p.pos+1].text == ":"
+}
+
+// label returns the name before a colon, if there is one.
+func (p *lpParser) label() string {
+	if p.atLabel() {
+		name := p.tokens[p.pos].text
+		p.pos += 2
+		return name
+	}
+	return ""
+}
+
+// number reads a signed bound or right-hand side, which is infinite from INFINITY on.
+func (p *lpParser) number() (float64, error) {
+	sign := 1.0
+	for t := p.peek(); t.text == "+" || t.text == "-"; t = p.peek() {
+		if t.text == "-" {
+			sign = -sign
+		}
+		p.next()
+	}
+	t := p.next()
+	if !t.number {
+		return 0, syntaxError(t.line, "expected a number, got %q", t.text)
+	}
+	return infinite(sign * t.value), nil
+}
+
+// lpExpression is a parsed linear expression with an optional quadratic part.
+type lpExpression struct {
+	terms     map[int]float64
+	order     []int
+	constant  float64
+	quadratic map[[2]int]float64
+}
+
+func (e *lpExpression) add(j int, v float64) {
+	if _, ok := e.terms[j]; !ok {
+		e.order = append(e.order, j)
+	}
+	e.terms[j] += v
+}
+
+// expression reads terms until an operator, a label or the end of the section. A bracketed
+// quadratic part is allowed only in the objective.
+func (p *lpParser) expression(quadratic bool) (*lpExpression, error) {
+	e := &lpExpression{terms: make(map[int]float64), quadratic: make(map[[2]int]float64)}
+	for first := true; !p.done() && !p.isOperator(p.peek()) && !p.atLabel(); first = false {
+		sign, signed := 1.0, false
+		for t := p.peek(); t.text == "+" || t.text == "-"; t = p.peek() {
+			if t.text == "-" {
+				sign = -sign
+			}
+			signed = true
+			p.next()
+		}
+		t := p.next()
+		if !first && !signed {
+			return nil, syntaxError(t.line, "expected + or - before %q", t.text)
+		}
+		switch {
+		case t.text == "[":
+			if !quadratic {
+				return nil, syntaxError(t.line, "quadratic terms are only allowed in the objective")
+			}
+			if err := p.quadratic(e, sign); err != nil {
+				return nil, err
+			}
+		case t.number && p.isName(p.peek()):
+			if math.IsInf(t.value, 0) {
+				return nil, syntaxError(t.line, "infinite coefficient")
+			}
+			e.add(p.b.column(p.next().text), sign*t.value)
+		case t.number:
+			e.constant += sign * t.value
+		case p.isName(t):
+			e.add(p.b.column(t.text), sign)
+		default:
+			return nil, syntaxError(t.line, "unexpected %q", t.text)
+		}
+	}
+	return e, nil
+}
+
+// quadratic reads the terms of "[ ... ]" or "[ ... ] / 2" after the opening bracket and
+// adds them to the expression as entries of Q in 1/2 * x^T * Q * x.
+func (p *lpParser) quadratic(e *lpExpression, sign float64) error {
+	type term struct {
+		i, j int
+		v    float64
+	}
+	var terms []term
+	for first := true; ; first = false {
+		t := p.peek()
+		if t.text == "]" {
+			p.next()
+			break
+		}
+		if t.text == "" {
+			return syntaxError(t.line, "missing ]")
+		}
+		s, signed := 1.0, false
+		for t = p.peek(); t.text == "+" || t.text == "-"; t = p.peek() {
+			if t.text == "-" {
+				s = -s
+			}
+			signed = true
+			p.next()
+		}
+		if !first && !signed {
+			return syntaxError(t.line, "expected + or - before %q", t.text)
+		}
+		if t.number {
+			s *= t.value
+			p.next()
+		}
+		name := p.next()
+		if !p.isName(name) {
+			return syntaxError(name.line, "expected a variable, got %q", name.text)
+		}
+		i := p.b.column(name.text)
+		switch op := p.next(); op.text {
+		case "^":
+			if power := p.next(); !power.number || power.value != 2 {
+				return syntaxError(power.line, "only squares are allowed")
+			}
+			terms = append(terms, term{i, i, s})
+		case "*":
+			other := p.next()
+			if !p.isName(other) {
+				return syntaxError(other.line, "expected a variable, got %q", other.text)
+			}
+			terms = append(terms, term{i, p.b.column(other.text), s})
+		default:
+			return syntaxError(op.line, "expected ^ or *, got %q", op.text)
+		}
+	}
+
+	// without the division by 2 the coefficients are doubled to give 1/2 * x^T * Q * x
+	factor := 2.0
+	if p.peek().text == "/" {
+		p.next()
+		if two := p.next(); !two.number || two.value != 2 {
+			return syntaxError(two.line, "expected / 2")
+		}
+		factor = 1
+	}
+	for _, t := range terms {
+		v := sign * factor * t.v
+		if t.i != t.j {
+			v /= 2
+		}
+		i, j := min(t.i, t.j), max(t.i, t.j)
+		e.quadratic[[2]int{i, j}] += v
+	}
+	return nil
+}
+
+func (p *lpParser) objective() error {
+	m := p.b.model
+	if name := p.label(); name != "" {
+		m.ObjectiveName = name
+	}
+	e, err := p.expression(true)
+	if err != nil {
+		return err
+	}
+	if !p.done() {
+		t := p.peek()
+		return syntaxError(t.line, "unexpected %q in the objective", t.text)
+	}
+	if math.IsInf(e.constant, 0) {
+		return syntaxError(p.lastLine(), "infinite objective constant")
+	}
+	for _, j := range e.order {
+		m.C[j] += e.terms[j]
+	}
+	for k, v := range e.quadratic {
+		p.b.addQuadratic(k[0], k[1], v)
+	}
+	m.ObjectiveOffset += e.constant
+	return nil
+}
+
+func (p *lpParser) constraints() error {
+	m := p.b.model
+	for !p.done() {
+		line := p.peek().line
+		name := p.label()
+		if name == "" {
+			name = "R" + strconv.Itoa(len(m.Rows)+1)
+		}
+		if _, ok := p.b.rowIndex[name]; ok {
+			return syntaxError(line, "duplicate row %q", name)
+		}
+		e, err := p.expression(false)
+		if err != nil {
+			return err
+		}
+		op := p.next()
+		if !p.isOperator(op) {
+			return syntaxError(op.line, "expected <=, >= or =")
+		}
+
+		lower, upper := math.Inf(-1), math.Inf(1)
+		if len(e.terms) == 0 {
+			// a constant on the left: c <= expr, or the ranged form l <= expr <= u
+			c := e.constant
+			if e, err = p.expression(false); err != nil {
+				return err
+			}
+			c -= e.constant
+			switch op.text {
+			case "<=":
+				lower = c
+			case ">=":
+				upper = c
+			default:
+				lower, upper = c, c
+			}
+			if p.isOperator(p.peek()) {
+				op2 := p.next()
+				rhs, err := p.number()
+				if err != nil {
+					return err
+				}
+				rhs -= e.constant
+				switch {
+				case op2.text == "<=" && op.text == "<=":
+					upper = rhs
+				case op2.text == ">=" && op.text == ">=":
+					lower = rhs
+				default:
+					return syntaxError(op2.line, "the operators of a ranged row must match")
+				}
+			}
+		} else {
+			rhs, err := p.number()
+			if err != nil {
+				return err
+			}
+			rhs -= e.constant
+			switch op.text {
+			case "<=":
+				upper = rhs
+			case ">=":
+				lower = rhs
+			default:
+				lower, upper = rhs, rhs
+			}
+		}
+
+		i := p.b.row(name)
+		m.RowLower[i], m.RowUpper[i] = lower, upper
+		for _, j := range e.order {
+			p.b.a[[2]int{i, j}] += e.terms[j]
+		}
+	}
+	return nil
+}
+
+func (p *lpParser) bounds() error {
+	m := p.b.model
+	set := func(j int, op string, v float64, reversed bool) {
+		if reversed {
+			// v op x
+			switch op {
+			case "<=":
+				op = ">="
+			case ">=":
+				op = "<="
+			}
+		}
+		switch op {
+		case "<=":
+			m.Upper[j] = v
+		case ">=":
+			m.Lower[j] = v
+		default:
+			m.Lower[j], m.Upper[j] = v, v
+		}
+	}
+	for !p.done() {
+		t := p.peek()
+		if p.isName(t) {
+			p.next()
+			j := p.b.column(t.text)
+			if next := p.peek(); p.isName(next) && strings.ToLower(next.text) == "free" {
+				p.next()
+				m.Lower[j], m.Upper[j] = math.Inf(-1), math.Inf(1)
+				continue
+			}
+			op := p.next()
+			if !p.isOperator(op) {
+				return syntaxError(op.line, "expected a bound on %q", t.text)
+			}
+			v, err := p.number()
+			if err != nil {
+				return err
+			}
+			set(j, op.text, v, false)
+			continue
+		}
+
+		// l <= x or l <= x <= u
+		v, err := p.number()
+		if err != nil {
+			return err
+		}
+		op := p.next()
+		if !p.isOperator(op) {
+			return syntaxError(op.line, "expected <=, >= or =")
+		}
+		name := p.next()
+		if !p.isName(name) {
+			return syntaxError(name.line, "expected a variable, got %q", name.text)
+		}
+		j := p.b.column(name.text)
+		set(j, op.text, v, true)
+		if p.isOperator(p.peek()) {
+			op2 := p.next()
+			if op2.text != op.text || op.text == "=" {
+				return syntaxError(op2.line, "the operators of a double bound must match")
+			}
+			v, err := p.number()
+			if err != nil {
+				return err
+			}
+			set(j, op2.text, v, false)
+		}
+	}
+	return nil
+}
+
+func (p *lpParser) integers(binary bool) error {
+	m := p.b.model
+	for !p.done() {
+		t := p.next()
+		if !p.isName(t) {
+			return syntaxError(t.line, "expected a variable, got %q", t.text)
+		}
+		j := p.b.column(t.text)
+		m.Integer[j] = true
+		if binary {
+			m.Lower[j], m.Upper[j] = 0, 1
+		}
+	}
+	return nil
+}
+
+// ReadLP reads a model in the CPLEX LP format. The quadratic part of the objective is
+// written as "[ ... ] / 2"; ranged rows as "l <= expr <= u". Text after a backslash is
+// a comment, and a "\Problem name:" comment sets the model name.
+func ReadLP(r io.Reader) (*Model, error) {
+	b := newModelBuilder()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var sections []*lpSection
+	line := 0
+	ended := false
+	for scanner.Scan() && !ended {
+		line++
+		text := scanner.Text()
+		if k := strings.IndexByte(text, '\\'); k >= 0 {
+			comment := strings.TrimSpace(text[k+1:])
+			if name, ok := strings.CutPrefix(comment, "Problem name:"); ok && len(sections) == 0 {
+				b.model.Name = strings.TrimSpace(name)
+			}
+			text = text[:k]
+		}
+		if kind, rest := lpKeyword(text); kind != "" {
+			switch kind {
+			case "semi":
+				return nil, syntaxError(line, "semi-continuous variables are not supported")
+			case "sos":
+				return nil, syntaxError(line, "special ordered sets are not supported")
+			case "end":
+				ended = true
+				continue
+			case "minimize", "maximize":
+				if len(sections) > 0 {
+					return nil, syntaxError(line, "the objective must be the first section")
+				}
+			}
+			sections = append(sections, &lpSection{kind: kind, line: line})
+			text = rest
+		}
+		tokens, err := lpTokenize(text, line)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(sections) == 0 {
+			return nil, syntaxError(line, "expected Minimize or Maximize")
+		}
+		s := sections[len(sections)-1]
+		s.tokens = append(s.tokens, tokens...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, syntaxError(line, "expected Minimize or Maximize")
+	}
+
+	for _, s := range sections {
+		p := &lpParser{b: b, tokens: s.tokens, line: s.line}
+		var err error
+		switch s.kind {
+		case "minimize", "maximize":
+			b.model.Maximize = s.kind == "maximize"
+			err = p.objective()
+		case "constraints":
+			err = p.constraints()
+		case "bounds":
+			err = p.bounds()
+		case "general", "binary":
+			err = p.integers(s.kind == "binary")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+// lpLineWriter writes items separated by spaces, wrapping lines that get long.
+type lpLineWriter struct {
+	w      *bufio.Writer
+	length int
+}
+
+const lpLineLength = 80
+
+func (lw *lpLineWriter) item(s string) {
+	if lw.length > 0 && lw.length+1+len(s) > lpLineLength {
+		lw.w.WriteString("\n")
+		lw.length = 0
+	}
+	lw.w.WriteString(" " + s)
+	lw.length += 1 + len(s)
+}
+
+func (lw *lpLineWriter) end() {
+	lw.w.WriteString("\n")
+	lw.length = 0
+}
+
+// validLPName reports whether a name can be written to an LP file.
+func validLPName(name string) bool {
+	if name == "" || strings.ContainsAny(name, " \t\\"+lpOperators) {
+		return false
+	}
+	if c := name[0]; c >= '0' && c <= '9' || c == '.' {
+		return false
+	}
+	if kind, _ := lpKeyword(name); kind != "" {
+		return false
+	}
+	lower := strings.ToLower(name)
+	return lower != "inf" && lower != "infinity" && lower != "free"
+}
+
+func lpNumber(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// lpTerm formats a term with its sign; the sign of the first term is omitted when it is
+// positive.
+func lpTerm(v float64, name string, first bool) string {
+	sign := "+ "
+	if v < 0 {
+		sign, v = "- ", -v
+	}
+	if first && sign == "+ " {
+		sign = ""
+	}
+	if v == 1 && name != "" {
+		return sign + name
+	}
+	if name == "" {
+		return sign + lpNumber(v)
+	}
+	return sign + lpNumber(v) + " " + name
+}
+
+// WriteLP writes a model in the CPLEX LP format. Every column appears in the objective,
+// with a zero coefficient if needed, so that the column order is kept when the file is
+// read back. Free rows are written as ">= -inf".
+func WriteLP(w io.Writer, m *Model) error {
+	cols, rows := names(m)
+	objective := m.ObjectiveName
+	if objective == "" {
+		objective = "obj"
+	}
+	for _, name := range append(append([]string{objective}, cols...), rows...) {
+		if !validLPName(name) {
+			return fmt.Errorf("name %q cannot be written to an LP file", name)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	lw := &lpLineWriter{w: bw}
+	if m.Name != "" {
+		fmt.Fprintf(bw, "\\Problem name: %s\n", m.Name)
+	}
+	if m.Maximize {
+		fmt.Fprintln(bw, "Maximize")
+	} else {
+		fmt.Fprintln(bw, "Minimize")
+	}
+	lw.item(objective + ":")
+	for j := range cols {
+		if m.C[j] == 0 {
+			lw.item(lpTerm(0, "", j == 0) + " " + cols[j])
+		} else {
+			lw.item(lpTerm(m.C[j], cols[j], j == 0))
+		}
+	}
+	if m.Q.NNZ() > 0 {
+		lw.item("+ [")
+		first := true
+		for j := range cols {
+			rowIdx, values := m.Q.Column(j)
+			for k, i := range rowIdx {
+				switch {
+				case i == j:
+					lw.item(lpTerm(values[k], cols[i]+" ^ 2", first))
+				case i < j:
+					lw.item(lpTerm(2*values[k], cols[i]+" * "+cols[j], first))
+				default:
+					continue
+				}
+				first = false
+			}
+		}
+		lw.item("] / 2")
+	}
+	if m.ObjectiveOffset != 0 {
+		lw.item(lpTerm(m.ObjectiveOffset, "", false))
+	}
+	lw.end()
+
+	fmt.Fprintln(bw, "Subject To")
+	At := m.A.Transpose()
+	for i := range rows {
+		l, u := m.RowLower[i], m.RowUpper[i]
+		lw.item(rows[i] + ":")
+		if !math.IsInf(l, -1) && !math.IsInf(u, 1) && l != u {
+			lw.item(lpNumber(l) + " <=")
+		}
+		colIdx, values := At.Column(i)
+		for k, j := range colIdx {
+			lw.item(lpTerm(values[k], cols[j], k == 0))
+		}
+		if len(colIdx) == 0 && len(cols) > 0 {
+			lw.item("0 " + cols[0])
+		}
+		switch {
+		case l == u:
+			lw.item("= " + lpNumber(u))
+		case math.IsInf(u, 1):
+			lw.item(">= " + lpNumber(l))
+		default:
+			lw.item("<= " + lpNumber(u))
+		}
+		lw.end()
+	}
+
+	fmt.Fprintln(bw, "Bounds")
+	for j, name := range cols {
+		l, u := m.Lower[j], m.Upper[j]
+		switch {
+		case l == u:
+			fmt.Fprintf(bw, " %s = %s\n", name, lpNumber(l))
+		case math.IsInf(l, -1) && math.IsInf(u, 1):
+			fmt.Fprintf(bw, " %s free\n", name)
+		case math.IsInf(u, 1):
+			if l != 0 {
+				fmt.Fprintf(bw, " %s >= %s\n", name, lpNumber(l))
+			}
+		default:
+			fmt.Fprintf(bw, " %s <= %s <= %s\n", lpNumber(l), name, lpNumber(u))
+		}
+	}
+
+	general := false
+	for j, name := range cols {
+		if m.Integer != nil && m.Integer[j] {
+			if !general {
+				fmt.Fprintln(bw, "General")
+				general = true
+			}
+			lw.item(name)
+		}
+	}
+	if general {
+		lw.end()
+	}
+	fmt.Fprintln(bw, "End")
+	return bw.Flush()
+}
@@ -0,0 +1,138 @@
+package modelio_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tab58/go-optimize/pkg/modelio"
+)
+
+// lpSample is fixedSample in the LP format.
+const lpSample = `\Problem name: TESTLP
+Maximize
+ COST: X1 + 2 X2 - X3 + 3.5
+Subject To
+ LIM1: 1.5 <= X1 + X2 <= 4
+ LIM2: X1 >= 1
+ \ a ranged row
+ MYEQN: -1 <= -X2 + X3 <= 1
+Bounds
+ X1 <= 4
+ -inf <= X2 <= 1
+ X3 >= -infinity
+ X3 <= -2
+Generals
+ X1
+End
+`
+
+func TestReadLP(t *testing.T) {
+	expected, err := modelio.ReadMPS(strings.NewReader(fixedSample), modelio.FixedMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := modelio.ReadLP(strings.NewReader(lpSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameModel(t, expected, m)
+}
+
+func TestLPQuadratic(t *testing.T) {
+	model := `min
+ obj: - 2x - 4y + [ 2 x ^ 2 + 2 x * y + 2y^2 ] / 2
+st
+ x + y <= 2
+ c2: x - y >= -3
+bounds
+ x free
+ y free
+end
+`
+	m, err := modelio.ReadLP(strings.NewReader(model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// [x*y] / 2 contributes 1/2 * x * y to the objective, so Q has 1 off the diagonal
+	if m.Q.Get(0, 0) != 2 || m.Q.Get(1, 1) != 2 || m.Q.Get(0, 1) != 1 {
+		t.Errorf("expected Q = [2 1; 1 2], got %v", m.Q.ToDense())
+	}
+	if m.Rows[0] != "R1" || m.RowUpper[0] != 2 || m.RowLower[1] != -3 {
+		t.Errorf("unexpected rows %v with bounds %v and %v", m.Rows, m.RowLower, m.RowUpper)
+	}
+
+	var buf bytes.Buffer
+	if err := modelio.WriteLP(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	read, err := modelio.ReadLP(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameModel(t, m, read)
+}
+
+func TestWriteLP(t *testing.T) {
+	m, err := modelio.ReadMPS(strings.NewReader(fixedSample), modelio.FixedMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := modelio.WriteLP(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	read, err := modelio.ReadLP(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameModel(t, m, read)
+
+	m.Columns[0] = "2X"
+	if err := modelio.WriteLP(&bytes.Buffer{}, m); err == nil {
+		t.Error("expected an error for a name starting with a digit")
+	}
+}
+
+func TestLPInfinity(t *testing.T) {
+	const model = `Minimize
+ obj: x + y
+Subject To
+ c1: x + y <= 1e30
+ c2: x >= -1e+30
+Bounds
+ -1e30 <= x <= 1e30
+ y <= 2e30
+End
+`
+	m, err := modelio.ReadLP(strings.NewReader(model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(m.RowUpper[0], 1) || !math.IsInf(m.RowLower[1], -1) {
+		t.Errorf("expected infinite row bounds, got %v and %v", m.RowUpper, m.RowLower)
+	}
+	if !math.IsInf(m.Lower[0], -1) || !math.IsInf(m.Upper[0], 1) || !math.IsInf(m.Upper[1], 1) {
+		t.Errorf("expected infinite column bounds, got %v and %v", m.Lower, m.Upper)
+	}
+}
+
+func TestLPSyntaxError(t *testing.T) {
+	model := strings.Replace(lpSample, "LIM2: X1 >= 1", "LIM2: X1 X2 >= 1", 1)
+	_, err := modelio.ReadLP(strings.NewReader(model))
+	var syntaxErr *modelio.SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 6 {
+		t.Errorf("expected a syntax error on line 6, got %v", err)
+	}
+
+	if _, err := modelio.ReadLP(strings.NewReader("x + y <= 2\n")); !errors.As(err, &syntaxErr) || syntaxErr.Line != 1 {
+		t.Errorf("expected a syntax error on line 1 for a missing objective, got %v", err)
+	}
+
+	model = strings.Replace(lpSample, "End\n", "SOS\n s1: S1:: X1:1 X2:2\nEnd\n", 1)
+	if _, err := modelio.ReadLP(strings.NewReader(model)); !errors.As(err, &syntaxErr) || !strings.Contains(syntaxErr.Msg, "special ordered sets") {
+		t.Errorf("expected a syntax error for an SOS section, got %v", err)
+	}
+}
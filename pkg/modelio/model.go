@@ -0,0 +1,261 @@
+// Package modelio reads and writes optimization models in the MPS (fixed and free) and
+// CPLEX LP file formats.
+package modelio
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+	"github.com/tab58/go-optimize/pkg/milp"
+	"github.com/tab58/go-optimize/pkg/qp"
+)
+
+// Model is a linear or quadratic program read from or written to a file:
+//
+//	minimize (or maximize) c^T * x + 1/2 * x^T * Q * x + offset
+//	subject to rowLower <= A * x <= rowUpper, lower <= x <= upper
+//
+// with some columns required to be integer. Q is symmetric with only its upper triangle
+// stored, and is empty for linear programs. Infinite bounds are +-math.Inf.
+type Model struct {
+	Name            string
+	ObjectiveName   string
+	Maximize        bool
+	ObjectiveOffset float64
+	Columns         []string
+	Rows            []string
+	C               linalg.Vector
+	Q               linalg.SparseMatrix
+	A               linalg.SparseMatrix
+	RowLower        linalg.Vector
+	RowUpper        linalg.Vector
+	Lower           linalg.Vector
+	Upper           linalg.Vector
+	Integer         []bool
+}
+
+// SyntaxError is an error in a model file.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+func syntaxError(line int, format string, args ...any) error {
+	return &SyntaxError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+var INFINITY = 1e30 // bounds and right-hand sides at least this large are read as infinite
+
+// infinite returns v, or the infinity of its sign if |v| >= INFINITY.
+func infinite(v float64) float64 {
+	switch {
+	case v >= INFINITY:
+		return math.Inf(1)
+	case v <= -INFINITY:
+		return math.Inf(-1)
+	}
+	return v
+}
+
+// modelBuilder accumulates a model while a file is read.
+type modelBuilder struct {
+	model    *Model
+	colIndex map[string]int
+	rowIndex map[string]int
+	a        map[[2]int]float64
+	q        map[[2]int]float64
+}
+
+func newModelBuilder() *modelBuilder {
+	return &modelBuilder{
+		model:    &Model{ObjectiveName: "obj"},
+		colIndex: make(map[string]int),
+		rowIndex: make(map[string]int),
+		a:        make(map[[2]int]float64),
+		q:        make(map[[2]int]float64),
+	}
+}
+
+// column returns the index of the named column, adding it with the default bounds
+// [0, inf) if it is new.
+func (b *modelBuilder) column(name string) int {
+	if j, ok := b.colIndex[name]; ok {
+		return j
+	}
+	m := b.model
+	j := len(m.Columns)
+	b.colIndex[name] = j
+	m.Columns = append(m.Columns, name)
+	m.C = append(m.C, 0)
+	m.Lower = append(m.Lower, 0)
+	m.Upper = append(m.Upper, math.Inf(1))
+	m.Integer = append(m.Integer, false)
+	return j
+}
+
+// row returns the index of the named row, adding it as a free row if it is new.
+func (b *modelBuilder) row(name string) int {
+	if i, ok := b.rowIndex[name]; ok {
+		return i
+	}
+	m := b.model
+	i := len(m.Rows)
+	b.rowIndex[name] = i
+	m.Rows = append(m.Rows, name)
+	m.RowLower = append(m.RowLower, math.Inf(-1))
+	m.RowUpper = append(m.RowUpper, math.Inf(1))
+	return i
+}
+
+// addQuadratic adds value to Q_ij and Q_ji.
+func (b *modelBuilder) addQuadratic(i, j int, value float64) {
+	if i > j {
+		i, j = j, i
+	}
+	b.q[[2]int{i, j}] += value
+}
+
+func (b *modelBuilder) build() *Model {
+	m := b.model
+	n := len(m.Columns)
+	t := linalg.NewTripletMatrix(len(m.Rows), n)
+	for k, v := range b.a {
+		if v != 0 {
+			t.Append(k[0], k[1], v)
+		}
+	}
+	m.A = t.ToCSC()
+	q := linalg.NewTripletMatrix(n, n)
+	for k, v := range b.q {
+		if v != 0 {
+			q.Append(k[0], k[1], v)
+		}
+	}
+	m.Q = q.ToCSC()
+	if m.C == nil {
+		m.C = linalg.NewVector(0)
+	}
+	return m
+}
+
+// IsQuadratic reports whether the objective has quadratic terms.
+func (m *Model) IsQuadratic() bool {
+	return m.Q.NNZ() > 0
+}
+
+// LP returns the model as a linear program. The objective of a maximization model is
+// negated; the quadratic terms and integrality are ignored.
+func (m *Model) LP() *lp.Problem {
+	c := linalg.NewVector(len(m.C))
+	copy(c, m.C)
+	if m.Maximize {
+		for j := range c {
+			c[j] = -c[j]
+		}
+	}
+	return &lp.Problem{
+		C:        c,
+		A:        m.A,
+		RowLower: m.RowLower,
+		RowUpper: m.RowUpper,
+		Lower:    m.Lower,
+		Upper:    m.Upper,
+	}
+}
+
+// MILP returns the model as a mixed-integer linear program. The objective of a
+// maximization model is negated; the quadratic terms are ignored.
+func (m *Model) MILP() *milp.Problem {
+	p := m.LP()
+	integer := make([]bool, len(m.C))
+	copy(integer, m.Integer)
+	return &milp.Problem{
+		C:        p.C,
+		A:        p.A,
+		RowLower: p.RowLower,
+		RowUpper: p.RowUpper,
+		Lower:    p.Lower,
+		Upper:    p.Upper,
+		Integer:  integer,
+	}
+}
+
+// QP returns the model as a quadratic program. Rows with equal bounds become equality
+// constraints and every finite row or column bound an inequality. The objective of a
+// maximization model is negated; integrality is ignored.
+func (m *Model) QP() *qp.SparseProblem {
+	n := len(m.Columns)
+	sign := 1.0
+	if m.Maximize {
+		sign = -1
+	}
+	c := linalg.NewVector(n)
+	for j := range c {
+		c[j] = sign * m.C[j]
+	}
+	q := linalg.NewTripletMatrix(n, n)
+	for j := 0; j < n; j++ {
+		rows, values := m.Q.Column(j)
+		for k, i := range rows {
+			q.Append(i, j, sign*values[k])
+		}
+	}
+
+	// constraint rows s * a^T * x <= s * r, collected before the matrices are sized
+	type row struct {
+		cols   []int
+		values []float64
+		s, r   float64
+	}
+	var ineq, eq []row
+	At := m.A.Transpose()
+	for i := range m.Rows {
+		cols, values := At.Column(i)
+		l, u := m.RowLower[i], m.RowUpper[i]
+		if l == u {
+			eq = append(eq, row{cols, values, 1, u})
+			continue
+		}
+		if !math.IsInf(u, 1) {
+			ineq = append(ineq, row{cols, values, 1, u})
+		}
+		if !math.IsInf(l, -1) {
+			ineq = append(ineq, row{cols, values, -1, l})
+		}
+	}
+	for j := 0; j < n; j++ {
+		if !math.IsInf(m.Upper[j], 1) {
+			ineq = append(ineq, row{[]int{j}, []float64{1}, 1, m.Upper[j]})
+		}
+		if !math.IsInf(m.Lower[j], -1) {
+			ineq = append(ineq, row{[]int{j}, []float64{1}, -1, m.Lower[j]})
+		}
+	}
+	stack := func(rows []row) (linalg.SparseMatrix, linalg.Vector) {
+		t := linalg.NewTripletMatrix(len(rows), n)
+		rhs := linalg.NewVector(len(rows))
+		for i, r := range rows {
+			for k, j := range r.cols {
+				t.Append(i, j, r.s*r.values[k])
+			}
+			rhs[i] = r.s * r.r
+		}
+		return t.ToCSC(), rhs
+	}
+	A, b := stack(ineq)
+	E, d := stack(eq)
+	return &qp.SparseProblem{
+		Q: q.ToCSC(),
+		C: c,
+		A: A,
+		B: b,
+		E: E,
+		D: d,
+	}
+}
@@ -0,0 +1,543 @@
+package modelio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type MPSFormat int
+
+const (
+	// FixedMPS places the fields of each line in fixed columns, so names may contain
+	// spaces but are limited to 8 characters.
+	FixedMPS MPSFormat = iota
+	// FreeMPS separates the fields by whitespace.
+	FreeMPS
+)
+
+// mpsRow is the type, right-hand side and range of a row while an MPS file is read.
+type mpsRow struct {
+	kind byte
+	rhs  float64
+	rng  float64
+	set  bool // rng was given
+}
+
+// fixedFields returns the six fields of a fixed MPS data line, which start in columns
+// 2, 5, 15, 25, 40 and 50.
+func fixedFields(line string) [6]string {
+	bounds := [6][2]int{{1, 3}, {4, 12}, {14, 22}, {24, 36}, {39, 47}, {49, 61}}
+	var fields [6]string
+	for k, b := range bounds {
+		if b[0] >= len(line) {
+			break
+		}
+		end := min(b[1], len(line))
+		fields[k] = strings.TrimSpace(line[b[0]:end])
+	}
+	return fields
+}
+
+// ReadMPS reads a model in the given MPS format. The first N row is the objective and
+// other N rows are dropped. Integer columns are marked with INTORG and INTEND markers
+// or the BV, LI and UI bound types. The quadratic objective is read from a QUADOBJ
+// section (lower triangle) or a QMATRIX section (full matrix).
+func ReadMPS(r io.Reader, format MPSFormat) (*Model, error) {
+	b := newModelBuilder()
+	m := b.model
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []mpsRow
+	dropped := make(map[string]bool)
+	objective := ""
+	section := ""
+	integer := false
+	explicitLower := make(map[int]bool)
+	line := 0
+	ended := false
+
+	number := func(s string) (float64, error) {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, syntaxError(line, "invalid number %q", s)
+		}
+		return v, nil
+	}
+
+	// data returns the fields of a data line as (type, name1, name2, value1, name3, value2).
+	data := func(text string) ([6]string, error) {
+		if format == FixedMPS {
+			return fixedFields(text), nil
+		}
+		var f [6]string
+		tokens := strings.Fields(text)
+		switch section {
+		case "ROWS":
+			if len(tokens) != 2 {
+				return f, syntaxError(line, "expected a row type and name")
+			}
+			f[0], f[1] = tokens[0], tokens[1]
+		case "COLUMNS", "RHS", "RANGES":
+			// the set name is optional in the RHS and RANGES sections
+			if section != "COLUMNS" && len(tokens)%2 == 0 {
+				tokens = append([]string{""}, tokens...)
+			}
+			if len(tokens) != 3 && len(tokens) != 5 {
+				return f, syntaxError(line, "expected a name and one or two name-value pairs")
+			}
+			copy(f[1:], tokens)
+		case "BOUNDS":
+			if len(tokens) < 2 {
+				return f, syntaxError(line, "expected a bound type and column")
+			}
+			f[0] = tokens[0]
+			rest := tokens[1:]
+			needsValue := f[0] != "FR" && f[0] != "MI" && f[0] != "PL" && f[0] != "BV"
+			if (needsValue && len(rest) == 2) || (!needsValue && len(rest) == 1) {
+				rest = append([]string{""}, rest...)
+			}
+			if len(rest) > 3 {
+				return f, syntaxError(line, "too many fields in bound")
+			}
+			copy(f[1:], rest)
+		case "QUADOBJ", "QMATRIX":
+			if len(tokens) != 3 {
+				return f, syntaxError(line, "expected two columns and a value")
+			}
+			copy(f[1:], tokens)
+		default:
+			copy(f[:], tokens)
+		}
+		return f, nil
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(text) == "" || text[0] == '*' {
+			continue
+		}
+		if ended {
+			return nil, syntaxError(line, "data after ENDATA")
+		}
+
+		// section headers start in the first column
+		if text[0] != ' ' && text[0] != '\t' {
+			tokens := strings.Fields(text)
+			section = strings.ToUpper(tokens[0])
+			switch section {
+			case "NAME":
+				m.Name = strings.TrimSpace(text[len(tokens[0]):])
+			case "OBJSENSE", "OBJSENCE":
+				section = "OBJSENSE"
+				if len(tokens) > 1 {
+					if err := setSense(m, tokens[1], line); err != nil {
+						return nil, err
+					}
+				}
+			case "ROWS", "COLUMNS", "RHS", "RANGES", "BOUNDS", "QUADOBJ", "QMATRIX":
+			case "ENDATA":
+				ended = true
+			default:
+				return nil, syntaxError(line, "unknown section %q", tokens[0])
+			}
+			continue
+		}
+
+		if section == "OBJSENSE" {
+			if err := setSense(m, strings.TrimSpace(text), line); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// integer markers in the COLUMNS section
+		if section == "COLUMNS" && strings.Contains(text, "'MARKER'") {
+			switch {
+			case strings.Contains(text, "'INTORG'"):
+				integer = true
+			case strings.Contains(text, "'INTEND'"):
+				integer = false
+			default:
+				return nil, syntaxError(line, "unknown marker")
+			}
+			continue
+		}
+
+		f, err := data(text)
+		if err != nil {
+			return nil, err
+		}
+
+		switch section {
+		case "ROWS":
+			kind := strings.ToUpper(f[0])
+			if f[1] == "" || len(kind) != 1 || !strings.Contains("NELG", kind) {
+				return nil, syntaxError(line, "invalid row type %q", f[0])
+			}
+			if kind == "N" {
+				if objective == "" {
+					objective = f[1]
+					m.ObjectiveName = f[1]
+				} else {
+					dropped[f[1]] = true
+				}
+				continue
+			}
+			if _, ok := b.rowIndex[f[1]]; ok {
+				return nil, syntaxError(line, "duplicate row %q", f[1])
+			}
+			b.row(f[1])
+			rows = append(rows, mpsRow{kind: kind[0]})
+
+		case "COLUMNS":
+			if f[1] == "" {
+				return nil, syntaxError(line, "missing column name")
+			}
+			j := b.column(f[1])
+			m.Integer[j] = m.Integer[j] || integer
+			for _, pair := range [][2]string{{f[2], f[3]}, {f[4], f[5]}} {
+				if pair[0] == "" {
+					continue
+				}
+				v, err := number(pair[1])
+				if err != nil {
+					return nil, err
+				}
+				switch i, ok := b.rowIndex[pair[0]]; {
+				case pair[0] == objective:
+					m.C[j] += v
+				case ok:
+					b.a[[2]int{i, j}] += v
+				case !dropped[pair[0]]:
+					return nil, syntaxError(line, "unknown row %q", pair[0])
+				}
+			}
+
+		case "RHS", "RANGES":
+			for _, pair := range [][2]string{{f[2], f[3]}, {f[4], f[5]}} {
+				if pair[0] == "" {
+					continue
+				}
+				v, err := number(pair[1])
+				if err != nil {
+					return nil, err
+				}
+				v = infinite(v)
+				i, ok := b.rowIndex[pair[0]]
+				switch {
+				case pair[0] == objective && section == "RHS":
+					m.ObjectiveOffset = -v
+				case ok && section == "RHS":
+					rows[i].rhs = v
+				case ok:
+					rows[i].rng, rows[i].set = v, true
+				case !dropped[pair[0]]:
+					return nil, syntaxError(line, "unknown row %q", pair[0])
+				}
+			}
+
+		case "BOUNDS":
+			kind := strings.ToUpper(f[0])
+			j, ok := b.colIndex[f[2]]
+			if !ok {
+				return nil, syntaxError(line, "unknown column %q", f[2])
+			}
+			v := 0.0
+			if f[3] != "" {
+				if v, err = number(f[3]); err != nil {
+					return nil, err
+				}
+				v = infinite(v)
+			}
+			switch kind {
+			case "UP", "UI":
+				m.Upper[j] = v
+				if v < 0 && m.Lower[j] == 0 && !explicitLower[j] {
+					m.Lower[j] = math.Inf(-1)
+				}
+			case "LO", "LI":
+				m.Lower[j] = v
+				explicitLower[j] = true
+			case "FX":
+				m.Lower[j], m.Upper[j] = v, v
+			case "FR":
+				m.Lower[j], m.Upper[j] = math.Inf(-1), math.Inf(1)
+			case "MI":
+				m.Lower[j] = math.Inf(-1)
+			case "PL":
+				m.Upper[j] = math.Inf(1)
+			case "BV":
+				m.Lower[j], m.Upper[j] = 0, 1
+			default:
+				return nil, syntaxError(line, "unsupported bound type %q", f[0])
+			}
+			if kind == "BV" || kind == "LI" || kind == "UI" {
+				m.Integer[j] = true
+			}
+
+		case "QUADOBJ", "QMATRIX":
+			i, ok1 := b.colIndex[f[1]]
+			j, ok2 := b.colIndex[f[2]]
+			if !ok1 || !ok2 {
+				return nil, syntaxError(line, "unknown column in %q %q", f[1], f[2])
+			}
+			v, err := number(f[3])
+			if err != nil {
+				return nil, err
+			}
+			if section == "QMATRIX" {
+				// both triangles are listed
+				if i <= j {
+					b.addQuadratic(i, j, v)
+				}
+			} else {
+				b.addQuadratic(i, j, v)
+			}
+
+		default:
+			return nil, syntaxError(line, "data outside of a section")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, syntaxError(line, "missing ENDATA")
+	}
+
+	for i, r := range rows {
+		rhs, rng := r.rhs, math.Abs(r.rng)
+		lower, upper := math.Inf(-1), math.Inf(1)
+		switch r.kind {
+		case 'E':
+			lower, upper = rhs, rhs
+			if r.set && r.rng >= 0 {
+				upper = rhs + rng
+			} else if r.set {
+				lower = rhs - rng
+			}
+		case 'L':
+			upper = rhs
+			if r.set {
+				lower = rhs - rng
+			}
+		case 'G':
+			lower = rhs
+			if r.set {
+				upper = rhs + rng
+			}
+		}
+		m.RowLower[i], m.RowUpper[i] = lower, upper
+	}
+	return b.build(), nil
+}
+
+func setSense(m *Model, sense string, line int) error {
+	switch strings.ToUpper(sense) {
+	case "MAX", "MAXIMIZE":
+		m.Maximize = true
+	case "MIN", "MINIMIZE":
+		m.Maximize = false
+	default:
+		return syntaxError(line, "invalid objective sense %q", sense)
+	}
+	return nil
+}
+
+// WriteMPS writes a model in the given MPS format. Free rows are written as N rows and
+// are dropped when the file is read back. Names are generated for unnamed rows and
+// columns.
+func WriteMPS(w io.Writer, m *Model, format MPSFormat) error {
+	cols, rows := names(m)
+	objective := m.ObjectiveName
+	if objective == "" {
+		objective = "obj"
+	}
+	all := append(append([]string{objective}, cols...), rows...)
+	for _, name := range all {
+		if format == FixedMPS && len(name) > 8 {
+			return fmt.Errorf("name %q is longer than 8 characters", name)
+		}
+		if format == FreeMPS && strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("name %q contains whitespace", name)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	value := func(v float64) string {
+		if format == FixedMPS {
+			return fixedValue(v)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	field := func(fields ...string) {
+		if format == FixedMPS {
+			var f [6]string
+			copy(f[:], fields)
+			line := fmt.Sprintf(" %-2s %-8s  %-8s  %12s   %-8s  %12s", f[0], f[1], f[2], f[3], f[4], f[5])
+			fmt.Fprintln(bw, strings.TrimRight(line, " "))
+			return
+		}
+		var nonEmpty []string
+		for _, s := range fields {
+			if s != "" {
+				nonEmpty = append(nonEmpty, s)
+			}
+		}
+		fmt.Fprintln(bw, " "+strings.Join(nonEmpty, " "))
+	}
+
+	fmt.Fprintf(bw, "NAME          %s\n", m.Name)
+	if m.Maximize {
+		fmt.Fprintln(bw, "OBJSENSE")
+		fmt.Fprintln(bw, "    MAX")
+	}
+
+	fmt.Fprintln(bw, "ROWS")
+	field("N", objective)
+	kinds := make([]string, len(rows))
+	rhs := make([]float64, len(rows))
+	ranges := make([]float64, len(rows))
+	for i := range rows {
+		l, u := m.RowLower[i], m.RowUpper[i]
+		switch {
+		case l == u:
+			kinds[i], rhs[i] = "E", l
+		case math.IsInf(l, -1) && math.IsInf(u, 1):
+			kinds[i] = "N"
+		case math.IsInf(l, -1):
+			kinds[i], rhs[i] = "L", u
+		case math.IsInf(u, 1):
+			kinds[i], rhs[i] = "G", l
+		default:
+			kinds[i], rhs[i], ranges[i] = "G", l, u-l
+		}
+		field(kinds[i], rows[i])
+	}
+
+	fmt.Fprintln(bw, "COLUMNS")
+	At := m.A
+	integer := false
+	for j := range cols {
+		isInteger := m.Integer != nil && m.Integer[j]
+		if isInteger != integer {
+			marker := "'INTORG'"
+			if !isInteger {
+				marker = "'INTEND'"
+			}
+			field("", "MARKER", "'MARKER'", "", marker)
+			integer = isInteger
+		}
+		if m.C[j] != 0 {
+			field("", cols[j], objective, value(m.C[j]))
+		}
+		rowIdx, values := At.Column(j)
+		for k, i := range rowIdx {
+			field("", cols[j], rows[i], value(values[k]))
+		}
+		if m.C[j] == 0 && len(rowIdx) == 0 {
+			// keep the column in the file
+			field("", cols[j], objective, value(0))
+		}
+	}
+	if integer {
+		field("", "MARKER", "'MARKER'", "", "'INTEND'")
+	}
+
+	fmt.Fprintln(bw, "RHS")
+	for i := range rows {
+		if rhs[i] != 0 && kinds[i] != "N" {
+			field("", "RHS", rows[i], value(rhs[i]))
+		}
+	}
+	if m.ObjectiveOffset != 0 {
+		field("", "RHS", objective, value(-m.ObjectiveOffset))
+	}
+
+	hasRanges := false
+	for i := range rows {
+		if ranges[i] != 0 {
+			if !hasRanges {
+				fmt.Fprintln(bw, "RANGES")
+				hasRanges = true
+			}
+			field("", "RNG", rows[i], value(ranges[i]))
+		}
+	}
+
+	fmt.Fprintln(bw, "BOUNDS")
+	for j := range cols {
+		l, u := m.Lower[j], m.Upper[j]
+		switch {
+		case l == u:
+			field("FX", "BND", cols[j], value(l))
+			continue
+		case math.IsInf(l, -1) && math.IsInf(u, 1):
+			field("FR", "BND", cols[j])
+			continue
+		case math.IsInf(l, -1):
+			field("MI", "BND", cols[j])
+		case l != 0 || u < 0:
+			field("LO", "BND", cols[j], value(l))
+		}
+		if !math.IsInf(u, 1) {
+			field("UP", "BND", cols[j], value(u))
+		}
+	}
+
+	if m.Q.NNZ() > 0 {
+		fmt.Fprintln(bw, "QUADOBJ")
+		for j := range cols {
+			rowIdx, values := m.Q.Column(j)
+			for k, i := range rowIdx {
+				if i <= j {
+					field("", cols[i], cols[j], value(values[k]))
+				}
+			}
+		}
+	}
+	fmt.Fprintln(bw, "ENDATA")
+	return bw.Flush()
+}
+
+// fixedValue formats v in at most 12 characters.
+func fixedValue(v float64) string {
+	for precision := -1; ; precision-- {
+		var s string
+		if precision == -1 {
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		} else {
+			s = strconv.FormatFloat(v, 'g', 12+precision, 64)
+		}
+		if len(s) <= 12 {
+			return s
+		}
+	}
+}
+
+// names returns the column and row names of a model, generating the missing ones.
+func names(m *Model) (cols, rows []string) {
+	cols = make([]string, len(m.C))
+	rows = make([]string, m.A.Rows())
+	for j := range cols {
+		if j < len(m.Columns) && m.Columns[j] != "" {
+			cols[j] = m.Columns[j]
+		} else {
+			cols[j] = "C" + strconv.Itoa(j+1)
+		}
+	}
+	for i := range rows {
+		if i < len(m.Rows) && m.Rows[i] != "" {
+			rows[i] = m.Rows[i]
+		} else {
+			rows[i] = "R" + strconv.Itoa(i+1)
+		}
+	}
+	return cols, rows
+}
@@ -0,0 +1,277 @@
+package modelio_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/lp"
+	"github.com/tab58/go-optimize/pkg/milp"
+	"github.com/tab58/go-optimize/pkg/modelio"
+	"github.com/tab58/go-optimize/pkg/qp"
+)
+
+// fixedSample is a maximization problem with ranged rows, a negative upper bound on a
+// column with a default lower bound, an objective constant and an integer column. Its
+// optimum is x = (4, -1, -2) with the objective 7.5.
+const fixedSample = `NAME          TESTLP
+* a comment
+OBJSENSE
+    MAX
+ROWS
+ N  COST
+ L  LIM1
+ G  LIM2
+ E  MYEQN
+COLUMNS
+    MARKER                 'MARKER'                 'INTORG'
+    X1        COST               1.0   LIM1               1.0
+    X1        LIM2               1.0
+    MARKER                 'MARKER'                 'INTEND'
+    X2        COST               2.0   LIM1               1.0
+    X2        MYEQN             -1.0
+    X3        COST              -1.0   MYEQN              1.0
+RHS
+    RHS       COST              -3.5
+    RHS       LIM1               4.0   LIM2               1.0
+    RHS       MYEQN             -1.0
+RANGES
+    RNG       LIM1               2.5   MYEQN              2.0
+BOUNDS
+ UP BND       X1                 4.0
+ MI BND       X2
+ UP BND       X2                 1.0
+ UP BND       X3                -2.0
+ENDATA
+`
+
+// sameModel fails the test if two models differ.
+func sameModel(t *testing.T, expected, actual *modelio.Model) {
+	t.Helper()
+	if expected.Name != actual.Name || expected.Maximize != actual.Maximize || expected.ObjectiveOffset != actual.ObjectiveOffset {
+		t.Fatalf("expected name %q, maximize %v and offset %v, got %q, %v and %v", expected.Name, expected.Maximize,
+			expected.ObjectiveOffset, actual.Name, actual.Maximize, actual.ObjectiveOffset)
+	}
+	if strings.Join(expected.Columns, " ") != strings.Join(actual.Columns, " ") ||
+		strings.Join(expected.Rows, " ") != strings.Join(actual.Rows, " ") {
+		t.Fatalf("expected columns %v and rows %v, got %v and %v", expected.Columns, expected.Rows, actual.Columns, actual.Rows)
+	}
+	vectors := [][2]linalg.Vector{
+		{expected.C, actual.C},
+		{expected.RowLower, actual.RowLower},
+		{expected.RowUpper, actual.RowUpper},
+		{expected.Lower, actual.Lower},
+		{expected.Upper, actual.Upper},
+	}
+	for _, v := range vectors {
+		for i := range v[0] {
+			if v[0][i] != v[1][i] {
+				t.Fatalf("expected %v, got %v", v[0], v[1])
+			}
+		}
+	}
+	for j := range expected.Integer {
+		if expected.Integer[j] != actual.Integer[j] {
+			t.Fatalf("expected integer columns %v, got %v", expected.Integer, actual.Integer)
+		}
+	}
+	for _, M := range [][2]linalg.SparseMatrix{{expected.A, actual.A}, {expected.Q, actual.Q}} {
+		if M[0].NNZ() != M[1].NNZ() {
+			t.Fatalf("expected %d nonzeros, got %d", M[0].NNZ(), M[1].NNZ())
+		}
+		for j := 0; j < M[0].Cols(); j++ {
+			rows, values := M[0].Column(j)
+			for k, i := range rows {
+				if M[1].Get(i, j) != values[k] {
+					t.Fatalf("expected %v at (%d, %d), got %v", values[k], i, j, M[1].Get(i, j))
+				}
+			}
+		}
+	}
+}
+
+func TestReadMPS(t *testing.T) {
+	m, err := modelio.ReadMPS(strings.NewReader(fixedSample), modelio.FixedMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inf := math.Inf(1)
+	if !m.Maximize || m.ObjectiveOffset != 3.5 || m.ObjectiveName != "COST" {
+		t.Errorf("expected a maximization of COST with offset 3.5, got %v, %q and %v", m.Maximize, m.ObjectiveName, m.ObjectiveOffset)
+	}
+	expected := map[string][2]linalg.Vector{
+		"row bounds":    {{1.5, 1, -1}, {4, inf, 1}},
+		"column bounds": {{0, -inf, -inf}, {4, 1, -2}},
+	}
+	actual := map[string][2]linalg.Vector{
+		"row bounds":    {m.RowLower, m.RowUpper},
+		"column bounds": {m.Lower, m.Upper},
+	}
+	for name, e := range expected {
+		for k := range e {
+			for i := range e[k] {
+				if actual[name][k][i] != e[k][i] {
+					t.Errorf("expected %s %v, got %v", name, e, actual[name])
+				}
+			}
+		}
+	}
+	if !m.Integer[0] || m.Integer[1] || m.Integer[2] {
+		t.Errorf("expected only X1 to be integer, got %v", m.Integer)
+	}
+
+	sol := lp.NewSimplexSolver().Solve(m.LP())
+	if sol.Status != lp.Optimal || math.Abs(-sol.Objective+m.ObjectiveOffset-7.5) > 1e-9 {
+		t.Errorf("expected the optimum 7.5, got %v with %v", sol.Status, -sol.Objective+m.ObjectiveOffset)
+	}
+	if sol := milp.NewBranchAndBoundSolver().Solve(m.MILP()); sol.Status != milp.Optimal || sol.X[0] != 4 {
+		t.Errorf("expected the integer optimum with X1 = 4, got %v with %v", sol.Status, sol.X)
+	}
+
+	// the same model in free format, with the optional set names left out
+	free := `NAME TESTLP
+OBJSENSE MAX
+ROWS
+ N COST
+ L LIM1
+ G LIM2
+ E MYEQN
+COLUMNS
+ MARKER 'MARKER' 'INTORG'
+ X1 COST 1.0 LIM1 1.0
+ X1 LIM2 1.0
+ MARKER 'MARKER' 'INTEND'
+ X2 COST 2.0 LIM1 1.0
+ X2 MYEQN -1.0
+ X3 COST -1.0 MYEQN 1.0
+RHS
+ COST -3.5
+ RHS LIM1 4.0 LIM2 1.0
+ MYEQN -1.0
+RANGES
+ RNG LIM1 2.5 MYEQN 2.0
+BOUNDS
+ UP X1 4.0
+ MI BND X2
+ UP BND X2 1.0
+ UP X3 -2.0
+ENDATA
+`
+	freeModel, err := modelio.ReadMPS(strings.NewReader(free), modelio.FreeMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameModel(t, m, freeModel)
+}
+
+func TestWriteMPS(t *testing.T) {
+	m, err := modelio.ReadMPS(strings.NewReader(fixedSample), modelio.FixedMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []modelio.MPSFormat{modelio.FixedMPS, modelio.FreeMPS} {
+		var buf bytes.Buffer
+		if err := modelio.WriteMPS(&buf, m, format); err != nil {
+			t.Fatal(err)
+		}
+		read, err := modelio.ReadMPS(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameModel(t, m, read)
+	}
+
+	m.Columns[0] = "LONGCOLUMN"
+	if err := modelio.WriteMPS(&bytes.Buffer{}, m, modelio.FixedMPS); err == nil {
+		t.Error("expected an error for a long name in fixed format")
+	}
+}
+
+func TestMPSQuadratic(t *testing.T) {
+	// minimize x^2 + y^2 - 2x - 4y subject to x + y <= 2, with the solution (0.5, 1.5)
+	model := `NAME QP
+ROWS
+ N obj
+ L c1
+COLUMNS
+ x obj -2 c1 1
+ y obj -4 c1 1
+RHS
+ rhs c1 2
+BOUNDS
+ FR bnd x
+ FR bnd y
+QUADOBJ
+ x x 2
+ y y 2
+ENDATA
+`
+	m, err := modelio.ReadMPS(strings.NewReader(model), modelio.FreeMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsQuadratic() {
+		t.Fatal("expected a quadratic model")
+	}
+	sol := qp.NewADMMSolver().Solve(m.QP())
+	if sol.Status != qp.Optimal || math.Abs(sol.X[0]-0.5) > 1e-4 || math.Abs(sol.X[1]-1.5) > 1e-4 {
+		t.Errorf("expected (0.5, 1.5), got %v with %v", sol.Status, sol.X)
+	}
+
+	var buf bytes.Buffer
+	if err := modelio.WriteMPS(&buf, m, modelio.FreeMPS); err != nil {
+		t.Fatal(err)
+	}
+	read, err := modelio.ReadMPS(&buf, modelio.FreeMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameModel(t, m, read)
+}
+
+func TestMPSInfinity(t *testing.T) {
+	const model = `NAME INF
+ROWS
+ N obj
+ L c1
+ G c2
+COLUMNS
+ x obj 1 c1 1
+ x c2 1
+ y obj 1 c1 1
+RHS
+ rhs c1 1e30 c2 -1e30
+BOUNDS
+ LO bnd x -1e30
+ UP bnd x 1e30
+ UP bnd y 2e30
+ENDATA
+`
+	m, err := modelio.ReadMPS(strings.NewReader(model), modelio.FreeMPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(m.RowUpper[0], 1) || !math.IsInf(m.RowLower[1], -1) {
+		t.Errorf("expected infinite row bounds, got %v and %v", m.RowUpper, m.RowLower)
+	}
+	if !math.IsInf(m.Lower[0], -1) || !math.IsInf(m.Upper[0], 1) || !math.IsInf(m.Upper[1], 1) {
+		t.Errorf("expected infinite column bounds, got %v and %v", m.Lower, m.Upper)
+	}
+}
+
+func TestMPSSyntaxError(t *testing.T) {
+	model := strings.Replace(fixedSample, "    X2        MYEQN             -1.0", "    X2        NOROW             -1.0", 1)
+	_, err := modelio.ReadMPS(strings.NewReader(model), modelio.FixedMPS)
+	var syntaxErr *modelio.SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 16 {
+		t.Errorf("expected a syntax error on line 16, got %v", err)
+	}
+
+	model = strings.Replace(fixedSample, "ENDATA\n", "", 1)
+	if _, err := modelio.ReadMPS(strings.NewReader(model), modelio.FixedMPS); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected a syntax error for a missing ENDATA, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package optim
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var AL_PENALTY_INIT = 10.0
+var AL_PENALTY_GROWTH = 10.0
+var AL_PENALTY_MAX = 1e12
+var AL_FEASIBILITY_DECREASE = 0.5 // required decrease in infeasibility before the penalty grows
+var AL_MAX_OUTER_ITERATIONS = 100
+
+var ErrInnerSolverBounds = errors.New("inner solver does not support bounds")
+
+// Solver minimizes an objective function, optionally subject to simple bounds.
+type Solver interface {
+	Solve(f ObjectiveFunc, x0 linalg.Vector, options ...func(*solveOptions)) *solution
+}
+
+// WithInnerSolver sets the solver used for the subproblems of constrained solvers.
+func WithInnerSolver(solver Solver) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.innerSolver = solver
+	}
+}
+
+// augmentedLagrangianSolver is the Powell-Hestenes-Rockafellar augmented Lagrangian method:
+//
+//	L_A(x) = f(x) + lambda^T * g(x) + rho/2 * |g(x)|^2
+//	       + 1/(2*rho) * sum(max(0, mu_i + rho * h_i(x))^2 - mu_i^2)
+//
+// Each outer iteration minimizes L_A with the inner solver and then updates the
+// multipliers and the penalty parameter rho.
+type augmentedLagrangianSolver struct{}
+
+func (s *augmentedLagrangianSolver) Solve(problem *ConstrainedProblem, x0 linalg.Vector, options ...func(*solveOptions)) *constrainedSolution {
+	opts := newSolveOptions(x0, options...)
+	if check := failedGradientCheck(problem.Objective, x0, opts); check != nil {
+		return &constrainedSolution{Result: vectorCopy(x0), Objective: problem.Objective(x0), GradientCheck: check}
+	}
+	return s.solve(problem, x0, opts)
+}
+
+func (s *augmentedLagrangianSolver) solve(problem *ConstrainedProblem, x0 linalg.Vector, opts *solveOptions) *constrainedSolution {
+	f := problem.Objective
+	evaluateGradient := opts.gradientFunc
+	tolerance := opts.tolerance
+	innerSolver := opts.innerSolver
+	if innerSolver == nil {
+		innerSolver = NewLBFGSBSolver()
+	}
+	bounds := Bounds{}
+	if opts.bounds != nil {
+		switch innerSolver.(type) {
+		case *lbfgsbSolver, *spgSolver:
+		default:
+			panic(ErrInnerSolverBounds)
+		}
+		bounds = *opts.bounds
+	}
+
+	n := x0.Len()
+	e := newConstraintEvaluator(problem, n)
+	me := e.me
+	mi := e.mi
+
+	x := linalg.NewVector(n)
+	gradF := linalg.NewVector(n)
+	gradL := linalg.NewVector(n)
+	g := linalg.NewVector(me)
+	h := linalg.NewVector(mi)
+	lambda := linalg.NewVector(me)
+	mu := linalg.NewVector(mi)
+	wg := linalg.NewVector(me)
+	wh := linalg.NewVector(mi)
+	Jg := linalg.NewDenseMatrix(me, n)
+	Jh := linalg.NewDenseMatrix(mi, n)
+	rho := AL_PENALTY_INIT
+
+	// shifted multiplier estimates used by the augmented Lagrangian
+	shifted := func(x linalg.Vector) {
+		e.equality(x, g)
+		e.inequality(x, h)
+		for i := range g {
+			wg[i] = lambda[i] + rho*g[i]
+		}
+		for i := range h {
+			wh[i] = math.Max(0, mu[i]+rho*h[i])
+		}
+	}
+
+	augmentedLagrangian := func(x linalg.Vector) float64 {
+		shifted(x)
+		sum := f(x)
+		for i := range g {
+			sum += lambda[i]*g[i] + 0.5*rho*g[i]*g[i]
+		}
+		for i := range h {
+			sum += (wh[i]*wh[i] - mu[i]*mu[i]) / (2 * rho)
+		}
+		return sum
+	}
+
+	augmentedLagrangianGradient := func(x linalg.Vector, _ ObjectiveFunc, grad linalg.Vector) float64 {
+		evaluateGradient(x, f, gradF)
+		shifted(x)
+		e.jacobians(x, Jg, Jh)
+		lagrangianGradient(gradF, Jg, wg, Jh, wh, grad)
+		return blas.NRM2(grad)
+	}
+
+	blas.COPY(x0, x)
+	bounds.Project(x)
+	e.equality(x, g)
+	e.inequality(x, h)
+	infeasibility := math.Inf(1)
+
+	iter := 0
+	converged := false
+	var stationarity, primal, complementarity float64
+	for !converged && iter < AL_MAX_OUTER_ITERATIONS {
+		inner := innerSolver.Solve(augmentedLagrangian, x,
+			WithTolerance(tolerance),
+			WithMaxIterations(opts.maxIterations),
+			WithGradientFunc(augmentedLagrangianGradient),
+			WithBounds(bounds),
+			WithMemory(opts.memory),
+		)
+		blas.COPY(inner.Result, x)
+
+		// measure infeasibility and complementarity with the current multipliers
+		shifted(x)
+		v := 0.0
+		for i := range g {
+			v = math.Max(v, math.Abs(g[i]))
+		}
+		for i := range h {
+			v = math.Max(v, math.Abs(math.Max(h[i], -mu[i]/rho)))
+		}
+
+		// first-order multiplier updates
+		blas.COPY(wg, lambda)
+		blas.COPY(wh, mu)
+		iter++
+
+		evaluateGradient(x, f, gradF)
+		e.jacobians(x, Jg, Jh)
+		lagrangianGradient(gradF, Jg, lambda, Jh, mu, gradL)
+		stationarity, primal, complementarity = kktResiduals(x, gradL, g, h, mu, bounds)
+		converged = inner.ValidSolution && primal <= tolerance && complementarity <= tolerance
+
+		// grow the penalty if the infeasibility did not decrease enough
+		if v > tolerance && v > AL_FEASIBILITY_DECREASE*infeasibility {
+			rho = math.Min(AL_PENALTY_GROWTH*rho, AL_PENALTY_MAX)
+		}
+		infeasibility = v
+	}
+
+	result := linalg.NewVector(n)
+	blas.COPY(x, result)
+	activeSet := make([]int, 0, mi)
+	for i := range mu {
+		if mu[i] > 0 {
+			activeSet = append(activeSet, i)
+		}
+	}
+
+	return &constrainedSolution{
+		ValidSolution:         converged,
+		Result:                result,
+		Iterations:            iter,
+		Objective:             f(x),
+		EqualityMultipliers:   lambda,
+		InequalityMultipliers: mu,
+		ActiveSet:             activeSet,
+		Stationarity:          stationarity,
+		PrimalInfeasibility:   primal,
+		Complementarity:       complementarity,
+	}
+}
+
+// NewAugmentedLagrangianSolver returns an augmented Lagrangian solver for problems with
+// general equality and inequality constraints. The subproblems are solved by L-BFGS-B
+// unless another solver is given with WithInnerSolver; with bounds that solver must be
+// L-BFGS-B or SPG, which keep the iterates in the box, or Solve panics with
+// ErrInnerSolverBounds.
+func NewAugmentedLagrangianSolver() *augmentedLagrangianSolver {
+	return &augmentedLagrangianSolver{}
+}
@@ -0,0 +1,112 @@
+package optim_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func TestAugmentedLagrangianSolver_Equality(t *testing.T) {
+	solver := optim.NewAugmentedLagrangianSolver()
+
+	// minimize (x-1)^2 + (y-2)^2 subject to x + y = 1
+	problem := &optim.ConstrainedProblem{
+		Objective: func(X linalg.Vector) float64 {
+			return (X[0]-1)*(X[0]-1) + (X[1]-2)*(X[1]-2)
+		},
+		Equality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = X[0] + X[1] - 1
+		},
+		NumEquality: 1,
+	}
+
+	x0 := linalg.NewVector(2)
+	solution := solver.Solve(problem, x0, optim.WithTolerance(1e-8))
+
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	if math.Abs(solution.Result[0]) > 1e-6 || math.Abs(solution.Result[1]-1) > 1e-6 {
+		t.Errorf("Expected [0 1], got %v", solution.Result)
+	}
+	if math.Abs(solution.EqualityMultipliers[0]-2) > 1e-5 {
+		t.Errorf("Expected multiplier 2, got %v", solution.EqualityMultipliers)
+	}
+}
+
+func TestAugmentedLagrangianSolver_InnerSolverBounds(t *testing.T) {
+	// minimize (x-1)^2 + (y-2)^2 subject to x + y = 1 and x >= 0.5
+	problem := &optim.ConstrainedProblem{
+		Objective: func(X linalg.Vector) float64 {
+			return (X[0]-1)*(X[0]-1) + (X[1]-2)*(X[1]-2)
+		},
+		Equality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = X[0] + X[1] - 1
+		},
+		NumEquality: 1,
+	}
+	bounds := optim.Bounds{Lower: linalg.Vector{0.5, math.Inf(-1)}}
+
+	solution := optim.NewAugmentedLagrangianSolver().Solve(problem, linalg.NewVector(2),
+		optim.WithTolerance(1e-8), optim.WithBounds(bounds), optim.WithInnerSolver(optim.NewSPGSolver()))
+	if !solution.ValidSolution || math.Abs(solution.Result[0]-0.5) > 1e-6 || math.Abs(solution.Result[1]-0.5) > 1e-6 {
+		t.Errorf("Expected [0.5 0.5], got %+v", solution)
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, optim.ErrInnerSolverBounds) {
+			t.Errorf("expected ErrInnerSolverBounds, got %v", err)
+		}
+	}()
+	optim.NewAugmentedLagrangianSolver().Solve(problem, linalg.NewVector(2),
+		optim.WithBounds(bounds), optim.WithInnerSolver(optim.NewQuasiNewtonSolver()))
+	t.Errorf("expected an inner solver without bounds to panic")
+}
+
+// HS071 from the Hock-Schittkowski test set.
+func TestAugmentedLagrangianSolver_HS071(t *testing.T) {
+	solver := optim.NewAugmentedLagrangianSolver()
+
+	problem := &optim.ConstrainedProblem{
+		Objective: func(X linalg.Vector) float64 {
+			return X[0]*X[3]*(X[0]+X[1]+X[2]) + X[2]
+		},
+		Equality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = X[0]*X[0] + X[1]*X[1] + X[2]*X[2] + X[3]*X[3] - 40
+		},
+		NumEquality: 1,
+		Inequality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = 25 - X[0]*X[1]*X[2]*X[3]
+		},
+		NumInequality: 1,
+	}
+
+	x0 := linalg.Vector{1, 5, 5, 1}
+	solution := solver.Solve(problem, x0,
+		optim.WithTolerance(1e-7),
+		optim.WithBounds(optim.Bounds{
+			Lower: linalg.Vector{1, 1, 1, 1},
+			Upper: linalg.Vector{5, 5, 5, 5},
+		}),
+	)
+
+	expected := linalg.Vector{1, 4.74299963, 3.82114998, 1.37940829}
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	for i := range expected {
+		if math.Abs(solution.Result[i]-expected[i]) > 1e-4 {
+			t.Errorf("Expected %v, got %v", expected, solution.Result)
+			break
+		}
+	}
+	if math.Abs(solution.Objective-17.0140173) > 1e-5 {
+		t.Errorf("Expected objective 17.0140173, got %v", solution.Objective)
+	}
+	if solution.InequalityMultipliers[0] <= 0 {
+		t.Errorf("Expected a positive multiplier on the active inequality, got %v", solution.InequalityMultipliers)
+	}
+}
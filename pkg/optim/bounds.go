@@ -0,0 +1,60 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// Bounds are the lower and upper limits on the variables of a problem.
+//
+// Use math.Inf(-1) and math.Inf(1) for variables that are unbounded below or above.
+// A nil Lower or Upper vector means every variable is unbounded on that side.
+type Bounds struct {
+	Lower linalg.Vector
+	Upper linalg.Vector
+}
+
+// lower returns the lower bound on variable i.
+func (b Bounds) lower(i int) float64 {
+	if b.Lower == nil {
+		return math.Inf(-1)
+	}
+	return b.Lower[i]
+}
+
+// upper returns the upper bound on variable i.
+func (b Bounds) upper(i int) float64 {
+	if b.Upper == nil {
+		return math.Inf(1)
+	}
+	return b.Upper[i]
+}
+
+// check panics if the bounds do not match a problem of size n.
+func (b Bounds) check(n int) {
+	if b.Lower != nil && b.Lower.Len() != n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if b.Upper != nil && b.Upper.Len() != n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+}
+
+// Project clamps x onto the box defined by the bounds.
+func (b Bounds) Project(x linalg.Vector) {
+	for i := range x {
+		x[i] = math.Max(b.lower(i), math.Min(x[i], b.upper(i)))
+	}
+}
+
+// projectedGradientNorm returns the infinity norm of P(x - g) - x, the first-order
+// optimality measure for a box-constrained problem.
+func (b Bounds) projectedGradientNorm(x linalg.Vector, g linalg.Vector) float64 {
+	nrm := 0.0
+	for i := range x {
+		p := math.Max(b.lower(i), math.Min(x[i]-g[i], b.upper(i)))
+		nrm = math.Max(nrm, math.Abs(p-x[i]))
+	}
+	return nrm
+}
@@ -0,0 +1,235 @@
+package optim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var CHECK_TOLERANCE = 1e-6 // relative error above which a derivative entry is suspicious
+var CHECK_STEP = 1e-2      // initial Ridders step of the checks relative to max(|x_i|, typ_i)
+
+var ErrGradientCheck = errors.New("gradient does not match finite differences")
+
+// gradientCheck is the comparison of a gradient with finite differences.
+type gradientCheck struct {
+	Passed         bool
+	MaxError       float64       // largest relative error
+	Analytic       linalg.Vector // the gradient being checked
+	Numeric        linalg.Vector // Ridders' finite-difference gradient
+	RelativeErrors linalg.Vector // |analytic - numeric| / max(|analytic|, |numeric|, uncertainty of numeric)
+	Suspicious     []int         // components whose error exceeds CHECK_TOLERANCE and the difference uncertainty
+}
+
+// matrixCheck is the comparison of a Jacobian or Hessian with finite differences.
+type matrixCheck struct {
+	Passed         bool
+	MaxError       float64       // largest relative error
+	Analytic       linalg.Matrix // the matrix being checked
+	Numeric        linalg.Matrix // Ridders' finite-difference matrix
+	RelativeErrors linalg.Matrix // |analytic - numeric| / max(|analytic|, |numeric|, uncertainty of numeric)
+	Suspicious     [][2]int      // rows and columns of the entries that fail as in gradientCheck
+}
+
+// compareEntry returns the relative error of an analytic derivative against a numeric one
+// with the estimated absolute error e, taken at step h of a function with value fx, and
+// whether the entry is suspicious: its relative error exceeds CHECK_TOLERANCE and its
+// difference is well beyond e. The error is relative to the larger derivative, or to the
+// uncertainty of the numeric one, max(e, eps * |fx| / h), when both are smaller.
+func compareEntry(analytic, numeric, e, fx, h float64) (float64, bool) {
+	d := math.Abs(analytic - numeric)
+	if math.IsNaN(d) {
+		return math.Inf(1), true
+	}
+	if d == 0 {
+		return 0, false
+	}
+	floor := math.Max(e, epsilon*math.Abs(fx)/h)
+	rel := d / math.Max(floor, math.Max(math.Abs(analytic), math.Abs(numeric)))
+	return rel, rel > CHECK_TOLERANCE && d > 10*e
+}
+
+// checkSteps returns the initial Ridders step of each coordinate of a check at x.
+func checkSteps(x, typical linalg.Vector) linalg.Vector {
+	h := linalg.NewVector(x.Len())
+	for i := range h {
+		h[i] = CHECK_STEP * scale(x, typical, i)
+	}
+	return h
+}
+
+// CheckGradient compares the gradient of f computed by grad at x with Ridders' accurate
+// finite differences, to catch mistakes in hand-written gradients before they spoil a
+// solve. The differences start from the steps CHECK_STEP * max(|x_i|, typ_i), where the
+// typical magnitudes typ may be nil, in which case they are all 1. An entry is
+// suspicious when its relative error is above CHECK_TOLERANCE and more than the
+// uncertainty of the differences, and the check passes if none are.
+func CheckGradient(f ObjectiveFunc, grad gradientFunc, x, typical linalg.Vector) *gradientCheck {
+	n := x.Len()
+	check := &gradientCheck{
+		Passed:         true,
+		Analytic:       linalg.NewVector(n),
+		Numeric:        linalg.NewVector(n),
+		RelativeErrors: linalg.NewVector(n),
+	}
+	xc := linalg.NewVector(n)
+	blas.COPY(x, xc)
+	grad(xc, f, check.Analytic)
+	fx := f(xc)
+
+	h := checkSteps(x, typical)
+	e := linalg.NewVector(n)
+	riddersGradient(f, xc, func(i int) float64 { return h[i] }, check.Numeric, e)
+	for i := 0; i < n; i++ {
+		rel, suspicious := compareEntry(check.Analytic[i], check.Numeric[i], e[i], fx, h[i])
+		check.RelativeErrors[i] = rel
+		check.MaxError = math.Max(check.MaxError, rel)
+		if suspicious {
+			check.Suspicious = append(check.Suspicious, i)
+			check.Passed = false
+		}
+	}
+	return check
+}
+
+func (c *gradientCheck) String() string {
+	var b strings.Builder
+	verdict := "passed"
+	if !c.Passed {
+		verdict = "FAILED"
+	}
+	fmt.Fprintf(&b, "gradient check %s, largest relative error %.3g\n", verdict, c.MaxError)
+	fmt.Fprintf(&b, "%6s %24s %24s %10s\n", "i", "analytic", "numeric", "error")
+	suspicious := map[int]bool{}
+	for _, i := range c.Suspicious {
+		suspicious[i] = true
+	}
+	for i := range c.Analytic {
+		mark := ""
+		if suspicious[i] {
+			mark = " <-"
+		}
+		fmt.Fprintf(&b, "%6d %24.16g %24.16g %10.3g%s\n", i, c.Analytic[i], c.Numeric[i], c.RelativeErrors[i], mark)
+	}
+	return b.String()
+}
+
+// Err returns nil if the check passed and otherwise an error wrapping ErrGradientCheck
+// that lists the suspicious components.
+func (c *gradientCheck) Err() error {
+	if c.Passed {
+		return nil
+	}
+	return fmt.Errorf("%w in components %v", ErrGradientCheck, c.Suspicious)
+}
+
+// newMatrixCheck returns a passing check of an m x n matrix.
+func newMatrixCheck(m, n int) *matrixCheck {
+	return &matrixCheck{
+		Passed:         true,
+		Analytic:       linalg.NewDenseMatrix(m, n),
+		Numeric:        linalg.NewDenseMatrix(m, n),
+		RelativeErrors: linalg.NewDenseMatrix(m, n),
+	}
+}
+
+// compareRow compares row i of the analytic matrix with the numeric row and its errors,
+// differenced at the steps h from the value fx.
+func (c *matrixCheck) compareRow(i int, numeric, e linalg.Vector, fx float64, h linalg.Vector) {
+	for j := range numeric {
+		c.Numeric.Set(i, j, numeric[j])
+		rel, suspicious := compareEntry(c.Analytic.Get(i, j), numeric[j], e[j], fx, h[j])
+		c.RelativeErrors.Set(i, j, rel)
+		c.MaxError = math.Max(c.MaxError, rel)
+		if suspicious {
+			c.Suspicious = append(c.Suspicious, [2]int{i, j})
+			c.Passed = false
+		}
+	}
+}
+
+// CheckJacobian compares the Jacobian of the m-valued function c computed by jacobian at
+// x with Ridders' finite differences of each component, as CheckGradient does.
+func CheckJacobian(c ConstraintFunc, jacobian JacobianFunc, m int, x, typical linalg.Vector) *matrixCheck {
+	n := x.Len()
+	check := newMatrixCheck(m, n)
+	xc := linalg.NewVector(n)
+	blas.COPY(x, xc)
+	jacobian(xc, check.Analytic)
+	c0 := linalg.NewVector(m)
+	c(xc, c0)
+
+	h := checkSteps(x, typical)
+	cx := linalg.NewVector(m)
+	row := linalg.NewVector(n)
+	e := linalg.NewVector(n)
+	for i := 0; i < m; i++ {
+		component := func(x linalg.Vector) float64 {
+			cx.Zero()
+			c(x, cx)
+			return cx[i]
+		}
+		riddersGradient(component, xc, func(j int) float64 { return h[j] }, row, e)
+		check.compareRow(i, row, e, c0[i], h)
+	}
+	return check
+}
+
+// CheckHessian compares the Hessian of f computed by hessian at x with Ridders' finite
+// differences of the gradient computed by grad, which should itself be exact or checked.
+func CheckHessian(f ObjectiveFunc, grad gradientFunc, hessian HessianFunc, x, typical linalg.Vector) *matrixCheck {
+	n := x.Len()
+	check := newMatrixCheck(n, n)
+	xc := linalg.NewVector(n)
+	blas.COPY(x, xc)
+	hessian(xc, check.Analytic)
+	g0 := linalg.NewVector(n)
+	grad(xc, f, g0)
+
+	h := checkSteps(x, typical)
+	g := linalg.NewVector(n)
+	row := linalg.NewVector(n)
+	e := linalg.NewVector(n)
+	for i := 0; i < n; i++ {
+		component := func(x linalg.Vector) float64 {
+			grad(x, f, g)
+			return g[i]
+		}
+		riddersGradient(component, xc, func(j int) float64 { return h[j] }, row, e)
+		check.compareRow(i, row, e, g0[i], h)
+	}
+	return check
+}
+
+// WithGradientCheck checks the gradient at x0 with CheckGradient before a solver starts,
+// differencing at the typical magnitudes typ, which may be nil. If the check fails the
+// solver returns at once with an invalid solution that holds the failed check.
+func WithGradientCheck(typical linalg.Vector) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.checkGradient = true
+		opts.checkTypical = typical
+	}
+}
+
+// failedGradientCheck returns the check of the gradient of f at x0 requested by
+// WithGradientCheck if it fails, and nil otherwise.
+func failedGradientCheck(f ObjectiveFunc, x0 linalg.Vector, opts *solveOptions) *gradientCheck {
+	if !opts.checkGradient {
+		return nil
+	}
+	if check := CheckGradient(f, opts.gradientFunc, x0, opts.checkTypical); !check.Passed {
+		return check
+	}
+	return nil
+}
+
+// vectorCopy returns a new vector with the values of x.
+func vectorCopy(x linalg.Vector) linalg.Vector {
+	y := linalg.NewVector(x.Len())
+	blas.COPY(x, y)
+	return y
+}
@@ -0,0 +1,161 @@
+package optim_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/autodiff"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// wrongGradient has a mistake in the second component of SimpleTestFunctionGradient
+func wrongGradient(X linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+	SimpleTestFunctionGradient(X, f, gradF)
+	gradF[1] = -2*X[0] + 4*X[1]
+	return math.Hypot(gradF[0], gradF[1])
+}
+
+func TestCheckGradient(t *testing.T) {
+	x := linalg.Vector{1.5, -0.5}
+	check := optim.CheckGradient(SimpleTestFunction, SimpleTestFunctionGradient, x, nil)
+	if !check.Passed || check.MaxError > 1e-10 || len(check.Suspicious) != 0 {
+		t.Errorf("expected the gradient to pass, got %+v", check)
+	}
+
+	check = optim.CheckGradient(SimpleTestFunction, wrongGradient, x, nil)
+	if check.Passed || !reflect.DeepEqual(check.Suspicious, []int{1}) {
+		t.Errorf("expected component 1 to fail, got %+v", check)
+	}
+	if check.RelativeErrors[0] > 1e-10 || math.Abs(check.Numeric[1]+7) > 1e-10 {
+		t.Errorf("expected an exact first component and numeric g2 = -7, got %+v", check)
+	}
+	if report := check.String(); !strings.Contains(report, "FAILED") || strings.Count(report, "<-") != 1 {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	if x[0] != 1.5 || x[1] != -0.5 {
+		t.Errorf("expected x unchanged, got %v", x)
+	}
+
+	// forward differences are not accurate enough to pass
+	check = optim.CheckGradient(SimpleTestFunction, optim.ForwardGradientConstantStep(1e-4, 2), x, nil)
+	if check.Passed {
+		t.Errorf("expected forward differences to fail, got %+v", check)
+	}
+}
+
+func TestCheckJacobian(t *testing.T) {
+	x := linalg.Vector{-0.3, 0.8, 1.2, -1.1}
+	check := optim.CheckJacobian(broydenTridiagonal, broydenTridiagonalJacobian, 4, x, nil)
+	if !check.Passed || check.MaxError > 1e-10 {
+		t.Errorf("expected the Jacobian to pass, got %+v", check)
+	}
+
+	wrong := func(x linalg.Vector, J linalg.Matrix) {
+		broydenTridiagonalJacobian(x, J)
+		J.Set(2, 3, 2)
+	}
+	check = optim.CheckJacobian(broydenTridiagonal, wrong, 4, x, nil)
+	if check.Passed || !reflect.DeepEqual(check.Suspicious, [][2]int{{2, 3}}) {
+		t.Errorf("expected entry (2, 3) to fail, got %v", check.Suspicious)
+	}
+}
+
+func TestCheckHessian(t *testing.T) {
+	x := linalg.Vector{0.5, -0.7, 1.1}
+	hessian := autodiff.Hessian(mixedHessianDual)
+	check := optim.CheckHessian(mixedObjective, mixedGradient, hessian, x, nil)
+	if !check.Passed || check.MaxError > 1e-9 {
+		t.Errorf("expected the Hessian to pass, got %+v", check)
+	}
+
+	// a Hessian missing the x0^2 * sin(x2) term
+	wrong := func(x linalg.Vector, H linalg.Matrix) {
+		hessian(x, H)
+		H.Set(0, 0, H.Get(0, 0)-2*math.Sin(x[2]))
+	}
+	check = optim.CheckHessian(mixedObjective, mixedGradient, wrong, x, nil)
+	if check.Passed || !reflect.DeepEqual(check.Suspicious, [][2]int{{0, 0}}) {
+		t.Errorf("expected entry (0, 0) to fail, got %v", check.Suspicious)
+	}
+}
+
+func TestCheckGradientScaling(t *testing.T) {
+	// f = 1e-3 * ||x||^2 has a gradient far below 1 near the origin, where a wrong sign
+	// or a missing component must still be caught
+	small := func(x linalg.Vector) float64 {
+		return 1e-3 * (x[0]*x[0] + x[1]*x[1])
+	}
+	x := linalg.Vector{1e-4, 2e-4}
+	exact := func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		gradF[0], gradF[1] = 2e-3*x[0], 2e-3*x[1]
+		return math.Hypot(gradF[0], gradF[1])
+	}
+	if check := optim.CheckGradient(small, exact, x, nil); !check.Passed {
+		t.Errorf("expected the gradient to pass, got %+v", check)
+	}
+	wrongSign := func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		exact(x, f, gradF)
+		gradF[0] = -gradF[0]
+		return math.Hypot(gradF[0], gradF[1])
+	}
+	if check := optim.CheckGradient(small, wrongSign, x, nil); !reflect.DeepEqual(check.Suspicious, []int{0}) {
+		t.Errorf("expected component 0 to fail, got %+v", check)
+	}
+	missing := func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		exact(x, f, gradF)
+		gradF[1] = 0
+		return math.Abs(gradF[0])
+	}
+	if check := optim.CheckGradient(small, missing, x, nil); !reflect.DeepEqual(check.Suspicious, []int{1}) {
+		t.Errorf("expected component 1 to fail, got %+v", check)
+	}
+
+	// coordinates of very different magnitudes each get a step of their own size
+	wide := func(x linalg.Vector) float64 {
+		return math.Exp(x[0]) + math.Log(x[1])
+	}
+	wideGradient := func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		gradF[0], gradF[1] = math.Exp(x[0]), 1/x[1]
+		return math.Hypot(gradF[0], gradF[1])
+	}
+	if check := optim.CheckGradient(wide, wideGradient, linalg.Vector{3e-6, 4e6}, nil); !check.Passed || check.MaxError > 1e-8 {
+		t.Errorf("expected the gradient to pass, got %+v", check)
+	}
+
+	// typical magnitudes shrink the steps of a rapidly varying coordinate
+	steep := func(x linalg.Vector) float64 {
+		return math.Exp(1e5 * x[0])
+	}
+	steepGradient := func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		gradF[0] = 1e5 * math.Exp(1e5*x[0])
+		return math.Abs(gradF[0])
+	}
+	if check := optim.CheckGradient(steep, steepGradient, linalg.Vector{3e-6}, linalg.Vector{1e-5}); !check.Passed {
+		t.Errorf("expected the gradient to pass, got %+v", check)
+	}
+}
+
+func TestWithGradientCheck(t *testing.T) {
+	sol := optim.NewQuasiNewtonSolver().Solve(SimpleTestFunction, linalg.Vector{1, 1},
+		optim.WithGradientFunc(SimpleTestFunctionGradient), optim.WithGradientCheck(nil))
+	if !sol.ValidSolution || sol.GradientCheck != nil {
+		t.Errorf("expected a solution, got %+v", sol)
+	}
+
+	x0 := linalg.Vector{1, 1}
+	sol = optim.NewLBFGSBSolver().Solve(SimpleTestFunction, x0,
+		optim.WithGradientFunc(wrongGradient), optim.WithGradientCheck(nil))
+	if sol.ValidSolution || sol.Iterations != 0 || sol.GradientCheck == nil {
+		t.Fatalf("expected an invalid solution with the failed check, got %+v", sol)
+	}
+	if err := sol.GradientCheck.Err(); !errors.Is(err, optim.ErrGradientCheck) {
+		t.Errorf("expected ErrGradientCheck, got %v", err)
+	}
+	if !reflect.DeepEqual(sol.Result, x0) {
+		t.Errorf("expected the solver to stop at x0, got %v", sol.Result)
+	}
+}
@@ -0,0 +1,288 @@
+package optim
+
+import (
+	"sort"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// adjacency returns the neighbours of each column of the pattern in its column
+// intersection graph, where two columns are adjacent if they share a row.
+func adjacency(pattern linalg.SparseMatrix) [][]int {
+	n := pattern.Cols()
+	rowCols := make([][]int, pattern.Rows())
+	for j := 0; j < n; j++ {
+		rows, _ := pattern.Column(j)
+		for _, i := range rows {
+			rowCols[i] = append(rowCols[i], j)
+		}
+	}
+	adj := make([][]int, n)
+	seen := make([]int, n)
+	for j := range seen {
+		seen[j] = -1
+	}
+	for j := 0; j < n; j++ {
+		seen[j] = j
+		rows, _ := pattern.Column(j)
+		for _, i := range rows {
+			for _, k := range rowCols[i] {
+				if seen[k] != j {
+					seen[k] = j
+					adj[j] = append(adj[j], k)
+				}
+			}
+		}
+	}
+	return adj
+}
+
+// symmetricAdjacency returns the neighbours of each variable in the graph of a symmetric
+// pattern, with an edge i - j for each off-diagonal entry of the pattern or its transpose.
+func symmetricAdjacency(pattern linalg.SparseMatrix) [][]int {
+	n := pattern.Cols()
+	if pattern.Rows() != n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	sets := make([]map[int]bool, n)
+	for j := range sets {
+		sets[j] = map[int]bool{}
+	}
+	for j := 0; j < n; j++ {
+		rows, _ := pattern.Column(j)
+		for _, i := range rows {
+			if i != j {
+				sets[i][j] = true
+				sets[j][i] = true
+			}
+		}
+	}
+	adj := make([][]int, n)
+	for j, set := range sets {
+		for k := range set {
+			adj[j] = append(adj[j], k)
+		}
+		sort.Ints(adj[j])
+	}
+	return adj
+}
+
+// largestFirst returns the vertices of the graph by decreasing degree.
+func largestFirst(adj [][]int) []int {
+	order := make([]int, len(adj))
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(adj[order[a]]) > len(adj[order[b]])
+	})
+	return order
+}
+
+// ColorColumns groups the columns of a Jacobian with the sparsity pattern so that no two
+// columns of a group have a nonzero in the same row, as Curtis, Powell and Reid (On the
+// Estimation of Sparse Jacobian Matrices, 1974) proposed. It greedily colors the column
+// intersection graph in largest-first order and returns the color of each column and
+// the number of colors.
+func ColorColumns(pattern linalg.SparseMatrix) ([]int, int) {
+	adj := adjacency(pattern)
+	colors := make([]int, len(adj))
+	for j := range colors {
+		colors[j] = -1
+	}
+	forbidden := make([]int, len(adj)+1)
+	for c := range forbidden {
+		forbidden[c] = -1
+	}
+	count := 0
+	for _, v := range largestFirst(adj) {
+		for _, w := range adj[v] {
+			if colors[w] >= 0 {
+				forbidden[colors[w]] = v
+			}
+		}
+		c := 0
+		for forbidden[c] == v {
+			c++
+		}
+		colors[v] = c
+		count = max(count, c+1)
+	}
+	return colors, count
+}
+
+// StarColorColumns colors the variables of a Hessian with the symmetric sparsity pattern
+// so that adjacent variables differ in color and every path on four variables uses at
+// least three colors. Every nonzero of the Hessian can then be read directly from the
+// products of H with the sum of the unit vectors of each color. It is the greedy star
+// coloring of Gebremedhin, Manne and Pothen (What Color Is Your Jacobian?, 2005,
+// Algorithm 4.1) in largest-first order, and returns the color of each variable and the
+// number of colors. Only star coloring is provided: the acyclic coloring of the same paper,
+// which often needs fewer colors but recovers the Hessian by substitution, is not.
+func StarColorColumns(pattern linalg.SparseMatrix) ([]int, int) {
+	adj := symmetricAdjacency(pattern)
+	colors := make([]int, len(adj))
+	for j := range colors {
+		colors[j] = -1
+	}
+	forbidden := make([]int, len(adj)+1)
+	for c := range forbidden {
+		forbidden[c] = -1
+	}
+	count := 0
+	for _, v := range largestFirst(adj) {
+		for _, w := range adj[v] {
+			if colors[w] >= 0 {
+				forbidden[colors[w]] = v
+			}
+		}
+		for _, w := range adj[v] {
+			if colors[w] < 0 {
+				// v and any colored x two steps away through w would be two ends of a
+				// path of length two with w free to take their color
+				for _, x := range adj[w] {
+					if x != v && colors[x] >= 0 {
+						forbidden[colors[x]] = v
+					}
+				}
+				continue
+			}
+			// a path v - w - x - y colored c(v), c(w), c(x), c(w) would be bicolored if
+			// v took the color of x
+			for _, x := range adj[w] {
+				if x == v || colors[x] < 0 {
+					continue
+				}
+				for _, y := range adj[x] {
+					if y != w && colors[y] == colors[w] {
+						forbidden[colors[x]] = v
+						break
+					}
+				}
+			}
+		}
+		c := 0
+		for forbidden[c] == v {
+			c++
+		}
+		colors[v] = c
+		count = max(count, c+1)
+	}
+	return colors, count
+}
+
+// SparseCentralJacobian returns a JacobianFunc that approximates the Jacobian of the
+// m-valued function c with the sparsity pattern by central differences, perturbing all
+// the columns of a color of ColorColumns at once. It costs two evaluations of c per
+// color rather than per column. Entries outside the pattern are set to zero.
+func SparseCentralJacobian(c ConstraintFunc, pattern linalg.SparseMatrix, delta float64) JacobianFunc {
+	m, n := pattern.Rows(), pattern.Cols()
+	colors, count := ColorColumns(pattern)
+	c0 := linalg.NewVector(m)
+	c1 := linalg.NewVector(m)
+	x0 := linalg.NewVector(n)
+	return func(x linalg.Vector, J linalg.Matrix) {
+		if x.Len() != n || J.Rows() != m || J.Cols() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		blas.COPY(x, x0)
+		J.Zero()
+		for color := 0; color < count; color++ {
+			for j := range x {
+				if colors[j] == color {
+					x[j] = x0[j] + delta
+				}
+			}
+			c1.Zero()
+			c(x, c1)
+			for j := range x {
+				if colors[j] == color {
+					x[j] = x0[j] - delta
+				}
+			}
+			c0.Zero()
+			c(x, c0)
+
+			// restore the original values
+			blas.COPY(x0, x)
+
+			// each row meets at most one column of the color
+			for j := range x {
+				if colors[j] != color {
+					continue
+				}
+				rows, _ := pattern.Column(j)
+				for _, i := range rows {
+					J.Set(i, j, (c1[i]-c0[i])/(2*delta))
+				}
+			}
+		}
+	}
+}
+
+// SparseHessianFromGradient returns a HessianFunc that approximates the Hessian of f with
+// the symmetric sparsity pattern by forward differences of its gradient along the sum of
+// the unit vectors of each color of StarColorColumns. It costs one gradient evaluation
+// per color plus one. Each entry H_ij is read from the product of the color of j if no
+// other variable of that color is adjacent to i, and otherwise from the product of the
+// color of i, which the star coloring guarantees is free of conflicts. Every entry is
+// recovered directly, never by substitution, so no error propagates between entries.
+func SparseHessianFromGradient(f ObjectiveFunc, gradient gradientFunc, pattern linalg.SparseMatrix, delta float64) HessianFunc {
+	n := pattern.Cols()
+	adj := symmetricAdjacency(pattern)
+	colors, count := StarColorColumns(pattern)
+
+	// colorCount counts the neighbours of each variable in each color
+	colorCount := make(map[[2]int]int)
+	for i, nbrs := range adj {
+		for _, j := range nbrs {
+			colorCount[[2]int{i, colors[j]}]++
+		}
+	}
+	g0 := linalg.NewVector(n)
+	g1 := linalg.NewVector(n)
+	x0 := linalg.NewVector(n)
+	products := make([]linalg.Vector, count)
+	for c := range products {
+		products[c] = linalg.NewVector(n)
+	}
+	return func(x linalg.Vector, H linalg.Matrix) {
+		if x.Len() != n || H.Rows() != n || H.Cols() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		blas.COPY(x, x0)
+		gradient(x, f, g0)
+		for color := 0; color < count; color++ {
+			for j := range x {
+				if colors[j] == color {
+					x[j] = x0[j] + delta
+				}
+			}
+			gradient(x, f, g1)
+
+			// restore the original values
+			blas.COPY(x0, x)
+
+			for i := range g1 {
+				products[color][i] = (g1[i] - g0[i]) / delta
+			}
+		}
+
+		H.Zero()
+		for i := 0; i < n; i++ {
+			H.Set(i, i, products[colors[i]][i])
+			for _, j := range adj[i] {
+				if j < i {
+					continue
+				}
+				h := products[colors[i]][j]
+				if colorCount[[2]int{i, colors[j]}] == 1 {
+					h = products[colors[j]][i]
+				}
+				H.Set(i, j, h)
+				H.Set(j, i, h)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/autodiff"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// randomPattern returns an m x n pattern with about density * m * n entries.
+func randomPattern(m, n int, density float64, seed uint64) linalg.SparseMatrix {
+	t := linalg.NewTripletMatrix(m, n)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			seed = seed*6364136223846793005 + 1442695040888963407
+			if float64(seed>>11)/(1<<53) < density {
+				t.Append(i, j, 1)
+			}
+		}
+	}
+	return t.ToCSC()
+}
+
+func TestColorColumns(t *testing.T) {
+	pattern := randomPattern(40, 60, 0.05, 1)
+	colors, count := optim.ColorColumns(pattern)
+	for i := 0; i < pattern.Rows(); i++ {
+		seen := map[int]int{}
+		for j := 0; j < pattern.Cols(); j++ {
+			if colors[j] < 0 || colors[j] >= count {
+				t.Fatalf("column %d has color %d of %d", j, colors[j], count)
+			}
+			if pattern.Get(i, j) == 0 {
+				continue
+			}
+			if k, ok := seen[colors[j]]; ok {
+				t.Fatalf("columns %d and %d share row %d and color %d", k, j, i, colors[j])
+			}
+			seen[colors[j]] = j
+		}
+	}
+	if count >= pattern.Cols()/2 {
+		t.Errorf("expected far fewer colors than columns, got %d", count)
+	}
+}
+
+func TestStarColorColumns(t *testing.T) {
+	p := randomPattern(50, 50, 0.04, 7)
+	adj := make([][]bool, 50)
+	for i := range adj {
+		adj[i] = make([]bool, 50)
+	}
+	for i := 0; i < 50; i++ {
+		for j := 0; j < 50; j++ {
+			if i != j && p.Get(i, j) != 0 {
+				adj[i][j], adj[j][i] = true, true
+			}
+		}
+	}
+	colors, _ := optim.StarColorColumns(p)
+	for v := 0; v < 50; v++ {
+		for w := 0; w < 50; w++ {
+			if !adj[v][w] {
+				continue
+			}
+			if colors[v] == colors[w] {
+				t.Fatalf("adjacent %d and %d share color %d", v, w, colors[v])
+			}
+			// no path v - w - x - y may be colored with two colors
+			for x := 0; x < 50; x++ {
+				if !adj[w][x] || x == v || colors[x] != colors[v] {
+					continue
+				}
+				for y := 0; y < 50; y++ {
+					if adj[x][y] && y != w && y != v && colors[y] == colors[w] {
+						t.Fatalf("path %d - %d - %d - %d has two colors", v, w, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSparseCentralJacobian(t *testing.T) {
+	n := 30
+	pattern := linalg.NewTripletMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := max(0, i-1); j <= min(n-1, i+1); j++ {
+			pattern.Append(i, j, 1)
+		}
+	}
+	evaluations := 0
+	counted := func(x, fx linalg.Vector) {
+		evaluations++
+		broydenTridiagonal(x, fx)
+	}
+	jacobian := optim.SparseCentralJacobian(counted, pattern.ToCSC(), 1e-6)
+
+	x := linalg.NewVector(n)
+	for i := range x {
+		x[i] = -1 + 0.05*float64(i)
+	}
+	J := linalg.NewDenseMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			J.Set(i, j, 99)
+		}
+	}
+	jacobian(x, J)
+	if evaluations != 6 {
+		t.Errorf("expected two evaluations for each of 3 colors, got %d", evaluations)
+	}
+	exact := linalg.NewDenseMatrix(n, n)
+	broydenTridiagonalJacobian(x, exact)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if math.Abs(J.Get(i, j)-exact.Get(i, j)) > 1e-8 {
+				t.Fatalf("J[%d][%d]: expected %v, got %v", i, j, exact.Get(i, j), J.Get(i, j))
+			}
+		}
+	}
+
+	// the estimate drives the root finder
+	sol := optim.RootFind(broydenTridiagonal, jacobian, linalg.NewVector(n).Set(-1))
+	if !sol.ValidSolution || sol.ResidualNorm > 1e-8 {
+		t.Errorf("expected a root, got %+v", sol)
+	}
+}
+
+// arrowhead couples x0 with every variable and each variable with the next
+func arrowhead(x []autodiff.Var) autodiff.Var {
+	terms := make([]autodiff.Var, 0, 2*len(x))
+	for i := 1; i < len(x); i++ {
+		a := x[0].Mul(x[i]).AddConst(-1)
+		b := x[i].Sub(x[i-1].Mul(x[i-1]).Scale(0.5)).Sin()
+		terms = append(terms, a.Mul(a), b.Mul(x[i]))
+	}
+	return x[0].Tape().Sum(terms)
+}
+
+func arrowheadHyper(x []autodiff.HyperDual) autodiff.HyperDual {
+	s := autodiff.HyperConstant(0)
+	for i := 1; i < len(x); i++ {
+		a := x[0].Mul(x[i]).AddConst(-1)
+		b := x[i].Sub(x[i-1].Mul(x[i-1]).Scale(0.5)).Sin()
+		s = s.Add(a.Mul(a)).Add(b.Mul(x[i]))
+	}
+	return s
+}
+
+func TestSparseHessianFromGradient(t *testing.T) {
+	n := 20
+	pattern := linalg.NewTripletMatrix(n, n)
+	for i := 0; i < n; i++ {
+		pattern.Append(i, i, 1)
+		pattern.Append(0, i, 1)
+		if i > 0 {
+			pattern.Append(i, i-1, 1)
+		}
+	}
+	_, count := optim.StarColorColumns(pattern.ToCSC())
+	if count > 4 {
+		t.Errorf("expected at most 4 colors, got %d", count)
+	}
+
+	evaluations := 0
+	gradient := autodiff.ReverseGradient(arrowhead)
+	counted := func(x linalg.Vector, f optim.ObjectiveFunc, g linalg.Vector) float64 {
+		evaluations++
+		return gradient(x, f, g)
+	}
+	hessian := optim.SparseHessianFromGradient(nil, counted, pattern.ToCSC(), 1e-7)
+
+	x := linalg.NewVector(n)
+	for i := range x {
+		x[i] = 0.8 + 0.03*float64(i)
+	}
+	H := linalg.NewDenseMatrix(n, n)
+	hessian(x, H)
+	if evaluations != count+1 {
+		t.Errorf("expected %d gradient evaluations, got %d", count+1, evaluations)
+	}
+	exact := linalg.NewDenseMatrix(n, n)
+	autodiff.Hessian(arrowheadHyper)(x, exact)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if math.Abs(H.Get(i, j)-exact.Get(i, j)) > 1e-5*math.Max(1, math.Abs(exact.Get(i, j))) {
+				t.Errorf("H[%d][%d]: expected %v, got %v", i, j, exact.Get(i, j), H.Get(i, j))
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// ConstraintFunc evaluates a vector-valued constraint function at x, writing the values into c.
+type ConstraintFunc func(x linalg.Vector, c linalg.Vector)
+
+// JacobianFunc evaluates the Jacobian of a vector-valued function at x, writing
+// dc_i/dx_j into J[i][j].
+type JacobianFunc func(x linalg.Vector, J linalg.Matrix)
+
+// ConstrainedProblem is the nonlinear program
+//
+//	minimize f(x) subject to g(x) = 0, h(x) <= 0
+//
+// The Jacobians are optional; missing Jacobians are approximated by central differences.
+// The objective gradient is set with WithGradientFunc and simple bounds with WithBounds.
+type ConstrainedProblem struct {
+	Objective ObjectiveFunc
+
+	Equality         ConstraintFunc
+	NumEquality      int
+	EqualityJacobian JacobianFunc
+
+	Inequality         ConstraintFunc
+	NumInequality      int
+	InequalityJacobian JacobianFunc
+}
+
+// constrainedSolution is the result of a constrained optimization.
+type constrainedSolution struct {
+	ValidSolution bool
+	Result        linalg.Vector
+	Iterations    int
+	Objective     float64
+
+	// Lagrange multipliers of g(x) = 0 and h(x) <= 0, with the Lagrangian
+	// L(x, lambda, mu) = f(x) + lambda^T * g(x) + mu^T * h(x).
+	EqualityMultipliers   linalg.Vector
+	InequalityMultipliers linalg.Vector
+
+	// ActiveSet holds the indices of the inequality constraints active at the solution.
+	ActiveSet []int
+
+	// KKT residuals in the infinity norm. PrimalInfeasibility is the constraint violation.
+	Stationarity        float64
+	PrimalInfeasibility float64
+	Complementarity     float64
+
+	GradientCheck *gradientCheck // the failed check of WithGradientCheck, if any
+}
+
+// constraintEvaluator evaluates the constraints of a problem and their Jacobians,
+// falling back to finite differences for missing Jacobians.
+type constraintEvaluator struct {
+	problem            *ConstrainedProblem
+	n                  int
+	me                 int
+	mi                 int
+	equalityJacobian   JacobianFunc
+	inequalityJacobian JacobianFunc
+}
+
+func newConstraintEvaluator(problem *ConstrainedProblem, n int) *constraintEvaluator {
+	e := &constraintEvaluator{problem: problem, n: n}
+	if problem.Equality != nil {
+		e.me = problem.NumEquality
+		e.equalityJacobian = problem.EqualityJacobian
+		if e.equalityJacobian == nil {
+			e.equalityJacobian = CentralJacobianConstantStep(1e-6, problem.Equality, e.me)
+		}
+	}
+	if problem.Inequality != nil {
+		e.mi = problem.NumInequality
+		e.inequalityJacobian = problem.InequalityJacobian
+		if e.inequalityJacobian == nil {
+			e.inequalityJacobian = CentralJacobianConstantStep(1e-6, problem.Inequality, e.mi)
+		}
+	}
+	return e
+}
+
+func (e *constraintEvaluator) equality(x linalg.Vector, c linalg.Vector) {
+	if e.me > 0 {
+		e.problem.Equality(x, c)
+	}
+}
+
+func (e *constraintEvaluator) inequality(x linalg.Vector, c linalg.Vector) {
+	if e.mi > 0 {
+		e.problem.Inequality(x, c)
+	}
+}
+
+func (e *constraintEvaluator) jacobians(x linalg.Vector, Jg linalg.Matrix, Jh linalg.Matrix) {
+	if e.me > 0 {
+		e.equalityJacobian(x, Jg)
+	}
+	if e.mi > 0 {
+		e.inequalityJacobian(x, Jh)
+	}
+}
+
+// kktResiduals returns the stationarity, primal infeasibility and complementarity
+// residuals at x given the gradient of the Lagrangian gradL.
+func kktResiduals(x, gradL, g, h, mu linalg.Vector, bounds Bounds) (float64, float64, float64) {
+	stationarity := bounds.projectedGradientNorm(x, gradL)
+	infeasibility := 0.0
+	for i := range g {
+		infeasibility = math.Max(infeasibility, math.Abs(g[i]))
+	}
+	complementarity := 0.0
+	for i := range h {
+		infeasibility = math.Max(infeasibility, h[i])
+		complementarity = math.Max(complementarity, math.Abs(mu[i]*h[i]))
+	}
+	return stationarity, infeasibility, complementarity
+}
+
+// lagrangianGradient computes gradL = gradF + Jg^T * lambda + Jh^T * mu.
+func lagrangianGradient(gradF linalg.Vector, Jg linalg.Matrix, lambda linalg.Vector, Jh linalg.Matrix, mu linalg.Vector, gradL linalg.Vector) {
+	blas.COPY(gradF, gradL)
+	if lambda.Len() > 0 {
+		blas.GEMVT(1.0, Jg, lambda, 1.0, gradL)
+	}
+	if mu.Len() > 0 {
+		blas.GEMVT(1.0, Jh, mu, 1.0, gradL)
+	}
+}
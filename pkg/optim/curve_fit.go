@@ -0,0 +1,165 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// ModelFunc is a model y = f(t; p) fitted to data by CurveFit.
+type ModelFunc func(t float64, p linalg.Vector) float64
+
+// WithFixed holds the parameters marked true at their initial values in CurveFit.
+func WithFixed(fixed []bool) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.fixed = fixed
+	}
+}
+
+// curveFitResult is the result of CurveFit. Fixed parameters have zero standard errors
+// and zero rows and columns in the covariance and correlation matrices.
+type curveFitResult struct {
+	ValidSolution bool
+	Parameters    linalg.Vector
+	Iterations    int
+
+	StandardErrors linalg.Vector
+	Covariance     linalg.Matrix
+	Correlation    linalg.Matrix
+
+	ChiSquare        float64 // sum of the squared weighted residuals
+	ReducedChiSquare float64 // ChiSquare / (N - k) for N points and k free parameters
+	RSquared         float64 // 1 - ChiSquare / weighted total sum of squares
+	AIC              float64 // N * ln(ChiSquare / N) + 2 * k
+	BIC              float64 // N * ln(ChiSquare / N) + k * ln(N)
+
+	Residuals linalg.Vector // y_i - f(t_i; p), unweighted
+}
+
+// CurveFit fits the parameters p of model to the points (ts[i], ys[i]) by minimizing
+//
+//	chi^2 = sum_i ((ys[i] - model(ts[i], p)) / sigmas[i])^2
+//
+// with the Levenberg-Marquardt method, starting at p0. sigmas may be nil to weight the
+// points equally. Parameters can be held fixed with WithFixed and bounded with
+// WithBounds.
+//
+// If sigmas are given they are taken as the absolute uncertainties of the points and
+// the covariance is (J^T * W * J)^-1; otherwise the covariance is scaled by the reduced
+// chi-square, which estimates the unknown variance of the points.
+func CurveFit(model ModelFunc, ts, ys, sigmas, p0 linalg.Vector, options ...func(*solveOptions)) *curveFitResult {
+	N := ts.Len()
+	k := p0.Len()
+	if ys.Len() != N || (sigmas != nil && sigmas.Len() != N) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	opts := newSolveOptions(p0, options...)
+	if opts.fixed != nil && len(opts.fixed) != k {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	weight := func(i int) float64 {
+		if sigmas == nil {
+			return 1
+		}
+		return 1 / sigmas[i]
+	}
+
+	// the free parameters are the variables of the least-squares problem
+	var free []int
+	for j := 0; j < k; j++ {
+		if opts.fixed == nil || !opts.fixed[j] {
+			free = append(free, j)
+		}
+	}
+	nf := len(free)
+	p := linalg.NewVector(k)
+	copy(p, p0)
+	expand := func(x linalg.Vector) linalg.Vector {
+		for a, j := range free {
+			p[j] = x[a]
+		}
+		return p
+	}
+	x0 := linalg.NewVector(nf)
+	for a, j := range free {
+		x0[a] = p0[j]
+	}
+	if opts.bounds != nil {
+		opts.bounds.check(k)
+		reduced := Bounds{Lower: linalg.NewVector(nf), Upper: linalg.NewVector(nf)}
+		for a, j := range free {
+			reduced.Lower[a] = opts.bounds.lower(j)
+			reduced.Upper[a] = opts.bounds.upper(j)
+		}
+		opts.bounds = &reduced
+	}
+
+	problem := &LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			params := expand(x)
+			for i := 0; i < N; i++ {
+				r[i] = (model(ts[i], params) - ys[i]) * weight(i)
+			}
+		},
+		NumResiduals: N,
+	}
+	sol := (&levenbergMarquardtSolver{}).solve(problem, x0, opts)
+
+	res := &curveFitResult{
+		ValidSolution:  sol.ValidSolution,
+		Parameters:     linalg.NewVector(k),
+		Iterations:     sol.Iterations,
+		StandardErrors: linalg.NewVector(k),
+		Covariance:     linalg.NewDenseMatrix(k, k),
+		Correlation:    linalg.NewDenseMatrix(k, k),
+		Residuals:      linalg.NewVector(N),
+	}
+	copy(res.Parameters, expand(sol.Result))
+
+	// goodness of fit
+	sw, swy := 0.0, 0.0
+	for i := 0; i < N; i++ {
+		w := weight(i) * weight(i)
+		res.Residuals[i] = ys[i] - model(ts[i], res.Parameters)
+		res.ChiSquare += w * res.Residuals[i] * res.Residuals[i]
+		sw += w
+		swy += w * ys[i]
+	}
+	total := 0.0
+	for i := 0; i < N; i++ {
+		d := ys[i] - swy/sw
+		total += weight(i) * weight(i) * d * d
+	}
+	if N > nf {
+		res.ReducedChiSquare = res.ChiSquare / float64(N-nf)
+	}
+	res.RSquared = 1 - res.ChiSquare/total
+	res.AIC = float64(N)*math.Log(res.ChiSquare/float64(N)) + 2*float64(nf)
+	res.BIC = float64(N)*math.Log(res.ChiSquare/float64(N)) + float64(nf)*math.Log(float64(N))
+
+	// covariance of the free parameters from the weighted Jacobian
+	if nf > 0 && N > nf {
+		C := linalg.NewDenseMatrix(nf, nf)
+		linalg.FactorQR(sol.Jacobian).NormalInverse(C)
+		scale := 1.0
+		if sigmas == nil {
+			scale = res.ReducedChiSquare
+		}
+		for a, i := range free {
+			for b, j := range free {
+				res.Covariance.Set(i, j, scale*C.Get(a, b))
+			}
+		}
+		for _, i := range free {
+			res.StandardErrors[i] = math.Sqrt(res.Covariance.Get(i, i))
+		}
+		for _, i := range free {
+			for _, j := range free {
+				if d := res.StandardErrors[i] * res.StandardErrors[j]; d > 0 {
+					res.Correlation.Set(i, j, res.Covariance.Get(i, j)/d)
+				}
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func exponentialModel(t float64, p linalg.Vector) float64 {
+	return p[0]*math.Exp(-p[1]*t) + p[2]
+}
+
+// exponentialData samples 4 * exp(-0.5 * t) + 1 with a deterministic perturbation.
+func exponentialData() (ts, ys linalg.Vector) {
+	ts = linalg.NewVector(30)
+	ys = linalg.NewVector(30)
+	for i := range ts {
+		ts[i] = 0.25 * float64(i)
+		ys[i] = exponentialModel(ts[i], linalg.Vector{4, 0.5, 1}) + 0.02*math.Sin(5*ts[i])
+	}
+	return ts, ys
+}
+
+func TestCurveFitLine(t *testing.T) {
+	// weighted straight line, whose covariance (X^T * W * X)^-1 has a closed form
+	ts := linalg.Vector{0, 1, 2, 3, 4, 5, 6}
+	ys := linalg.Vector{0.9, 3.1, 4.8, 7.2, 9.1, 10.8, 13.2}
+	sigmas := linalg.Vector{0.1, 0.2, 0.1, 0.3, 0.2, 0.1, 0.2}
+	line := func(t float64, p linalg.Vector) float64 { return p[0] + p[1]*t }
+	fit := optim.CurveFit(line, ts, ys, sigmas, linalg.Vector{0, 1}, optim.WithTolerance(1e-12))
+	if !fit.ValidSolution {
+		t.Fatal("expected a valid fit")
+	}
+
+	s, st, stt, sy, sty := 0.0, 0.0, 0.0, 0.0, 0.0
+	for i := range ts {
+		w := 1 / (sigmas[i] * sigmas[i])
+		s += w
+		st += w * ts[i]
+		stt += w * ts[i] * ts[i]
+		sy += w * ys[i]
+		sty += w * ts[i] * ys[i]
+	}
+	det := s*stt - st*st
+	a := (stt*sy - st*sty) / det
+	b := (s*sty - st*sy) / det
+	if math.Abs(fit.Parameters[0]-a) > 1e-8 || math.Abs(fit.Parameters[1]-b) > 1e-8 {
+		t.Errorf("expected (%v, %v), got %v", a, b, fit.Parameters)
+	}
+	if math.Abs(fit.StandardErrors[0]-math.Sqrt(stt/det)) > 1e-8 || math.Abs(fit.StandardErrors[1]-math.Sqrt(s/det)) > 1e-8 {
+		t.Errorf("expected standard errors (%v, %v), got %v", math.Sqrt(stt/det), math.Sqrt(s/det), fit.StandardErrors)
+	}
+	if rho := -st / math.Sqrt(s*stt); math.Abs(fit.Correlation.Get(0, 1)-rho) > 1e-8 || fit.Correlation.Get(0, 0) != 1 {
+		t.Errorf("expected the correlation %v, got %v", rho, fit.Correlation)
+	}
+
+	n := float64(len(ts))
+	if math.Abs(fit.ReducedChiSquare-fit.ChiSquare/(n-2)) > 1e-12 ||
+		math.Abs(fit.AIC-(n*math.Log(fit.ChiSquare/n)+4)) > 1e-12 ||
+		math.Abs(fit.BIC-(n*math.Log(fit.ChiSquare/n)+2*math.Log(n))) > 1e-12 {
+		t.Errorf("inconsistent statistics: chi^2 %v, reduced %v, AIC %v, BIC %v", fit.ChiSquare, fit.ReducedChiSquare, fit.AIC, fit.BIC)
+	}
+	if fit.RSquared < 0.99 || fit.RSquared > 1 {
+		t.Errorf("expected R^2 close to 1, got %v", fit.RSquared)
+	}
+}
+
+func TestCurveFitFixedAndBounds(t *testing.T) {
+	ts, ys := exponentialData()
+	fit := optim.CurveFit(exponentialModel, ts, ys, nil, linalg.Vector{1, 1, 0})
+	expected := linalg.Vector{4, 0.5, 1}
+	for j := range expected {
+		if math.Abs(fit.Parameters[j]-expected[j]) > 0.02 || fit.StandardErrors[j] <= 0 {
+			t.Fatalf("expected about %v, got %v with standard errors %v", expected, fit.Parameters, fit.StandardErrors)
+		}
+	}
+	for i := range ts {
+		if math.Abs(fit.Residuals[i]-(ys[i]-exponentialModel(ts[i], fit.Parameters))) > 1e-12 {
+			t.Fatalf("unexpected residuals %v", fit.Residuals)
+		}
+	}
+
+	// the offset held at 1.5
+	fixed := optim.CurveFit(exponentialModel, ts, ys, nil, linalg.Vector{1, 1, 1.5}, optim.WithFixed([]bool{false, false, true}))
+	if fixed.Parameters[2] != 1.5 || fixed.StandardErrors[2] != 0 || fixed.Covariance.Get(0, 2) != 0 {
+		t.Errorf("expected the offset fixed at 1.5, got %v with standard errors %v", fixed.Parameters, fixed.StandardErrors)
+	}
+	if fixed.ChiSquare <= fit.ChiSquare {
+		t.Errorf("expected a worse fit with the offset fixed, got chi^2 %v and %v", fixed.ChiSquare, fit.ChiSquare)
+	}
+
+	// the rate bounded away from its best value
+	inf := math.Inf(1)
+	bounded := optim.CurveFit(exponentialModel, ts, ys, nil, linalg.Vector{1, 0.1, 0},
+		optim.WithBounds(optim.Bounds{Lower: linalg.Vector{-inf, 0, -inf}, Upper: linalg.Vector{inf, 0.4, inf}}))
+	if !bounded.ValidSolution || math.Abs(bounded.Parameters[1]-0.4) > 1e-9 {
+		t.Errorf("expected the rate at its bound 0.4, got %v", bounded.Parameters)
+	}
+
+	// the bounded fit is the fit with the rate fixed at its bound
+	atBound := optim.CurveFit(exponentialModel, ts, ys, nil, linalg.Vector{1, 0.4, 0}, optim.WithFixed([]bool{false, true, false}))
+	for _, j := range []int{0, 2} {
+		if math.Abs(bounded.Parameters[j]-atBound.Parameters[j]) > 1e-6 {
+			t.Errorf("expected the free parameters %v, got %v", atBound.Parameters, bounded.Parameters)
+		}
+	}
+	if math.Abs(bounded.ChiSquare-atBound.ChiSquare) > 1e-9*atBound.ChiSquare {
+		t.Errorf("expected chi^2 %v, got %v", atBound.ChiSquare, bounded.ChiSquare)
+	}
+}
@@ -1,6 +1,8 @@
 package optim
 
 import (
+	"math"
+
 	"github.com/tab58/go-optimize/internal/blas"
 	"github.com/tab58/go-optimize/internal/linalg"
 )
@@ -26,6 +28,7 @@ func ForwardGradientConstantStep(delta float64, n int) gradientFunc {
 			x[i] = xi + dxi
 			fxh = f(x)
 			g = (fxh - fx0) / dxi
+			gradF[i] = g
 
 			// restore the original value
 			x[i] = xi
@@ -56,6 +59,7 @@ func BackwardGradientConstantStep(delta float64, n int) gradientFunc {
 			x[i] = xi - dxi
 			fx0 = f(x)
 			g = (fxh - fx0) / dxi
+			gradF[i] = g
 
 			// restore the original value
 			x[i] = xi
@@ -99,3 +103,200 @@ func CentralGradientConstantStep(delta float64, n int) gradientFunc {
 		return nrm2
 	}
 }
+
+// CentralGradient4ConstantStep returns a gradientFunc that approximates the gradient by
+// the fourth-order central stencil
+//
+//	g_i = (f(x - 2h) - 8 * f(x - h) + 8 * f(x + h) - f(x + 2h)) / (12h)
+func CentralGradient4ConstantStep(delta float64, n int) gradientFunc {
+	return stencilGradient([]float64{2.0 / 3.0, -1.0 / 12.0}, delta, n)
+}
+
+// CentralGradient6ConstantStep returns a gradientFunc that approximates the gradient by
+// the sixth-order central stencil
+//
+//	g_i = (-f(x - 3h) + 9 * f(x - 2h) - 45 * f(x - h) + 45 * f(x + h) - 9 * f(x + 2h) + f(x + 3h)) / (60h)
+func CentralGradient6ConstantStep(delta float64, n int) gradientFunc {
+	return stencilGradient([]float64{3.0 / 4.0, -3.0 / 20.0, 1.0 / 60.0}, delta, n)
+}
+
+// stencilGradient approximates g_i = sum_k w_k * (f(x + k * h * e_i) - f(x - k * h * e_i)) / h
+// for k = 1, ..., len(w).
+func stencilGradient(w []float64, delta float64, n int) gradientFunc {
+	return func(x linalg.Vector, f ObjectiveFunc, gradF linalg.Vector) float64 {
+		if x.Len() != n || gradF.Len() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		nrm2 := 0.0
+		for i := range x {
+			xi := x[i]
+			g := 0.0
+			for k, wk := range w {
+				h := float64(k+1) * delta
+				x[i] = xi + h
+				fp := f(x)
+				x[i] = xi - h
+				fm := f(x)
+				g += wk * (fp - fm)
+			}
+			g /= delta
+			gradF[i] = g
+
+			// restore the original value
+			x[i] = xi
+
+			nrm2 = blas.Hypot(nrm2, g)
+		}
+		return nrm2
+	}
+}
+
+var RIDDERS_SHRINK = 1.4 // factor by which each step of the extrapolation table shrinks
+var RIDDERS_TABLE = 10   // largest number of steps of the extrapolation table
+var RIDDERS_SAFE = 2.0   // stop once the error grows by this factor over the best estimate
+
+// RiddersGradient returns a gradientFunc that approximates each component of the gradient
+// by Ridders' method: central differences at steps delta, delta / 1.4, ... are
+// extrapolated to a zero step with Neville's algorithm. It costs up to 2 * RIDDERS_TABLE
+// evaluations per component and is far more accurate than a single difference, even
+// for a poor choice of delta.
+func RiddersGradient(delta float64, n int) gradientFunc {
+	errF := linalg.NewVector(n)
+	return func(x linalg.Vector, f ObjectiveFunc, gradF linalg.Vector) float64 {
+		return RiddersGradientError(f, x, delta, gradF, errF)
+	}
+}
+
+// RiddersGradientError writes Ridders' approximation of the gradient of f at x into
+// gradF and an estimate of the absolute error of each component into errF. It returns
+// the norm of the gradient.
+func RiddersGradientError(f ObjectiveFunc, x linalg.Vector, delta float64, gradF, errF linalg.Vector) float64 {
+	return riddersGradient(f, x, func(int) float64 { return delta }, gradF, errF)
+}
+
+// riddersGradient is RiddersGradientError with the initial step of coordinate i given
+// by step.
+func riddersGradient(f ObjectiveFunc, x linalg.Vector, step func(i int) float64, gradF, errF linalg.Vector) float64 {
+	n := x.Len()
+	if gradF.Len() != n || errF.Len() != n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	// a[j][k] is the j-th extrapolation of the differences at the first k + 1 steps
+	a := make([][]float64, RIDDERS_TABLE)
+	for j := range a {
+		a[j] = make([]float64, RIDDERS_TABLE)
+	}
+	shrink2 := RIDDERS_SHRINK * RIDDERS_SHRINK
+
+	nrm2 := 0.0
+	for i := range x {
+		xi := x[i]
+		difference := func(h float64) float64 {
+			x[i] = xi + h
+			fp := f(x)
+			x[i] = xi - h
+			fm := f(x)
+			x[i] = xi
+			return (fp - fm) / (2 * h)
+		}
+
+		h := step(i)
+		a[0][0] = difference(h)
+		g, e := a[0][0], math.Inf(1)
+		for k := 1; k < RIDDERS_TABLE; k++ {
+			h /= RIDDERS_SHRINK
+			a[0][k] = difference(h)
+			factor := shrink2
+			for j := 1; j <= k; j++ {
+				a[j][k] = (a[j-1][k]*factor - a[j-1][k-1]) / (factor - 1)
+				factor *= shrink2
+				errt := math.Max(math.Abs(a[j][k]-a[j-1][k]), math.Abs(a[j][k]-a[j-1][k-1]))
+				if errt <= e {
+					g, e = a[j][k], errt
+				}
+			}
+			// higher orders are getting worse, so stop early
+			if math.Abs(a[k][k]-a[k-1][k-1]) >= RIDDERS_SAFE*e {
+				break
+			}
+		}
+		gradF[i] = g
+		errF[i] = e
+		nrm2 = blas.Hypot(nrm2, g)
+	}
+	return nrm2
+}
+
+// ComplexObjectiveFunc is an objective that can be evaluated at complex points, written
+// with the analytic continuation of its operations (no abs, comparisons or conjugates of
+// the variables).
+type ComplexObjectiveFunc func(x []complex128) complex128
+
+var COMPLEX_STEP = 1e-20 // imaginary step of the complex-step derivative
+
+// ComplexObjective returns the real part of f as an ObjectiveFunc.
+func ComplexObjective(f ComplexObjectiveFunc) ObjectiveFunc {
+	var z []complex128
+	return func(x linalg.Vector) float64 {
+		if len(z) != x.Len() {
+			z = make([]complex128, x.Len())
+		}
+		for i, v := range x {
+			z[i] = complex(v, 0)
+		}
+		return real(f(z))
+	}
+}
+
+// ComplexStepGradient returns a gradientFunc that computes g_i = Im(f(x + i * h * e_i)) / h
+// with the tiny step h = COMPLEX_STEP. There is no subtraction, so the gradient is
+// accurate to machine precision. It ignores the real objective passed to it.
+func ComplexStepGradient(f ComplexObjectiveFunc, n int) gradientFunc {
+	z := make([]complex128, n)
+	return func(x linalg.Vector, _ ObjectiveFunc, gradF linalg.Vector) float64 {
+		if x.Len() != n || gradF.Len() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		for i, v := range x {
+			z[i] = complex(v, 0)
+		}
+		nrm2 := 0.0
+		for i := range x {
+			z[i] = complex(x[i], COMPLEX_STEP)
+			g := imag(f(z)) / COMPLEX_STEP
+			z[i] = complex(x[i], 0)
+			gradF[i] = g
+			nrm2 = blas.Hypot(nrm2, g)
+		}
+		return nrm2
+	}
+}
+
+// CentralJacobianConstantStep returns a JacobianFunc that approximates the Jacobian of the
+// m-valued function c by central differences with a constant step.
+func CentralJacobianConstantStep(delta float64, c ConstraintFunc, m int) JacobianFunc {
+	c0 := linalg.NewVector(m)
+	c1 := linalg.NewVector(m)
+	return func(x linalg.Vector, J linalg.Matrix) {
+		if J.Rows() != m || J.Cols() != x.Len() {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		for j := range x {
+			xj := x[j]
+
+			x[j] = xj + delta
+			c1.Zero()
+			c(x, c1)
+			x[j] = xj - delta
+			c0.Zero()
+			c(x, c0)
+
+			// restore the original value
+			x[j] = xj
+
+			for i := 0; i < m; i++ {
+				J.Set(i, j, (c1[i]-c0[i])/(2*delta))
+			}
+		}
+	}
+}
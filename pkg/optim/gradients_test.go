@@ -0,0 +1,117 @@
+package optim_test
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// smoothComplex is f(x) = exp(x0) * sin(x1) + x0^3 * x1 + log(1 + x2^2)
+func smoothComplex(x []complex128) complex128 {
+	return cmplx.Exp(x[0])*cmplx.Sin(x[1]) + x[0]*x[0]*x[0]*x[1] + cmplx.Log(1+x[2]*x[2])
+}
+
+func smoothGradient(x linalg.Vector) linalg.Vector {
+	return linalg.Vector{
+		math.Exp(x[0])*math.Sin(x[1]) + 3*x[0]*x[0]*x[1],
+		math.Exp(x[0])*math.Cos(x[1]) + x[0]*x[0]*x[0],
+		2 * x[2] / (1 + x[2]*x[2]),
+	}
+}
+
+func gradientError(g, want linalg.Vector) float64 {
+	e := 0.0
+	for i := range g {
+		e = math.Max(e, math.Abs(g[i]-want[i])/math.Max(1, math.Abs(want[i])))
+	}
+	return e
+}
+
+func TestOneSidedGradientsWriteGradient(t *testing.T) {
+	x := linalg.Vector{1.5, -0.5}
+	want := linalg.NewVector(2)
+	SimpleTestFunctionGradient(x, SimpleTestFunction, want)
+
+	gradients := map[string]func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64{
+		"forward":  optim.ForwardGradientConstantStep(1e-7, 2),
+		"backward": optim.BackwardGradientConstantStep(1e-7, 2),
+	}
+	for name, grad := range gradients {
+		g := linalg.NewVector(2)
+		nrm := grad(x, SimpleTestFunction, g)
+		if math.Abs(g[0]-want[0]) > 1e-5 || math.Abs(g[1]-want[1]) > 1e-5 {
+			t.Errorf("%s: expected the gradient %v, got %v", name, want, g)
+		}
+		if math.Abs(nrm-math.Hypot(g[0], g[1])) > 1e-12*nrm {
+			t.Errorf("%s: expected the norm of %v, got %v", name, g, nrm)
+		}
+	}
+}
+
+func TestFiniteDifferenceGradients(t *testing.T) {
+	f := optim.ComplexObjective(smoothComplex)
+	x := linalg.Vector{0.8, -1.3, 2.2}
+	want := smoothGradient(x)
+	g := linalg.NewVector(3)
+
+	gradients := []struct {
+		name  string
+		grad  func(x linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64
+		limit float64
+	}{
+		{"forward", optim.ForwardGradientConstantStep(1e-7, 3), 1e-6},
+		{"backward", optim.BackwardGradientConstantStep(1e-7, 3), 1e-6},
+		{"central", optim.CentralGradientConstantStep(1e-5, 3), 1e-9},
+		{"central 4", optim.CentralGradient4ConstantStep(1e-3, 3), 1e-11},
+		{"central 6", optim.CentralGradient6ConstantStep(1e-2, 3), 1e-11},
+		{"ridders", optim.RiddersGradient(0.1, 3), 1e-12},
+		{"complex step", optim.ComplexStepGradient(smoothComplex, 3), 1e-15},
+	}
+	for _, gr := range gradients {
+		g.Zero()
+		nrm := gr.grad(x, f, g)
+		if e := gradientError(g, want); e > gr.limit {
+			t.Errorf("%s: expected error below %v, got %v for %v", gr.name, gr.limit, e, g)
+		}
+		if math.Abs(nrm-math.Sqrt(g[0]*g[0]+g[1]*g[1]+g[2]*g[2])) > 1e-12*nrm {
+			t.Errorf("%s: expected the norm %v, got %v", gr.name, math.Sqrt(g[0]*g[0]+g[1]*g[1]+g[2]*g[2]), nrm)
+		}
+		if x[0] != 0.8 || x[1] != -1.3 || x[2] != 2.2 {
+			t.Fatalf("%s: x was not restored, got %v", gr.name, x)
+		}
+	}
+}
+
+func TestRiddersErrorEstimate(t *testing.T) {
+	f := optim.ComplexObjective(smoothComplex)
+	x := linalg.Vector{-0.4, 0.6, 0.3}
+	want := smoothGradient(x)
+	g, e := linalg.NewVector(3), linalg.NewVector(3)
+
+	// a poor initial step is still extrapolated to an accurate gradient
+	optim.RiddersGradientError(f, x, 1.0, g, e)
+	for i := range g {
+		if math.Abs(g[i]-want[i]) > 1e-10 {
+			t.Errorf("g[%d]: expected %v, got %v", i, want[i], g[i])
+		}
+		if e[i] <= 0 || e[i] > 1e-8 || math.Abs(g[i]-want[i]) > 100*e[i] {
+			t.Errorf("g[%d]: error estimate %v does not describe the error %v", i, e[i], g[i]-want[i])
+		}
+	}
+}
+
+func TestComplexStepSolve(t *testing.T) {
+	// the Rosenbrock function over complex numbers
+	rosenbrock := func(x []complex128) complex128 {
+		a, b := 1-x[0], x[1]-x[0]*x[0]
+		return a*a + 100*b*b
+	}
+	sol := optim.NewLBFGSBSolver().Solve(optim.ComplexObjective(rosenbrock), linalg.Vector{-1.2, 1},
+		optim.WithGradientFunc(optim.ComplexStepGradient(rosenbrock, 2)), optim.WithTolerance(1e-10))
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-1) > 1e-8 || math.Abs(sol.Result[1]-1) > 1e-8 {
+		t.Errorf("expected the minimum [1 1], got %+v", sol)
+	}
+}
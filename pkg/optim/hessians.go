@@ -0,0 +1,152 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// HessianFunc evaluates the Hessian of an objective at x, writing d2f/dx_i dx_j into
+// H[i][j].
+type HessianFunc func(x linalg.Vector, H linalg.Matrix)
+
+// HessianVectorFunc writes the product of the Hessian of an objective at x with v into
+// Hv.
+type HessianVectorFunc func(x, v, Hv linalg.Vector)
+
+// CentralHessianConstantStep returns a HessianFunc that approximates the Hessian of f from
+// its values by the central second differences
+//
+//	H_ii = (f(x + h e_i) - 2 * f(x) + f(x - h e_i)) / h^2
+//	H_ij = (f(x + h e_i + h e_j) - f(x + h e_i - h e_j) - f(x - h e_i + h e_j) + f(x - h e_i - h e_j)) / (4h^2)
+//
+// at a cost of 2n^2 + 1 evaluations. The rounding error grows like eps / h^2, so steps
+// around eps^(1/4) times the scale of x suit it best.
+func CentralHessianConstantStep(f ObjectiveFunc, delta float64) HessianFunc {
+	return func(x linalg.Vector, H linalg.Matrix) {
+		n := x.Len()
+		if H.Rows() != n || H.Cols() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		f0 := f(x)
+		for i := 0; i < n; i++ {
+			xi := x[i]
+
+			x[i] = xi + delta
+			fp := f(x)
+			x[i] = xi - delta
+			fm := f(x)
+			H.Set(i, i, (fp-2*f0+fm)/(delta*delta))
+
+			for j := i + 1; j < n; j++ {
+				xj := x[j]
+				corners := 0.0
+				for _, s := range [4][2]float64{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+					x[i] = xi + s[0]*delta
+					x[j] = xj + s[1]*delta
+					corners += s[0] * s[1] * f(x)
+				}
+				x[j] = xj
+				h := corners / (4 * delta * delta)
+				H.Set(i, j, h)
+				H.Set(j, i, h)
+			}
+
+			// restore the original value
+			x[i] = xi
+		}
+	}
+}
+
+// ForwardHessianFromGradient returns a HessianFunc that approximates the Hessian of f by
+// forward differences of its gradient, A_ij = (g_i(x + h e_j) - g_i(x)) / h, symmetrized
+// as H = (A + A^T) / 2. It costs n + 1 gradient evaluations and, with an exact gradient,
+// is accurate to around sqrt(eps).
+func ForwardHessianFromGradient(f ObjectiveFunc, gradient gradientFunc, delta float64) HessianFunc {
+	var g0, g1 linalg.Vector
+	return func(x linalg.Vector, H linalg.Matrix) {
+		n := x.Len()
+		if H.Rows() != n || H.Cols() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		if g0.Len() != n {
+			g0 = linalg.NewVector(n)
+			g1 = linalg.NewVector(n)
+		}
+		gradient(x, f, g0)
+		for j := 0; j < n; j++ {
+			xj := x[j]
+			x[j] = xj + delta
+			h := x[j] - xj
+			gradient(x, f, g1)
+
+			// restore the original value
+			x[j] = xj
+
+			for i := 0; i < n; i++ {
+				H.Set(i, j, (g1[i]-g0[i])/h)
+			}
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				s := (H.Get(i, j) + H.Get(j, i)) / 2
+				H.Set(i, j, s)
+				H.Set(j, i, s)
+			}
+		}
+	}
+}
+
+// HessianVectorProduct returns a HessianVectorFunc that approximates H(x) * v by the
+// directional difference of the gradient
+//
+//	Hv = (g(x + h * v) - g(x)) / h,  h = delta * max(1, ||x||) / ||v||
+//
+// with one gradient evaluation per product, without forming H. The gradient at x is
+// kept between calls, so the repeated products of a Newton-CG or Steihaug iteration at
+// the same x cost one gradient each.
+func HessianVectorProduct(f ObjectiveFunc, gradient gradientFunc, delta float64) HessianVectorFunc {
+	var xc, g0, g1, xh linalg.Vector
+	cached := false
+	return func(x, v, Hv linalg.Vector) {
+		n := x.Len()
+		if v.Len() != n || Hv.Len() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		if xc.Len() != n {
+			xc = linalg.NewVector(n)
+			g0 = linalg.NewVector(n)
+			g1 = linalg.NewVector(n)
+			xh = linalg.NewVector(n)
+			cached = false
+		}
+		if !cached || !sameVector(x, xc) {
+			blas.COPY(x, xc)
+			gradient(xc, f, g0)
+			cached = true
+		}
+		vnorm := blas.NRM2(v)
+		if vnorm == 0 {
+			Hv.Zero()
+			return
+		}
+		h := delta * math.Max(1, blas.NRM2(x)) / vnorm
+		blas.COPY(x, xh)
+		blas.AXPY(h, v, xh)
+		gradient(xh, f, g1)
+		for i := range Hv {
+			Hv[i] = (g1[i] - g0[i]) / h
+		}
+	}
+}
+
+// sameVector reports whether x and y hold the same values.
+func sameVector(x, y linalg.Vector) bool {
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/autodiff"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// mixedObjective is f = exp(x0 * x1) + x0^2 * sin(x2)
+func mixedObjective(x linalg.Vector) float64 {
+	return math.Exp(x[0]*x[1]) + x[0]*x[0]*math.Sin(x[2])
+}
+
+func mixedGradient(x linalg.Vector, _ optim.ObjectiveFunc, g linalg.Vector) float64 {
+	e := math.Exp(x[0] * x[1])
+	g[0] = x[1]*e + 2*x[0]*math.Sin(x[2])
+	g[1] = x[0] * e
+	g[2] = x[0] * x[0] * math.Cos(x[2])
+	return math.Sqrt(g[0]*g[0] + g[1]*g[1] + g[2]*g[2])
+}
+
+func mixedHessianDual(x []autodiff.HyperDual) autodiff.HyperDual {
+	return x[0].Mul(x[1]).Exp().Add(x[0].Mul(x[0]).Mul(x[2].Sin()))
+}
+
+func TestFiniteDifferenceHessians(t *testing.T) {
+	x := linalg.Vector{0.5, -0.7, 1.1}
+	exact := linalg.NewDenseMatrix(3, 3)
+	var hessian optim.HessianFunc = autodiff.Hessian(mixedHessianDual)
+	hessian(x, exact)
+
+	H := linalg.NewDenseMatrix(3, 3)
+	for name, test := range map[string]struct {
+		hessian optim.HessianFunc
+		limit   float64
+	}{
+		"values":           {optim.CentralHessianConstantStep(mixedObjective, 1e-4), 1e-6},
+		"exact gradient":   {optim.ForwardHessianFromGradient(mixedObjective, mixedGradient, 1e-8), 1e-6},
+		"central gradient": {optim.ForwardHessianFromGradient(mixedObjective, optim.RiddersGradient(0.1, 3), 1e-4), 1e-4},
+	} {
+		test.hessian(x, H)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				if math.Abs(H.Get(i, j)-exact.Get(i, j)) > test.limit {
+					t.Errorf("%s: H[%d][%d]: expected %v, got %v", name, i, j, exact.Get(i, j), H.Get(i, j))
+				}
+				if H.Get(i, j) != H.Get(j, i) {
+					t.Errorf("%s: expected a symmetric Hessian", name)
+				}
+			}
+		}
+		if x[0] != 0.5 || x[1] != -0.7 || x[2] != 1.1 {
+			t.Fatalf("%s: x was not restored, got %v", name, x)
+		}
+	}
+}
+
+func TestHessianVectorProduct(t *testing.T) {
+	x := linalg.Vector{0.5, -0.7, 1.1}
+	exact := linalg.NewDenseMatrix(3, 3)
+	autodiff.Hessian(mixedHessianDual)(x, exact)
+
+	evaluations := 0
+	counted := func(x linalg.Vector, f optim.ObjectiveFunc, g linalg.Vector) float64 {
+		evaluations++
+		return mixedGradient(x, f, g)
+	}
+	product := optim.HessianVectorProduct(mixedObjective, counted, 1e-7)
+	Hv := linalg.NewVector(3)
+	for k, v := range []linalg.Vector{{1, 0, 0}, {0.3, -2, 0.5}, {1e-3, 1e-3, -1e-3}, {0, 0, 0}} {
+		product(x, v, Hv)
+		for i := 0; i < 3; i++ {
+			want := 0.0
+			for j := 0; j < 3; j++ {
+				want += exact.Get(i, j) * v[j]
+			}
+			if math.Abs(Hv[i]-want) > 1e-6*math.Max(1, math.Abs(want)) {
+				t.Errorf("v = %v: (Hv)[%d]: expected %v, got %v", v, i, want, Hv[i])
+			}
+		}
+		// the gradient at x is evaluated once, and the zero vector needs no gradient
+		if want := k + 2; k < 3 && evaluations != want {
+			t.Errorf("expected %d gradient evaluations, got %d", want, evaluations)
+		}
+	}
+	if evaluations != 4 {
+		t.Errorf("expected 4 gradient evaluations, got %d", evaluations)
+	}
+
+	// a new point needs a new gradient
+	product(linalg.Vector{0.4, -0.7, 1.1}, linalg.Vector{1, 1, 1}, Hv)
+	if evaluations != 6 {
+		t.Errorf("expected 6 gradient evaluations, got %d", evaluations)
+	}
+}
@@ -0,0 +1,264 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var HOMOTOPY_INITIAL_STEP = 0.05        // initial arclength step
+var HOMOTOPY_MIN_STEP = 1e-8            // smallest arclength step before giving up
+var HOMOTOPY_MAX_STEP = 1.0             // largest arclength step
+var HOMOTOPY_CORRECTOR_ITERATIONS = 6   // Newton iterations of the corrector
+var HOMOTOPY_CORRECTOR_TOLERANCE = 1e-9 // corrector tolerance relative to 1 + ||(x, lambda)||
+
+// HomotopyProblem is the homotopy
+//
+//	H(x, lambda) = lambda * F(x) + (1 - lambda) * G(x)
+//
+// from the start system G, whose root is the starting point x0, to the target system
+// F. A nil G is the fixed-point homotopy G(x) = x - x0. The Jacobians are optional;
+// missing Jacobians are approximated by central differences.
+type HomotopyProblem struct {
+	F         VectorFunc
+	Jacobian  JacobianFunc
+	G         VectorFunc
+	GJacobian JacobianFunc
+}
+
+// ContinuationPoint is a point of the path traced by the continuation solver.
+type ContinuationPoint struct {
+	X            linalg.Vector
+	Lambda       float64
+	Step         float64 // arclength step that reached the point
+	TurningPoint bool    // lambda reversed direction between the previous point and this one
+}
+
+// continuationSolution is the result of a continuation. Path holds every accepted point
+// from (x0, 0) on, and the root of F found from the end of the path.
+type continuationSolution struct {
+	ValidSolution bool
+	Result        linalg.Vector
+	ResidualNorm  float64 // ||F(Result)||
+	Path          []ContinuationPoint
+	TurningPoints int
+	Steps         int // accepted predictor-corrector steps
+	Rejections    int // steps rejected and retried with a smaller step
+	Evaluations   int // evaluations of H and of F
+}
+
+// continuationSolver traces the zero curve of a homotopy from lambda = 0 to lambda = 1
+// with a pseudo-arclength predictor-corrector method (Allgower and Georg, Introduction
+// to Numerical Continuation Methods, 1990). With y = (x, lambda), each step predicts
+// along the unit tangent t of the curve, the null vector of [H_x H_lambda], and corrects
+// with Newton's method on
+//
+//	H(y) = 0,  t^T * (y - y_predicted) = 0
+//
+// whose Jacobian stays nonsingular at turning points where lambda reverses direction.
+// The step is doubled after fast corrections and halved after failed ones. Once the
+// path crosses lambda = 1, the point at lambda = 1 interpolated between the last two
+// points is polished by Newton's method on F.
+type continuationSolver struct{}
+
+func (s *continuationSolver) Solve(problem *HomotopyProblem, x0 linalg.Vector, options ...func(*solveOptions)) *continuationSolution {
+	opts := newSolveOptions(x0, options...)
+	return s.solve(problem, x0, opts)
+}
+
+func (s *continuationSolver) solve(problem *HomotopyProblem, x0 linalg.Vector, opts *solveOptions) *continuationSolution {
+	n := x0.Len()
+	F, JF := problem.F, problem.Jacobian
+	if JF == nil {
+		JF = CentralJacobianConstantStep(1e-6, ConstraintFunc(F), n)
+	}
+	G, JG := problem.G, problem.GJacobian
+	if G == nil {
+		start := linalg.NewVector(n)
+		copy(start, x0)
+		G = func(x, gx linalg.Vector) {
+			for i := range x {
+				gx[i] = x[i] - start[i]
+			}
+		}
+		JG = func(x linalg.Vector, J linalg.Matrix) {
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					J.Set(i, j, 0)
+				}
+				J.Set(i, i, 1)
+			}
+		}
+	}
+	if JG == nil {
+		JG = CentralJacobianConstantStep(1e-6, ConstraintFunc(G), n)
+	}
+
+	sol := &continuationSolution{}
+	fx := linalg.NewVector(n)
+	gx := linalg.NewVector(n)
+	JFx := linalg.NewDenseMatrix(n, n)
+	JGx := linalg.NewDenseMatrix(n, n)
+
+	// homotopy writes H(y) into h and the first n rows of the augmented Jacobian
+	// [H_x H_lambda] into A.
+	homotopy := func(y, h linalg.Vector, A linalg.Matrix) {
+		sol.Evaluations++
+		x, lambda := y[:n], y[n]
+		fx.Zero()
+		F(x, fx)
+		gx.Zero()
+		G(x, gx)
+		for i := 0; i < n; i++ {
+			h[i] = lambda*fx[i] + (1-lambda)*gx[i]
+		}
+		JF(x, JFx)
+		JG(x, JGx)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				A.Set(i, j, lambda*JFx.Get(i, j)+(1-lambda)*JGx.Get(i, j))
+			}
+			A.Set(i, n, fx[i]-gx[i])
+		}
+	}
+
+	// tangent writes the unit tangent at y into t, oriented along the previous tangent
+	// tPrev, using the last row of A for the orientation constraint tPrev^T * t = 1.
+	A := linalg.NewDenseMatrix(n+1, n+1)
+	h := linalg.NewVector(n + 1)
+	rhs := linalg.NewVector(n + 1)
+	tangent := func(y, tPrev, t linalg.Vector) bool {
+		homotopy(y, h, A)
+		for j := 0; j <= n; j++ {
+			A.Set(n, j, tPrev[j])
+		}
+		rhs.Zero()
+		rhs[n] = 1
+		lu, err := linalg.FactorLU(A)
+		if err != nil {
+			return false
+		}
+		lu.Solve(rhs, t)
+		blas.SCAL(1/blas.NRM2(t), t)
+		return true
+	}
+
+	y := linalg.NewVector(n + 1)
+	copy(y, x0)
+	t := linalg.NewVector(n + 1)
+	tPrev := linalg.NewVector(n + 1)
+	tPrev[n] = 1
+	yp := linalg.NewVector(n + 1)
+	yc := linalg.NewVector(n + 1)
+	dy := linalg.NewVector(n + 1)
+	record := func(y linalg.Vector, step float64, turning bool) {
+		x := linalg.NewVector(n)
+		copy(x, y[:n])
+		sol.Path = append(sol.Path, ContinuationPoint{X: x, Lambda: y[n], Step: step, TurningPoint: turning})
+	}
+	record(y, 0, false)
+	step := HOMOTOPY_INITIAL_STEP
+	for ok := tangent(y, tPrev, t); ok && sol.Steps < opts.maxIterations; {
+		// predictor
+		blas.COPY(y, yp)
+		blas.AXPY(step, t, yp)
+
+		// corrector: Newton's method on H(y) = 0, t^T * (y - yp) = 0
+		blas.COPY(yp, yc)
+		converged := false
+		iterations := 0
+		previous := math.Inf(1)
+		for ; iterations < HOMOTOPY_CORRECTOR_ITERATIONS; iterations++ {
+			homotopy(yc, h, A)
+			h[n] = 0
+			for j := 0; j <= n; j++ {
+				A.Set(n, j, t[j])
+				h[n] += t[j] * (yc[j] - yp[j])
+			}
+			lu, err := linalg.FactorLU(A)
+			if err != nil {
+				break
+			}
+			blas.SCAL(-1, h)
+			lu.Solve(h, dy)
+			blas.AXPY(1, dy, yc)
+			size := blas.NRM2(dy)
+			if math.IsNaN(size) || size > 0.5*previous {
+				break
+			}
+			previous = size
+			if size <= HOMOTOPY_CORRECTOR_TOLERANCE*(1+blas.NRM2(yc)) {
+				converged = true
+				iterations++
+				break
+			}
+		}
+		if !converged {
+			sol.Rejections++
+			step *= 0.5
+			if step < HOMOTOPY_MIN_STEP {
+				break
+			}
+			continue
+		}
+
+		// the new tangent, and the turning points where lambda reverses
+		blas.COPY(t, tPrev)
+		if !tangent(yc, tPrev, t) {
+			break
+		}
+		sol.Steps++
+		turning := (t[n] > 0) != (tPrev[n] > 0)
+		if turning {
+			sol.TurningPoints++
+		}
+		lambdaPrev := y[n]
+		blas.COPY(yc, y)
+		record(y, step, turning)
+
+		if y[n] >= 1 {
+			// interpolate the crossing of lambda = 1 between the last two points
+			w := (1 - lambdaPrev) / (y[n] - lambdaPrev)
+			prev := sol.Path[len(sol.Path)-2].X
+			x := linalg.NewVector(n)
+			for i := range x {
+				x[i] = prev[i] + w*(y[i]-prev[i])
+			}
+			return s.finish(sol, x, F, JF, opts)
+		}
+		if y[n] < 0 && t[n] < 0 {
+			// the path returned to lambda < 0 without reaching the target
+			break
+		}
+
+		if iterations <= 2 {
+			step = math.Min(2*step, HOMOTOPY_MAX_STEP)
+		} else if iterations >= HOMOTOPY_CORRECTOR_ITERATIONS-1 {
+			step *= 0.5
+		}
+	}
+	sol.Result = y[:n]
+	fx.Zero()
+	F(sol.Result, fx)
+	sol.ResidualNorm = blas.NRM2(fx)
+	return sol
+}
+
+// finish polishes the end of the path with Newton's method on F.
+func (s *continuationSolver) finish(sol *continuationSolution, x linalg.Vector, F VectorFunc, JF JacobianFunc, opts *solveOptions) *continuationSolution {
+	newton := *opts
+	newton.rootMethod = NewtonRoot
+	newton.maxIterations = 50
+	e := &rootEvaluator{f: F, jacobian: JF}
+	root := lineSearchRoot(e, x, &newton)
+	sol.Evaluations += e.evaluations
+	sol.Result = root.Result
+	sol.ResidualNorm = blas.NRM2(root.Residual)
+	sol.ValidSolution = root.Status == RootConverged
+	return sol
+}
+
+func NewContinuationSolver() *continuationSolver {
+	return &continuationSolver{}
+}
@@ -0,0 +1,93 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// cubicSystem is f(x) = x^3 - 2 * x + 2, on which Newton's method from 0 or 1.5 is
+// trapped by the local minimum of |f| at sqrt(2/3).
+func cubicSystem(x, fx linalg.Vector) {
+	fx[0] = x[0]*x[0]*x[0] - 2*x[0] + 2
+}
+
+func TestContinuationCubic(t *testing.T) {
+	const root = -1.7692923542386314
+	for _, x0 := range []float64{0, 1.5} {
+		newton := optim.RootFind(cubicSystem, nil, linalg.Vector{x0}, optim.WithRootMethod(optim.NewtonRoot))
+		if newton.ValidSolution {
+			t.Fatalf("x0 = %v: expected Newton's method to fail, got %v", x0, newton.Result)
+		}
+
+		sol := optim.NewContinuationSolver().Solve(&optim.HomotopyProblem{F: cubicSystem}, linalg.Vector{x0})
+		if !sol.ValidSolution || math.Abs(sol.Result[0]-root) > 1e-10 || sol.ResidualNorm > 1e-8 {
+			t.Fatalf("x0 = %v: expected the root %v, got %+v", x0, root, sol)
+		}
+
+		// the path starts at (x0, 0), ends past lambda = 1, and stays on the zero curve of
+		// H = lambda * f(x) + (1 - lambda) * (x - x0)
+		first, last := sol.Path[0], sol.Path[len(sol.Path)-1]
+		if first.X[0] != x0 || first.Lambda != 0 || last.Lambda < 1 || len(sol.Path) != sol.Steps+1 {
+			t.Errorf("x0 = %v: unexpected path ends %+v and %+v", x0, first, last)
+		}
+		turning := 0
+		fx := linalg.NewVector(1)
+		for k, p := range sol.Path {
+			cubicSystem(p.X, fx)
+			if h := p.Lambda*fx[0] + (1-p.Lambda)*(p.X[0]-x0); math.Abs(h) > 1e-8 {
+				t.Errorf("x0 = %v: point %d is off the path, H = %v", x0, k, h)
+			}
+			if k >= 2 {
+				before := sol.Path[k-1].Lambda - sol.Path[k-2].Lambda
+				after := p.Lambda - sol.Path[k-1].Lambda
+				if before*after < 0 {
+					turning++
+				}
+			}
+		}
+		if sol.TurningPoints != turning {
+			t.Errorf("x0 = %v: expected %d turning points, got %d", x0, turning, sol.TurningPoints)
+		}
+		// lambda(x) = (x0 - x) / (f(x) - x + x0) has two turning points from 1.5 and none from 0
+		if x0 == 1.5 && sol.TurningPoints != 2 || x0 == 0 && sol.TurningPoints != 0 {
+			t.Errorf("x0 = %v: unexpected %d turning points", x0, sol.TurningPoints)
+		}
+	}
+}
+
+func TestContinuationNewtonHomotopy(t *testing.T) {
+	// the circle x^2 + y^2 = 4 and the curve y = 1 - e^x, with the Newton homotopy
+	// G(x) = F(x) - F(x0)
+	F := func(x, fx linalg.Vector) {
+		fx[0] = x[0]*x[0] + x[1]*x[1] - 4
+		fx[1] = math.Exp(x[0]) + x[1] - 1
+	}
+	x0 := linalg.Vector{3, 3}
+	f0 := linalg.NewVector(2)
+	F(x0, f0)
+	G := func(x, gx linalg.Vector) {
+		F(x, gx)
+		for i := range gx {
+			gx[i] -= f0[i]
+		}
+	}
+	jacobian := func(x linalg.Vector, J linalg.Matrix) {
+		J.Set(0, 0, 2*x[0])
+		J.Set(0, 1, 2*x[1])
+		J.Set(1, 0, math.Exp(x[0]))
+		J.Set(1, 1, 1)
+	}
+	problem := &optim.HomotopyProblem{F: F, Jacobian: jacobian, G: G, GJacobian: jacobian}
+	sol := optim.NewContinuationSolver().Solve(problem, x0)
+	if !sol.ValidSolution || sol.ResidualNorm > 1e-8 {
+		t.Fatalf("expected a root, got %+v", sol)
+	}
+	fx := linalg.NewVector(2)
+	F(sol.Result, fx)
+	if math.Abs(fx[0]) > 1e-8 || math.Abs(fx[1]) > 1e-8 {
+		t.Errorf("expected a root, got F = %v at %v", fx, sol.Result)
+	}
+}
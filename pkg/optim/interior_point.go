@@ -0,0 +1,578 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// KKTSystem selects how the primal-dual Newton system of the interior point method is factored.
+type KKTSystem int
+
+const (
+	// DenseKKT factors the KKT matrix with a dense Bunch-Kaufman LDL^T factorization.
+	DenseKKT KKTSystem = iota
+	// SparseKKT factors the KKT matrix with a sparse LDL^T factorization.
+	SparseKKT
+)
+
+// WithKKTSystem sets the linear algebra used for the KKT systems of the interior point method.
+func WithKKTSystem(system KKTSystem) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.kktSystem = system
+	}
+}
+
+var IPM_MU_INIT = 0.1
+var IPM_KAPPA_EPSILON = 10.0 // barrier subproblem tolerance relative to mu
+var IPM_KAPPA_MU = 0.2       // linear decrease factor of mu
+var IPM_THETA_MU = 1.5       // superlinear decrease exponent of mu
+var IPM_TAU_MIN = 0.99       // smallest fraction-to-boundary parameter
+
+// Parameters of the filter line search and the inertia correction, following
+// Wachter and Biegler, "On the implementation of an interior-point filter line-search
+// algorithm for large-scale nonlinear programming", Math. Program., 2006.
+const (
+	ipmKappaSigma    = 1e10
+	ipmGammaTheta    = 1e-5
+	ipmGammaPhi      = 1e-8
+	ipmDelta         = 1.0
+	ipmSTheta        = 1.1
+	ipmSPhi          = 2.3
+	ipmEtaPhi        = 1e-4
+	ipmMinStep       = 1e-12
+	ipmSlackMin      = 1e-2
+	ipmDeltaWInit    = 1e-4
+	ipmDeltaWMin     = 1e-20
+	ipmDeltaWMax     = 1e40
+	ipmKappaWMinus   = 1.0 / 3.0
+	ipmKappaWPlus    = 8.0
+	ipmKappaWPlusBar = 100.0
+	ipmDeltaC        = 1e-8
+	ipmKappaC        = 0.25
+	ipmScaleMax      = 100.0
+)
+
+// interiorPointIteration records the progress of one interior point iteration.
+type interiorPointIteration struct {
+	Iteration           int
+	Objective           float64
+	PrimalInfeasibility float64
+	DualInfeasibility   float64
+	Mu                  float64
+	StepLength          float64
+	Regularization      float64
+}
+
+// interiorPointSolution is the result of the interior point method.
+type interiorPointSolution struct {
+	constrainedSolution
+	History []interiorPointIteration
+}
+
+// kktFactorization is a factorization of a symmetric KKT matrix that reports its inertia.
+type kktFactorization interface {
+	Solve(b linalg.Vector, x linalg.Vector)
+	Inertia() (positive, negative, zero int)
+}
+
+// interiorPointSolver is a primal-dual interior point method for
+//
+//	minimize f(x) subject to g(x) = 0, h(x) + s = 0, s >= 0
+//
+// with a log-barrier on the slacks, monotone (Fiacco-McCormick) barrier updates, the
+// fraction-to-boundary rule, a filter line search and inertia correction of the KKT
+// matrix. The Hessian of the Lagrangian is approximated by damped BFGS updates. Simple
+// bounds are treated as inequality constraints.
+type interiorPointSolver struct{}
+
+// ipmIterate holds the problem functions evaluated at a point.
+type ipmIterate struct {
+	x     linalg.Vector
+	f     float64
+	gradF linalg.Vector
+	cE    linalg.Vector
+	cI    linalg.Vector
+	JE    linalg.Matrix
+	JI    linalg.Matrix
+}
+
+func (s *interiorPointSolver) Solve(problem *ConstrainedProblem, x0 linalg.Vector, options ...func(*solveOptions)) *interiorPointSolution {
+	opts := newSolveOptions(x0, options...)
+	if check := failedGradientCheck(problem.Objective, x0, opts); check != nil {
+		return &interiorPointSolution{constrainedSolution: constrainedSolution{
+			Result: vectorCopy(x0), Objective: problem.Objective(x0), GradientCheck: check,
+		}}
+	}
+	return s.solve(problem, x0, opts)
+}
+
+func (s *interiorPointSolver) solve(problem *ConstrainedProblem, x0 linalg.Vector, opts *solveOptions) *interiorPointSolution {
+	f := problem.Objective
+	evaluateGradient := opts.gradientFunc
+	tolerance := opts.tolerance
+	maxIterations := opts.maxIterations
+	bounds := Bounds{}
+	if opts.bounds != nil {
+		bounds = *opts.bounds
+	}
+
+	n := x0.Len()
+	bounds.check(n)
+	e := newConstraintEvaluator(problem, n)
+	me := e.me
+	mh := e.mi
+
+	// finite bounds become the inequality rows l - x <= 0 and x - u <= 0
+	boundRows := make([]int, 0, 2*n)
+	boundSigns := make([]float64, 0, 2*n)
+	for i := 0; i < n; i++ {
+		if !math.IsInf(bounds.lower(i), -1) {
+			boundRows = append(boundRows, i)
+			boundSigns = append(boundSigns, -1)
+		}
+		if !math.IsInf(bounds.upper(i), 1) {
+			boundRows = append(boundRows, i)
+			boundSigns = append(boundSigns, 1)
+		}
+	}
+	mi := mh + len(boundRows)
+
+	hBuffer := linalg.NewVector(mh)
+	JhBuffer := linalg.NewDenseMatrix(mh, n)
+	newIterate := func() *ipmIterate {
+		return &ipmIterate{
+			x:     linalg.NewVector(n),
+			gradF: linalg.NewVector(n),
+			cE:    linalg.NewVector(me),
+			cI:    linalg.NewVector(mi),
+			JE:    linalg.NewDenseMatrix(me, n),
+			JI:    linalg.NewDenseMatrix(mi, n),
+		}
+	}
+	evaluate := func(it *ipmIterate, derivatives bool) {
+		it.f = f(it.x)
+		e.equality(it.x, it.cE)
+		e.inequality(it.x, hBuffer)
+		copy(it.cI, hBuffer)
+		for k, i := range boundRows {
+			if boundSigns[k] < 0 {
+				it.cI[mh+k] = bounds.lower(i) - it.x[i]
+			} else {
+				it.cI[mh+k] = it.x[i] - bounds.upper(i)
+			}
+		}
+		if derivatives {
+			evaluateGradient(it.x, f, it.gradF)
+			e.jacobians(it.x, it.JE, JhBuffer)
+			for i := 0; i < mh; i++ {
+				for j := 0; j < n; j++ {
+					it.JI.Set(i, j, JhBuffer.Get(i, j))
+				}
+			}
+			for k, i := range boundRows {
+				it.JI.Set(mh+k, i, boundSigns[k])
+			}
+		}
+	}
+
+	cur := newIterate()
+	trial := newIterate()
+	blas.COPY(x0, cur.x)
+	evaluate(cur, true)
+
+	// slacks strictly inside their bounds and unit bound multipliers
+	sl := linalg.NewVector(mi)
+	z := linalg.NewVector(mi)
+	yE := linalg.NewVector(me)
+	yI := linalg.NewVector(mi)
+	for i := range sl {
+		sl[i] = math.Max(-cur.cI[i], ipmSlackMin)
+		z[i] = 1
+		yI[i] = 1
+	}
+
+	B := linalg.NewDenseMatrix(n, n)
+	B.Identity()
+	N := n + mi + me + mi
+	rhs := linalg.NewVector(N)
+	sol := linalg.NewVector(N)
+	dx := linalg.NewVector(n)
+	ds := linalg.NewVector(mi)
+	dyE := linalg.NewVector(me)
+	dyI := linalg.NewVector(mi)
+	dz := linalg.NewVector(mi)
+	trialS := linalg.NewVector(mi)
+	gradL := linalg.NewVector(n)
+	gradL0 := linalg.NewVector(n)
+	yk := linalg.NewVector(n)
+	sk := linalg.NewVector(n)
+
+	mu := IPM_MU_INIT
+	tau := math.Max(IPM_TAU_MIN, 1-mu)
+	deltaWLast := 0.0
+
+	constraintViolation := func(it *ipmIterate, sl linalg.Vector) float64 {
+		theta := blas.ASUM(it.cE)
+		for i := range sl {
+			theta += math.Abs(it.cI[i] + sl[i])
+		}
+		return theta
+	}
+	barrier := func(it *ipmIterate, sl linalg.Vector) float64 {
+		phi := it.f
+		for i := range sl {
+			phi -= mu * math.Log(sl[i])
+		}
+		return phi
+	}
+
+	// residuals of the barrier problem; returns the scaled optimality error for the given mu
+	var dualInf, primalInf, compl float64
+	optimalityError := func(mu float64) float64 {
+		lagrangianGradient(cur.gradF, cur.JE, yE, cur.JI, yI, gradL)
+		dualInf = math.Abs(gradL[blas.IAMAX(gradL)])
+		for i := range z {
+			dualInf = math.Max(dualInf, math.Abs(yI[i]-z[i]))
+		}
+		primalInf = 0.0
+		for i := range cur.cE {
+			primalInf = math.Max(primalInf, math.Abs(cur.cE[i]))
+		}
+		for i := range sl {
+			primalInf = math.Max(primalInf, math.Abs(cur.cI[i]+sl[i]))
+		}
+		compl = 0.0
+		for i := range sl {
+			compl = math.Max(compl, math.Abs(sl[i]*z[i]-mu))
+		}
+		m := float64(me + mi)
+		sd, sc := 1.0, 1.0
+		if m > 0 {
+			sd = math.Max(ipmScaleMax, (blas.ASUM(yE)+blas.ASUM(yI)+blas.ASUM(z))/(m+float64(mi))) / ipmScaleMax
+			sc = math.Max(ipmScaleMax, blas.ASUM(z)/math.Max(float64(mi), 1)) / ipmScaleMax
+		}
+		return math.Max(dualInf/sd, math.Max(primalInf, compl/sc))
+	}
+
+	theta0 := constraintViolation(cur, sl)
+	thetaMax := 1e4 * math.Max(1, theta0)
+	thetaMin := 1e-4 * math.Max(1, theta0)
+	type filterEntry struct{ theta, phi float64 }
+	filter := []filterEntry{{thetaMax, math.Inf(-1)}}
+
+	history := make([]interiorPointIteration, 0)
+	iter := 0
+	converged := false
+	for iter < maxIterations {
+		if optimalityError(0) <= tolerance {
+			converged = true
+			break
+		}
+
+		// Fiacco-McCormick: decrease mu once the barrier problem is solved well enough
+		for mu > tolerance/10 && optimalityError(mu) <= IPM_KAPPA_EPSILON*mu {
+			mu = math.Max(tolerance/10, math.Min(IPM_KAPPA_MU*mu, math.Pow(mu, IPM_THETA_MU)))
+			tau = math.Max(IPM_TAU_MIN, 1-mu)
+			filter = []filterEntry{{thetaMax, math.Inf(-1)}}
+		}
+
+		// right-hand side of the primal-dual system
+		lagrangianGradient(cur.gradF, cur.JE, yE, cur.JI, yI, gradL)
+		for j := 0; j < n; j++ {
+			rhs[j] = -gradL[j]
+		}
+		for i := 0; i < mi; i++ {
+			rhs[n+i] = -(yI[i] - mu/sl[i])
+			rhs[n+mi+me+i] = -(cur.cI[i] + sl[i])
+		}
+		for i := 0; i < me; i++ {
+			rhs[n+mi+i] = -cur.cE[i]
+		}
+
+		// factor with inertia correction
+		deltaW, deltaC := 0.0, 0.0
+		var factor kktFactorization
+		for {
+			factor = s.factorKKT(opts.kktSystem, B, cur, sl, z, deltaW, deltaC)
+			pos, neg, zero := 0, 0, N
+			if factor != nil {
+				pos, neg, zero = factor.Inertia()
+			}
+			if pos == n+mi && neg == me+mi && zero == 0 {
+				break
+			}
+			if zero > 0 && deltaC == 0 {
+				deltaC = ipmDeltaC * math.Pow(mu, ipmKappaC)
+			}
+			if deltaW == 0 {
+				if deltaWLast == 0 {
+					deltaW = ipmDeltaWInit
+				} else {
+					deltaW = math.Max(ipmDeltaWMin, ipmKappaWMinus*deltaWLast)
+				}
+			} else if deltaWLast == 0 {
+				deltaW *= ipmKappaWPlusBar
+			} else {
+				deltaW *= ipmKappaWPlus
+			}
+			if deltaW > ipmDeltaWMax {
+				factor = nil
+				break
+			}
+		}
+		if factor == nil {
+			break
+		}
+		if deltaW > 0 {
+			deltaWLast = deltaW
+		}
+
+		factor.Solve(rhs, sol)
+		copy(dx, sol[:n])
+		copy(ds, sol[n:n+mi])
+		copy(dyE, sol[n+mi:n+mi+me])
+		copy(dyI, sol[n+mi+me:])
+		for i := range dz {
+			dz[i] = mu/sl[i] - z[i] - z[i]/sl[i]*ds[i]
+		}
+
+		// fraction-to-boundary rule for the slacks and the bound multipliers
+		alphaMax := 1.0
+		alphaZ := 1.0
+		for i := range sl {
+			if ds[i] < 0 {
+				alphaMax = math.Min(alphaMax, -tau*sl[i]/ds[i])
+			}
+			if dz[i] < 0 {
+				alphaZ = math.Min(alphaZ, -tau*z[i]/dz[i])
+			}
+		}
+
+		// filter line search
+		theta := constraintViolation(cur, sl)
+		phi := barrier(cur, sl)
+		gradPhiD := blas.DOT(cur.gradF, dx)
+		for i := range sl {
+			gradPhiD -= mu * ds[i] / sl[i]
+		}
+		alpha := alphaMax
+		accepted := false
+		augment := false
+		for alpha >= ipmMinStep {
+			blas.COPY(cur.x, trial.x)
+			blas.AXPY(alpha, dx, trial.x)
+			blas.COPY(sl, trialS)
+			blas.AXPY(alpha, ds, trialS)
+			evaluate(trial, false)
+			thetaT := constraintViolation(trial, trialS)
+			phiT := barrier(trial, trialS)
+
+			inFilter := thetaT >= thetaMax
+			for _, entry := range filter {
+				if thetaT >= entry.theta && phiT >= entry.phi {
+					inFilter = true
+					break
+				}
+			}
+			if !inFilter && !math.IsNaN(phiT) {
+				switching := gradPhiD < 0 && theta <= thetaMin &&
+					alpha*math.Pow(-gradPhiD, ipmSPhi) > ipmDelta*math.Pow(theta, ipmSTheta)
+				if switching {
+					if phiT <= phi+ipmEtaPhi*alpha*gradPhiD {
+						accepted = true
+						break
+					}
+				} else if thetaT <= (1-ipmGammaTheta)*theta || phiT <= phi-ipmGammaPhi*theta {
+					accepted = true
+					augment = true
+					break
+				}
+			}
+			alpha *= 0.5
+		}
+		if !accepted {
+			// the quasi-Newton model is poor; restart it before giving up
+			if !isIdentity(B) {
+				B.Identity()
+				continue
+			}
+			break
+		}
+		if augment {
+			filter = append(filter, filterEntry{(1 - ipmGammaTheta) * theta, phi - ipmGammaPhi*theta})
+		}
+
+		// take the step and update the multipliers
+		blas.AXPY(alpha, dyE, yE)
+		blas.AXPY(alpha, dyI, yI)
+		blas.AXPY(alphaZ, dz, z)
+		blas.COPY(trialS, sl)
+		for i := range z {
+			// keep the primal-dual Hessian close to the primal one
+			z[i] = math.Max(math.Min(z[i], ipmKappaSigma*mu/sl[i]), mu/(ipmKappaSigma*sl[i]))
+		}
+		evaluate(trial, true)
+
+		// damped BFGS update of the Lagrangian Hessian
+		lagrangianGradient(cur.gradF, cur.JE, yE, cur.JI, yI, gradL0)
+		lagrangianGradient(trial.gradF, trial.JE, yE, trial.JI, yI, gradL)
+		blas.COPY(trial.x, sk)
+		blas.AXPY(-1.0, cur.x, sk)
+		blas.COPY(gradL, yk)
+		blas.AXPY(-1.0, gradL0, yk)
+		UpdateHessianDampedBFGS(B, yk, sk)
+
+		cur, trial = trial, cur
+		iter++
+
+		optimalityError(mu)
+		history = append(history, interiorPointIteration{
+			Iteration:           iter,
+			Objective:           cur.f,
+			PrimalInfeasibility: primalInf,
+			DualInfeasibility:   dualInf,
+			Mu:                  mu,
+			StepLength:          alpha,
+			Regularization:      deltaW,
+		})
+	}
+
+	optimalityError(0)
+	lagrangianGradient(cur.gradF, cur.JE, yE, cur.JI, yI, gradL)
+	stationarity, primal, complementarity := kktResiduals(cur.x, gradL, cur.cE, cur.cI, yI, Bounds{})
+
+	result := linalg.NewVector(n)
+	blas.COPY(cur.x, result)
+	lambda := linalg.NewVector(me)
+	blas.COPY(yE, lambda)
+	multipliers := linalg.NewVector(mh)
+	copy(multipliers, yI[:mh])
+	activeSet := make([]int, 0, mh)
+	for i := 0; i < mh; i++ {
+		if cur.cI[i] >= -math.Sqrt(tolerance) && multipliers[i] > math.Sqrt(tolerance) {
+			activeSet = append(activeSet, i)
+		}
+	}
+
+	return &interiorPointSolution{
+		constrainedSolution: constrainedSolution{
+			ValidSolution:         converged,
+			Result:                result,
+			Iterations:            iter,
+			Objective:             cur.f,
+			EqualityMultipliers:   lambda,
+			InequalityMultipliers: multipliers,
+			ActiveSet:             activeSet,
+			Stationarity:          stationarity,
+			PrimalInfeasibility:   primal,
+			Complementarity:       complementarity,
+		},
+		History: history,
+	}
+}
+
+// factorKKT assembles and factors the primal-dual matrix
+//
+//	[ B + dw*I    0          JE^T     JI^T   ]
+//	[ 0           S^-1*Z+dw*I 0        I      ]
+//	[ JE          0          -dc*I    0      ]
+//	[ JI          I          0        -dc*I  ]
+//
+// Returns nil if the sparse factorization encounters a zero pivot.
+func (s *interiorPointSolver) factorKKT(system KKTSystem, B linalg.Matrix, it *ipmIterate, sl, z linalg.Vector, deltaW, deltaC float64) kktFactorization {
+	n := B.Rows()
+	mi := sl.Len()
+	me := it.cE.Len()
+	N := n + mi + me + mi
+	oE := n + mi
+	oI := n + mi + me
+
+	if system == SparseKKT {
+		// upper triangle, column by column
+		T := linalg.NewTripletMatrix(N, N)
+		for j := 0; j < n; j++ {
+			for i := 0; i < j; i++ {
+				if v := B.Get(i, j); v != 0 {
+					T.Append(i, j, v)
+				}
+			}
+			T.Append(j, j, B.Get(j, j)+deltaW)
+		}
+		for i := 0; i < mi; i++ {
+			T.Append(n+i, n+i, z[i]/sl[i]+deltaW)
+		}
+		for i := 0; i < me; i++ {
+			for j := 0; j < n; j++ {
+				if v := it.JE.Get(i, j); v != 0 {
+					T.Append(j, oE+i, v)
+				}
+			}
+			T.Append(oE+i, oE+i, -deltaC)
+		}
+		for i := 0; i < mi; i++ {
+			for j := 0; j < n; j++ {
+				if v := it.JI.Get(i, j); v != 0 {
+					T.Append(j, oI+i, v)
+				}
+			}
+			T.Append(n+i, oI+i, 1)
+			T.Append(oI+i, oI+i, -deltaC)
+		}
+		factor, err := linalg.FactorSparseLDL(T.ToCSC())
+		if err != nil {
+			return nil
+		}
+		return factor
+	}
+
+	// lower triangle
+	K := linalg.NewDenseMatrix(N, N)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			K.Set(i, j, B.Get(i, j))
+		}
+		K.Set(i, i, K.Get(i, i)+deltaW)
+	}
+	for i := 0; i < mi; i++ {
+		K.Set(n+i, n+i, z[i]/sl[i]+deltaW)
+	}
+	for i := 0; i < me; i++ {
+		for j := 0; j < n; j++ {
+			K.Set(oE+i, j, it.JE.Get(i, j))
+		}
+		K.Set(oE+i, oE+i, -deltaC)
+	}
+	for i := 0; i < mi; i++ {
+		for j := 0; j < n; j++ {
+			K.Set(oI+i, j, it.JI.Get(i, j))
+		}
+		K.Set(oI+i, n+i, 1)
+		K.Set(oI+i, oI+i, -deltaC)
+	}
+	return linalg.FactorLDL(K)
+}
+
+// isIdentity reports whether B is the identity matrix.
+func isIdentity(B linalg.Matrix) bool {
+	for i := 0; i < B.Rows(); i++ {
+		for j := 0; j < B.Cols(); j++ {
+			v := 0.0
+			if i == j {
+				v = 1
+			}
+			if B.Get(i, j) != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NewInteriorPointSolver returns a primal-dual interior point solver for nonlinear
+// programs with many inequality constraints. Use WithKKTSystem to choose dense or
+// sparse linear algebra.
+func NewInteriorPointSolver() *interiorPointSolver {
+	return &interiorPointSolver{}
+}
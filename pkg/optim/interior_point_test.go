@@ -0,0 +1,84 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func TestInteriorPointSolver_HS071(t *testing.T) {
+	for _, system := range []optim.KKTSystem{optim.DenseKKT, optim.SparseKKT} {
+		solver := optim.NewInteriorPointSolver()
+
+		problem := &optim.ConstrainedProblem{
+			Objective: func(X linalg.Vector) float64 {
+				return X[0]*X[3]*(X[0]+X[1]+X[2]) + X[2]
+			},
+			Equality: func(X linalg.Vector, c linalg.Vector) {
+				c[0] = X[0]*X[0] + X[1]*X[1] + X[2]*X[2] + X[3]*X[3] - 40
+			},
+			NumEquality: 1,
+			Inequality: func(X linalg.Vector, c linalg.Vector) {
+				c[0] = 25 - X[0]*X[1]*X[2]*X[3]
+			},
+			NumInequality: 1,
+		}
+
+		x0 := linalg.Vector{1, 5, 5, 1}
+		solution := solver.Solve(problem, x0,
+			optim.WithTolerance(1e-7),
+			optim.WithKKTSystem(system),
+			optim.WithBounds(optim.Bounds{
+				Lower: linalg.Vector{1, 1, 1, 1},
+				Upper: linalg.Vector{5, 5, 5, 5},
+			}),
+		)
+
+		expected := linalg.Vector{1, 4.74299963, 3.82114998, 1.37940829}
+		if !solution.ValidSolution {
+			t.Errorf("Expected valid solution, got %+v", solution)
+		}
+		for i := range expected {
+			if math.Abs(solution.Result[i]-expected[i]) > 1e-5 {
+				t.Errorf("Expected %v, got %v", expected, solution.Result)
+				break
+			}
+		}
+		if len(solution.History) != solution.Iterations {
+			t.Errorf("Expected %d history entries, got %d", solution.Iterations, len(solution.History))
+		}
+	}
+}
+
+// Minimize the distance to a point outside the polygon of tangents to the unit circle.
+func TestInteriorPointSolver_ManyInequalities(t *testing.T) {
+	const m = 40
+	solver := optim.NewInteriorPointSolver()
+
+	problem := &optim.ConstrainedProblem{
+		Objective: func(X linalg.Vector) float64 {
+			return (X[0]-2)*(X[0]-2) + (X[1]-2)*(X[1]-2)
+		},
+		Inequality: func(X linalg.Vector, c linalg.Vector) {
+			for i := 0; i < m; i++ {
+				a := 2 * math.Pi * float64(i) / m
+				c[i] = math.Cos(a)*X[0] + math.Sin(a)*X[1] - 1
+			}
+		},
+		NumInequality: m,
+	}
+
+	x0 := linalg.Vector{0, 0}
+	solution := solver.Solve(problem, x0, optim.WithTolerance(1e-8))
+
+	// the closest point lies on the facet with normal at 45 degrees
+	r := 1 / math.Sqrt2
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	if math.Abs(solution.Result[0]-r) > 1e-6 || math.Abs(solution.Result[1]-r) > 1e-6 {
+		t.Errorf("Expected [%f %f], got %v", r, r, solution.Result)
+	}
+}
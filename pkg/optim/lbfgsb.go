@@ -0,0 +1,432 @@
+package optim
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// lbfgsbMemory holds the limited-memory BFGS matrix in compact form:
+//
+//	B = theta * I - W * M * W^T, where W = [Y, theta * S]
+//
+// Reference: Byrd, Lu, Nocedal and Zhu, "A Limited Memory Algorithm for Bound
+// Constrained Optimization", SIAM J. Sci. Comput., 1995.
+type lbfgsbMemory struct {
+	m     int
+	theta float64
+	S     []linalg.Vector
+	Y     []linalg.Vector
+	M     linalg.Matrix
+}
+
+func newLBFGSBMemory(m int) *lbfgsbMemory {
+	return &lbfgsbMemory{
+		m:     m,
+		theta: 1.0,
+		M:     linalg.NewDenseMatrix(0, 0),
+	}
+}
+
+// size returns the number of columns of W, 2 * (number of correction pairs).
+func (mem *lbfgsbMemory) size() int {
+	return 2 * len(mem.S)
+}
+
+func (mem *lbfgsbMemory) reset() {
+	mem.theta = 1.0
+	mem.S = nil
+	mem.Y = nil
+	mem.M = linalg.NewDenseMatrix(0, 0)
+}
+
+// update adds the correction pair (s, y) if it satisfies the curvature condition.
+// Returns false if the pair was skipped.
+func (mem *lbfgsbMemory) update(s, y linalg.Vector) bool {
+	sy := blas.DOT(s, y)
+	yy := blas.DOT(y, y)
+	if sy <= 2.2e-16*yy {
+		return false
+	}
+
+	if len(mem.S) == mem.m {
+		mem.S = mem.S[1:]
+		mem.Y = mem.Y[1:]
+	}
+	sk := linalg.NewVector(s.Len())
+	yk := linalg.NewVector(y.Len())
+	blas.COPY(s, sk)
+	blas.COPY(y, yk)
+	mem.S = append(mem.S, sk)
+	mem.Y = append(mem.Y, yk)
+	mem.theta = yy / sy
+
+	// K = [[-D, L^T], [L, theta * S^T * S]], M = K^-1
+	k := len(mem.S)
+	K := linalg.NewDenseMatrix(2*k, 2*k)
+	for i := 0; i < k; i++ {
+		K.Set(i, i, -blas.DOT(mem.S[i], mem.Y[i]))
+		for j := 0; j < i; j++ {
+			lij := blas.DOT(mem.S[i], mem.Y[j])
+			K.Set(k+i, j, lij)
+			K.Set(j, k+i, lij)
+		}
+		for j := 0; j < k; j++ {
+			K.Set(k+i, k+j, mem.theta*blas.DOT(mem.S[i], mem.S[j]))
+		}
+	}
+	lu, err := linalg.FactorLU(K)
+	if err != nil {
+		mem.reset()
+		return false
+	}
+	mem.M = linalg.NewDenseMatrix(2*k, 2*k)
+	lu.Inverse(mem.M)
+	return true
+}
+
+// wRow writes row i of W into w.
+func (mem *lbfgsbMemory) wRow(i int, w linalg.Vector) {
+	k := len(mem.S)
+	for j := 0; j < k; j++ {
+		w[j] = mem.Y[j][i]
+		w[k+j] = mem.theta * mem.S[j][i]
+	}
+}
+
+// wTransposeTimes computes out = W^T * d.
+func (mem *lbfgsbMemory) wTransposeTimes(d linalg.Vector, out linalg.Vector) {
+	k := len(mem.S)
+	for j := 0; j < k; j++ {
+		out[j] = blas.DOT(mem.Y[j], d)
+		out[k+j] = mem.theta * blas.DOT(mem.S[j], d)
+	}
+}
+
+// mulM computes out = M * v.
+func (mem *lbfgsbMemory) mulM(v linalg.Vector, out linalg.Vector) {
+	if v.Len() == 0 {
+		return
+	}
+	blas.GEMV(1.0, mem.M, v, 0.0, out)
+}
+
+// computeGeneralizedCauchyPoint finds the first local minimizer of the quadratic
+// model along the projected steepest descent path x(t) = P(x - t * g).
+//
+// The Cauchy point is written to xcp and c is set to W^T * (xcp - x).
+func computeGeneralizedCauchyPoint(x, g linalg.Vector, bounds Bounds, mem *lbfgsbMemory, xcp, c linalg.Vector) {
+	n := x.Len()
+	theta := mem.theta
+	size := mem.size()
+
+	t := make([]float64, n)
+	d := linalg.NewVector(n)
+	breakpoints := make([]int, 0, n)
+	blas.COPY(x, xcp)
+	for i := range x {
+		ti := math.Inf(1)
+		if g[i] < 0 {
+			ti = (x[i] - bounds.upper(i)) / g[i]
+		} else if g[i] > 0 {
+			ti = (x[i] - bounds.lower(i)) / g[i]
+		}
+		t[i] = ti
+		if ti != 0 {
+			d[i] = -g[i]
+		}
+		if ti > 0 && !math.IsInf(ti, 1) {
+			breakpoints = append(breakpoints, i)
+		}
+	}
+	sort.Slice(breakpoints, func(a, b int) bool {
+		return t[breakpoints[a]] < t[breakpoints[b]]
+	})
+
+	p := linalg.NewVector(size)
+	c.Zero()
+	mem.wTransposeTimes(d, p)
+	Mp := linalg.NewVector(size)
+	Mc := linalg.NewVector(size)
+	Mw := linalg.NewVector(size)
+	wb := linalg.NewVector(size)
+
+	fp := -blas.DOT(d, d)
+	if fp >= 0 {
+		// the projected gradient is zero, x is the Cauchy point
+		return
+	}
+	mem.mulM(p, Mp)
+	fpp := -theta*fp - blas.DOT(p, Mp)
+	fppMin := -2.2e-16 * theta * fp
+	fpp = math.Max(fpp, fppMin)
+	dtMin := -fp / fpp
+	tOld := 0.0
+
+	for _, b := range breakpoints {
+		dt := t[b] - tOld
+		if dtMin < dt {
+			break
+		}
+
+		// variable b reaches its bound
+		if d[b] > 0 {
+			xcp[b] = bounds.upper(b)
+		} else {
+			xcp[b] = bounds.lower(b)
+		}
+		zb := xcp[b] - x[b]
+		blas.AXPY(dt, p, c)
+		gb := g[b]
+		mem.wRow(b, wb)
+		mem.mulM(c, Mc)
+		mem.mulM(p, Mp)
+		mem.mulM(wb, Mw)
+
+		fp += dt*fpp + gb*gb + theta*gb*zb - gb*blas.DOT(wb, Mc)
+		fpp += -theta*gb*gb - 2*gb*blas.DOT(wb, Mp) - gb*gb*blas.DOT(wb, Mw)
+		fpp = math.Max(fpp, fppMin)
+		blas.AXPY(gb, wb, p)
+		d[b] = 0
+		dtMin = -fp / fpp
+		tOld = t[b]
+	}
+
+	dtMin = math.Max(dtMin, 0)
+	tOld += dtMin
+	for i := range x {
+		if d[i] != 0 {
+			xcp[i] = x[i] + tOld*d[i]
+		}
+	}
+	blas.AXPY(dtMin, p, c)
+}
+
+// minimizeSubspace minimizes the quadratic model over the variables that are free
+// at the Cauchy point, using the direct primal method. The result is written to xbar.
+func minimizeSubspace(x, g linalg.Vector, bounds Bounds, mem *lbfgsbMemory, xcp, c, xbar linalg.Vector) {
+	n := x.Len()
+	theta := mem.theta
+	size := mem.size()
+	blas.COPY(xcp, xbar)
+
+	free := make([]int, 0, n)
+	for i := range xcp {
+		if xcp[i] > bounds.lower(i) && xcp[i] < bounds.upper(i) {
+			free = append(free, i)
+		}
+	}
+	if len(free) == 0 {
+		return
+	}
+
+	// rhat = Z^T * (g + theta * (xcp - x) - W * M * c)
+	Mc := linalg.NewVector(size)
+	mem.mulM(c, Mc)
+	w := linalg.NewVector(size)
+	rhat := linalg.NewVector(len(free))
+	for k, i := range free {
+		mem.wRow(i, w)
+		rhat[k] = g[i] + theta*(xcp[i]-x[i]) - blas.DOT(w, Mc)
+	}
+
+	// du = -1/theta * rhat - 1/theta^2 * Z^T * W * (I - 1/theta * M * W^T * Z * Z^T * W)^-1 * M * W^T * Z * rhat
+	du := linalg.NewVector(len(free))
+	blas.CPSC(-1.0/theta, rhat, du)
+	if size > 0 {
+		WZr := linalg.NewVector(size)
+		WZZW := linalg.NewDenseMatrix(size, size)
+		for k, i := range free {
+			mem.wRow(i, w)
+			blas.AXPY(rhat[k], w, WZr)
+			WZZW.AddOuterProduct(w, w, 1.0)
+		}
+		v := linalg.NewVector(size)
+		mem.mulM(WZr, v)
+
+		N := linalg.NewDenseMatrix(size, size)
+		blas.GEMM(-1.0/theta, mem.M, WZZW, 0.0, N)
+		for i := 0; i < size; i++ {
+			N.Set(i, i, N.Get(i, i)+1.0)
+		}
+		lu, err := linalg.FactorLU(N)
+		if err == nil {
+			lu.Solve(v, v)
+			for k, i := range free {
+				mem.wRow(i, w)
+				du[k] -= blas.DOT(w, v) / (theta * theta)
+			}
+		}
+	}
+
+	// project the subspace step onto the box, falling back to truncating the
+	// step if the projection does not give a descent direction
+	for k, i := range free {
+		xbar[i] = math.Max(bounds.lower(i), math.Min(xcp[i]+du[k], bounds.upper(i)))
+	}
+	descent := 0.0
+	for i := range x {
+		descent += (xbar[i] - x[i]) * g[i]
+	}
+	if descent < 0 {
+		return
+	}
+
+	alpha := 1.0
+	for k, i := range free {
+		if du[k] > 0 {
+			alpha = math.Min(alpha, (bounds.upper(i)-xcp[i])/du[k])
+		} else if du[k] < 0 {
+			alpha = math.Min(alpha, (bounds.lower(i)-xcp[i])/du[k])
+		}
+	}
+	for k, i := range free {
+		xbar[i] = xcp[i] + alpha*du[k]
+	}
+}
+
+// projectedLineSearch performs a backtracking search along x(alpha) = P(x + alpha * d),
+// writing the accepted point to x1. Returns the objective at x1 and whether a
+// sufficient decrease was found.
+func projectedLineSearch(f ObjectiveFunc, x, g, d linalg.Vector, f0, alpha float64, bounds Bounds, x1 linalg.Vector) (float64, bool) {
+	const c1 = 1e-4
+	const maxSteps = 30
+
+	for step := 0; step < maxSteps; step++ {
+		blas.COPY(x, x1)
+		blas.AXPY(alpha, d, x1)
+		bounds.Project(x1)
+		f1 := f(x1)
+
+		gd := 0.0
+		for i := range x {
+			gd += g[i] * (x1[i] - x[i])
+		}
+		if f1 <= f0+c1*gd {
+			return f1, true
+		}
+
+		// backtrack using the minimizer of the quadratic interpolant, safeguarded
+		gd0 := blas.DOT(g, d)
+		denom := 2 * (f1 - f0 - gd0*alpha)
+		next := 0.5 * alpha
+		if denom > 0 {
+			next = -gd0 * alpha * alpha / denom
+		}
+		alpha = math.Max(0.1*alpha, math.Min(next, 0.5*alpha))
+	}
+	blas.COPY(x, x1)
+	return f0, false
+}
+
+// LBFGSB_FACTR scales machine epsilon to give the relative reduction in the objective
+// below which L-BFGS-B stops, as in the reference implementation. Tying the reduction to
+// the gradient tolerance instead stops objectives with a large constant part, such as
+// the augmented Lagrangian at a big penalty, long before their gradient is small.
+var LBFGSB_FACTR = 1e1
+
+type lbfgsbSolver struct{}
+
+func (s *lbfgsbSolver) Solve(f ObjectiveFunc, x0 linalg.Vector, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(x0, options...)
+	if check := failedGradientCheck(f, x0, opts); check != nil {
+		return &solution{Result: vectorCopy(x0), Objective: f(x0), GradientCheck: check}
+	}
+	return s.solve(f, x0, opts)
+}
+
+func (s *lbfgsbSolver) solve(f ObjectiveFunc, x0 linalg.Vector, opts *solveOptions) *solution {
+	evaluateGradient := opts.gradientFunc
+	tolerance := opts.tolerance
+	maxIterations := opts.maxIterations
+	bounds := Bounds{}
+	if opts.bounds != nil {
+		bounds = *opts.bounds
+	}
+
+	n := x0.Len()
+	bounds.check(n)
+	mem := newLBFGSBMemory(opts.memory)
+
+	x := linalg.NewVector(n)
+	x1 := linalg.NewVector(n)
+	g := linalg.NewVector(n)
+	g1 := linalg.NewVector(n)
+	xcp := linalg.NewVector(n)
+	xbar := linalg.NewVector(n)
+	d := linalg.NewVector(n)
+	sk := linalg.NewVector(n)
+	yk := linalg.NewVector(n)
+
+	blas.COPY(x0, x)
+	bounds.Project(x)
+	f0 := math.Inf(1)
+	f1 := f(x)
+	evaluateGradient(x, f, g)
+	pgNorm := bounds.projectedGradientNorm(x, g)
+
+	iter := 0
+	converged := pgNorm <= tolerance
+	for !converged && iter < maxIterations {
+		c := linalg.NewVector(mem.size())
+		computeGeneralizedCauchyPoint(x, g, bounds, mem, xcp, c)
+		minimizeSubspace(x, g, bounds, mem, xcp, c, xbar)
+
+		blas.COPY(xbar, d)
+		blas.AXPY(-1.0, x, d)
+		alpha := 1.0
+		if mem.size() == 0 {
+			alpha = math.Min(1.0, 1.0/blas.NRM2(d))
+		}
+
+		fNew := f1
+		ok := blas.DOT(g, d) < 0
+		if ok {
+			fNew, ok = projectedLineSearch(f, x, g, d, f1, alpha, bounds, x1)
+		}
+		if !ok {
+			if mem.size() == 0 {
+				// steepest descent failed to make progress
+				break
+			}
+			mem.reset()
+			continue
+		}
+
+		evaluateGradient(x1, f, g1)
+		blas.COPY(x1, sk)
+		blas.AXPY(-1.0, x, sk)
+		blas.COPY(g1, yk)
+		blas.AXPY(-1.0, g, yk)
+		mem.update(sk, yk)
+
+		f0 = f1
+		f1 = fNew
+		x, x1 = x1, x
+		g, g1 = g1, g
+		iter++
+
+		pgNorm = bounds.projectedGradientNorm(x, g)
+		scale := math.Max(math.Max(math.Abs(f0), math.Abs(f1)), 1.0)
+		converged = pgNorm <= tolerance || (f0-f1) <= LBFGSB_FACTR*epsilon*scale
+	}
+
+	result := linalg.NewVector(n)
+	blas.COPY(x, result)
+
+	return &solution{
+		ValidSolution: converged,
+		Result:        result,
+		Iterations:    iter,
+		GradientNorm:  pgNorm,
+		Objective:     f1,
+	}
+}
+
+// NewLBFGSBSolver returns a limited-memory BFGS solver that supports simple bounds
+// on the variables through WithBounds.
+func NewLBFGSBSolver() *lbfgsbSolver {
+	return &lbfgsbSolver{}
+}
@@ -0,0 +1,121 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func RosenbrockFunction(X linalg.Vector) float64 {
+	sum := 0.0
+	for i := 0; i < X.Len()-1; i++ {
+		a := X[i+1] - X[i]*X[i]
+		b := 1 - X[i]
+		sum += 100*a*a + b*b
+	}
+	return sum
+}
+
+func RosenbrockFunctionGradient(X linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+	gradF.Zero()
+	for i := 0; i < X.Len()-1; i++ {
+		a := X[i+1] - X[i]*X[i]
+		gradF[i] += -400*X[i]*a - 2*(1-X[i])
+		gradF[i+1] += 200 * a
+	}
+	nrm := 0.0
+	for i := range gradF {
+		nrm = math.Hypot(nrm, gradF[i])
+	}
+	return nrm
+}
+
+func TestLBFGSBSolver_Unbounded(t *testing.T) {
+	solver := optim.NewLBFGSBSolver()
+
+	x0 := linalg.NewVector(6)
+	x0.Set(-1.2)
+
+	solution := solver.Solve(RosenbrockFunction, x0,
+		optim.WithTolerance(1e-10),
+		optim.WithGradientFunc(RosenbrockFunctionGradient),
+	)
+
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	for i, xi := range solution.Result {
+		if math.Abs(xi-1) > 1e-4 {
+			t.Errorf("Expected x[%d] = 1, got %f", i, xi)
+		}
+	}
+}
+
+func TestLBFGSBSolver_ActiveBounds(t *testing.T) {
+	solver := optim.NewLBFGSBSolver()
+
+	x0 := linalg.NewVector(2)
+	x0[0] = 1
+	x0[1] = 0.5
+
+	f := func(X linalg.Vector) float64 {
+		a := X[0] - 3
+		b := X[1] + 1
+		return a*a + b*b + X[0]*X[1]
+	}
+
+	solution := solver.Solve(f, x0,
+		optim.WithBounds(optim.Bounds{
+			Lower: linalg.Vector{0, 0},
+			Upper: linalg.Vector{2, math.Inf(1)},
+		}),
+	)
+
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	if math.Abs(solution.Result[0]-2) > 1e-6 || math.Abs(solution.Result[1]) > 1e-6 {
+		t.Errorf("Expected [2 0], got %v", solution.Result)
+	}
+}
+
+func TestLBFGSBSolver_BoundedRosenbrock(t *testing.T) {
+	solver := optim.NewLBFGSBSolver()
+
+	x0 := linalg.NewVector(2)
+	x0[0] = -1.2
+	x0[1] = 1
+
+	solution := solver.Solve(RosenbrockFunction, x0,
+		optim.WithTolerance(1e-10),
+		optim.WithGradientFunc(RosenbrockFunctionGradient),
+		optim.WithBounds(optim.Bounds{
+			Lower: linalg.Vector{math.Inf(-1), math.Inf(-1)},
+			Upper: linalg.Vector{0.5, math.Inf(1)},
+		}),
+	)
+
+	// the constrained minimizer lies on x1 = 0.5 with x2 = x1^2
+	if math.Abs(solution.Result[0]-0.5) > 1e-6 || math.Abs(solution.Result[1]-0.25) > 1e-5 {
+		t.Errorf("Expected [0.5 0.25], got %v", solution.Result)
+	}
+}
+
+func TestLBFGSBSolver_LargeObjective(t *testing.T) {
+	solver := optim.NewLBFGSBSolver()
+
+	// a constant offset makes each reduction tiny relative to the objective, which must
+	// not stop the solver while the gradient is large
+	f := func(x linalg.Vector) float64 {
+		return 1e6 + RosenbrockFunction(x)
+	}
+	solution := solver.Solve(f, linalg.Vector{-1.2, 1},
+		optim.WithGradientFunc(RosenbrockFunctionGradient),
+	)
+
+	if math.Abs(solution.Result[0]-1) > 1e-4 || math.Abs(solution.Result[1]-1) > 1e-4 {
+		t.Errorf("Expected [1 1], got %v", solution.Result)
+	}
+}
@@ -0,0 +1,378 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var GN_ARMIJO = 1e-4          // sufficient decrease parameter of the Gauss-Newton line search
+var GN_MIN_STEP = 1e-10       // smallest step length tried by the Gauss-Newton line search
+var LM_INITIAL_DAMPING = 1e-3 // initial damping relative to the largest diagonal of J^T * J
+
+// ResidualFunc evaluates the residuals of a least-squares problem at x, writing them into r.
+type ResidualFunc func(x linalg.Vector, r linalg.Vector)
+
+// LeastSquaresProblem is the nonlinear least-squares problem
+//
+//	minimize 1/2 * ||r(x)||^2
+//
+// with NumResiduals residuals, or 1/2 * sum_i rho(r_i(x)^2) with a robust loss set by
+// WithLoss. The Jacobian is optional; a missing Jacobian is approximated by central
+// differences.
+type LeastSquaresProblem struct {
+	Residual     ResidualFunc
+	NumResiduals int
+	Jacobian     JacobianFunc
+}
+
+// leastSquaresSolution is the result of a nonlinear least-squares problem.
+type leastSquaresSolution struct {
+	ValidSolution bool
+	Result        linalg.Vector
+	Iterations    int
+	Evaluations   int     // residual evaluations, excluding those of finite differences
+	Objective     float64 // 1/2 * ||r||^2, or 1/2 * sum_i rho(r_i^2) with a loss
+	GradientNorm  float64 // ||J^T * r||_inf
+
+	Residuals linalg.Vector
+	Jacobian  linalg.Matrix
+
+	// Covariance is the estimated covariance of the parameters, s^2 * (J^T * J)^-1 with
+	// the residual variance s^2 = 2 * Objective / (m - n). With a loss the corrected
+	// Jacobian is used.
+	Covariance linalg.Matrix
+}
+
+// leastSquaresEvaluator evaluates the residuals and Jacobian of a problem, with the
+// robust loss if there is one.
+type leastSquaresEvaluator struct {
+	problem     *LeastSquaresProblem
+	m           int
+	n           int
+	jacobian    JacobianFunc
+	loss        Loss
+	evaluations int
+
+	rc linalg.Vector // residuals and Jacobian corrected for the loss
+	Jc linalg.Matrix
+}
+
+func newLeastSquaresEvaluator(problem *LeastSquaresProblem, n int, loss Loss) *leastSquaresEvaluator {
+	e := &leastSquaresEvaluator{problem: problem, m: problem.NumResiduals, n: n, jacobian: problem.Jacobian, loss: loss}
+	if e.m < n {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if e.jacobian == nil {
+		e.jacobian = CentralJacobianConstantStep(1e-6, ConstraintFunc(problem.Residual), e.m)
+	}
+	if loss != nil {
+		e.rc = linalg.NewVector(e.m)
+		e.Jc = linalg.NewDenseMatrix(e.m, n)
+	}
+	return e
+}
+
+// residual writes r(x) into r and returns the objective 1/2 * sum_i rho(r_i^2).
+func (e *leastSquaresEvaluator) residual(x, r linalg.Vector) float64 {
+	e.evaluations++
+	r.Zero()
+	e.problem.Residual(x, r)
+	return e.objective(r)
+}
+
+// objective returns 1/2 * sum_i rho(r_i^2) for the residuals r.
+func (e *leastSquaresEvaluator) objective(r linalg.Vector) float64 {
+	if e.loss == nil {
+		nrm := blas.NRM2(r)
+		return 0.5 * nrm * nrm
+	}
+	f := 0.0
+	for _, ri := range r {
+		rho, _, _ := e.loss.Evaluate(ri * ri)
+		f += rho
+	}
+	return 0.5 * f
+}
+
+// linearize writes the Jacobian at x into J and returns the residuals and Jacobian of
+// the linear model used for the steps: r and J themselves, or with a loss the corrected
+// ones of Triggs et al. (Bundle Adjustment - A Modern Synthesis, 6.8)
+//
+//	rc_i = sqrt(rho'_i) / (1 - alpha_i) * r_i
+//	Jc_i = sqrt(rho'_i) * (I - alpha_i * r_i * r_i^T / r_i^2) * J_i
+//
+// whose Gauss-Newton model has the gradient of the robust objective and a Hessian that
+// includes the curvature of rho. alpha_i is zero where rho has no positive curvature,
+// and the correction then reduces to iteratively reweighted least squares.
+func (e *leastSquaresEvaluator) linearize(x, r linalg.Vector, J linalg.Matrix) (linalg.Vector, linalg.Matrix) {
+	e.jacobian(x, J)
+	if e.loss == nil {
+		return r, J
+	}
+	for i, ri := range r {
+		s := ri * ri
+		_, d1, d2 := e.loss.Evaluate(s)
+		d1 = math.Max(d1, 0)
+		sqrtD1 := math.Sqrt(d1)
+		scale, alpha := sqrtD1, 0.0
+		if s > 0 && d2 > 0 && d1 > 0 {
+			alpha = 1 - math.Sqrt(1+2*s*d2/d1)
+			scale = sqrtD1 / (1 - alpha)
+		}
+		e.rc[i] = scale * ri
+		for j := 0; j < e.n; j++ {
+			v := J.Get(i, j)
+			e.Jc.Set(i, j, sqrtD1*(v-alpha*v))
+		}
+	}
+	return e.rc, e.Jc
+}
+
+// normalGradient writes J^T * r into g and returns its infinity norm.
+func normalGradient(J linalg.Matrix, r, g linalg.Vector) float64 {
+	blas.GEMVT(1, J, r, 0, g)
+	return math.Abs(g[blas.IAMAX(g)])
+}
+
+// finish fills a solution at x from the residuals r and Jacobian J there, and the
+// residuals rm and Jacobian Jm of the linear model.
+func (e *leastSquaresEvaluator) finish(sol *leastSquaresSolution, x, r linalg.Vector, J linalg.Matrix, rm linalg.Vector, Jm linalg.Matrix, iter int) *leastSquaresSolution {
+	m, n := e.m, e.n
+	sol.Result = x
+	sol.Iterations = iter
+	sol.Evaluations = e.evaluations
+	sol.Residuals = r
+	sol.Jacobian = J
+	sol.Objective = e.objective(r)
+	g := linalg.NewVector(n)
+	sol.GradientNorm = normalGradient(Jm, rm, g)
+	sol.Covariance = linalg.NewDenseMatrix(n, n)
+	if m > n {
+		linalg.FactorQR(Jm).NormalInverse(sol.Covariance)
+		s2 := 2 * sol.Objective / float64(m-n)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				sol.Covariance.Set(i, j, s2*sol.Covariance.Get(i, j))
+			}
+		}
+	}
+	return sol
+}
+
+// gaussNewtonSolver is the Gauss-Newton method with a backtracking line search. Each
+// step solves the linear least-squares problem min ||J * p + r|| by QR with column
+// pivoting, which also handles rank-deficient Jacobians.
+type gaussNewtonSolver struct{}
+
+func (s *gaussNewtonSolver) Solve(problem *LeastSquaresProblem, x0 linalg.Vector, options ...func(*solveOptions)) *leastSquaresSolution {
+	opts := newSolveOptions(x0, options...)
+	return s.solve(problem, x0, opts)
+}
+
+func (s *gaussNewtonSolver) solve(problem *LeastSquaresProblem, x0 linalg.Vector, opts *solveOptions) *leastSquaresSolution {
+	n := x0.Len()
+	e := newLeastSquaresEvaluator(problem, n, opts.loss)
+	m := e.m
+	x := linalg.NewVector(n)
+	xt := linalg.NewVector(n)
+	r := linalg.NewVector(m)
+	rt := linalg.NewVector(m)
+	g := linalg.NewVector(n)
+	p := linalg.NewVector(n)
+	J := linalg.NewDenseMatrix(m, n)
+	blas.COPY(x0, x)
+
+	sol := &leastSquaresSolution{}
+	f := e.residual(x, r)
+	rm, Jm := e.linearize(x, r, J)
+	iter := 0
+	for ; iter < opts.maxIterations; iter++ {
+		if normalGradient(Jm, rm, g) <= opts.tolerance {
+			sol.ValidSolution = true
+			break
+		}
+
+		// p = argmin ||J * p + r||
+		blas.SCAL(-1, rm)
+		linalg.FactorQR(Jm).SolveLeastSquares(rm, p)
+		blas.SCAL(-1, rm)
+
+		slope := blas.DOT(g, p)
+		if slope >= 0 {
+			break
+		}
+		alpha := 1.0
+		ft := 0.0
+		for {
+			blas.COPY(x, xt)
+			blas.AXPY(alpha, p, xt)
+			ft = e.residual(xt, rt)
+			if ft <= f+GN_ARMIJO*alpha*slope || alpha < GN_MIN_STEP {
+				break
+			}
+			alpha /= 2
+		}
+		if ft > f {
+			break
+		}
+
+		step := alpha * blas.NRM2(p)
+		decrease := f - ft
+		blas.COPY(xt, x)
+		blas.COPY(rt, r)
+		f = ft
+		rm, Jm = e.linearize(x, r, J)
+		if step <= opts.tolerance*(blas.NRM2(x)+opts.tolerance) || decrease <= opts.tolerance*opts.tolerance*f {
+			iter++
+			sol.ValidSolution = true
+			break
+		}
+	}
+	return e.finish(sol, x, r, J, rm, Jm, iter)
+}
+
+func NewGaussNewtonSolver() *gaussNewtonSolver {
+	return &gaussNewtonSolver{}
+}
+
+// levenbergMarquardtSolver is the Levenberg-Marquardt method with the damping update of
+// Nielsen (Madsen, Nielsen and Tingleff, Methods for Non-Linear Least Squares Problems,
+// 3.2). Each step solves (J^T * J + mu * I) * p = -J^T * r as the least-squares problem
+//
+//	min || [J; sqrt(mu) * I] * p + [r; 0] ||
+//
+// by QR, and mu is scaled by the ratio of the actual to the predicted decrease. Bounds
+// set with WithBounds are handled by an active set: a variable at a bound whose gradient
+// points out of the box is held there, the damped step is taken in the others, and the
+// step is then projected onto the box.
+type levenbergMarquardtSolver struct{}
+
+func (s *levenbergMarquardtSolver) Solve(problem *LeastSquaresProblem, x0 linalg.Vector, options ...func(*solveOptions)) *leastSquaresSolution {
+	opts := newSolveOptions(x0, options...)
+	return s.solve(problem, x0, opts)
+}
+
+func (s *levenbergMarquardtSolver) solve(problem *LeastSquaresProblem, x0 linalg.Vector, opts *solveOptions) *leastSquaresSolution {
+	n := x0.Len()
+	e := newLeastSquaresEvaluator(problem, n, opts.loss)
+	m := e.m
+	x := linalg.NewVector(n)
+	xt := linalg.NewVector(n)
+	r := linalg.NewVector(m)
+	rt := linalg.NewVector(m)
+	g := linalg.NewVector(n)
+	p := linalg.NewVector(n)
+	J := linalg.NewDenseMatrix(m, n)
+	Ja := linalg.NewDenseMatrix(m+n, n)
+	ra := linalg.NewVector(m + n)
+	Jp := linalg.NewVector(m)
+	blas.COPY(x0, x)
+	bounds := opts.bounds
+	if bounds != nil {
+		bounds.check(n)
+		bounds.Project(x)
+	}
+
+	sol := &leastSquaresSolution{}
+	f := e.residual(x, r)
+	rm, Jm := e.linearize(x, r, J)
+	optimality := func() float64 {
+		nrm := normalGradient(Jm, rm, g)
+		if bounds != nil {
+			return bounds.projectedGradientNorm(x, g)
+		}
+		return nrm
+	}
+	mu := 0.0
+	for j := 0; j < n; j++ {
+		d := 0.0
+		for i := 0; i < m; i++ {
+			d += Jm.Get(i, j) * Jm.Get(i, j)
+		}
+		mu = math.Max(mu, d)
+	}
+	mu *= LM_INITIAL_DAMPING
+	if mu == 0 {
+		mu = LM_INITIAL_DAMPING
+	}
+	nu := 2.0
+	active := make([]bool, n)
+
+	iter := 0
+	for ; iter < opts.maxIterations; iter++ {
+		if optimality() <= opts.tolerance {
+			sol.ValidSolution = true
+			break
+		}
+
+		// the variables held at their bounds, where the descent direction -g leaves the box
+		if bounds != nil {
+			for j := 0; j < n; j++ {
+				active[j] = x[j] <= bounds.lower(j) && g[j] > 0 || x[j] >= bounds.upper(j) && g[j] < 0
+			}
+		}
+
+		// the damped step from the augmented least-squares problem, in which the zero
+		// columns of the active variables leave only the damping to set their steps to 0
+		sqrtMu := math.Sqrt(mu)
+		for i := 0; i < m; i++ {
+			for j := 0; j < n; j++ {
+				if active[j] {
+					Ja.Set(i, j, 0)
+				} else {
+					Ja.Set(i, j, Jm.Get(i, j))
+				}
+			}
+			ra[i] = -rm[i]
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				Ja.Set(m+i, j, 0)
+			}
+			Ja.Set(m+i, i, sqrtMu)
+			ra[m+i] = 0
+		}
+		linalg.FactorQR(Ja).SolveLeastSquares(ra, p)
+
+		// with bounds the step is projected onto the box
+		blas.COPY(x, xt)
+		blas.AXPY(1, p, xt)
+		if bounds != nil {
+			bounds.Project(xt)
+			blas.COPY(xt, p)
+			blas.AXPY(-1, x, p)
+		}
+		// a vanishing step only ends the solve at an optimum if the projected gradient
+		// is also small, since a step can be cut short by the box
+		step := blas.NRM2(p)
+		if step <= opts.tolerance*(blas.NRM2(x)+opts.tolerance) {
+			sol.ValidSolution = bounds == nil || optimality() <= math.Sqrt(opts.tolerance)
+			break
+		}
+		ft := e.residual(xt, rt)
+
+		// the decrease predicted by the linear model, -g^T * p - 1/2 * ||J * p||^2
+		blas.GEMV(1, Jm, p, 0, Jp)
+		predicted := -blas.DOT(g, p) - 0.5*blas.DOT(Jp, Jp)
+		// a projected step can increase the model, so the decrease must be predicted too
+		rho := (f - ft) / predicted
+		if predicted > 0 && rho > 0 && !math.IsNaN(ft) {
+			blas.COPY(xt, x)
+			blas.COPY(rt, r)
+			f = ft
+			rm, Jm = e.linearize(x, r, J)
+			mu *= math.Max(1.0/3.0, 1-math.Pow(2*rho-1, 3))
+			nu = 2
+		} else {
+			mu *= nu
+			nu *= 2
+		}
+	}
+	return e.finish(sol, x, r, J, rm, Jm, iter)
+}
+
+func NewLevenbergMarquardtSolver() *levenbergMarquardtSolver {
+	return &levenbergMarquardtSolver{}
+}
@@ -0,0 +1,154 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// rosenbrockResiduals is the Rosenbrock function as the sum of the squares of
+// 10 * (x2 - x1^2) and 1 - x1.
+func rosenbrockResiduals() *optim.LeastSquaresProblem {
+	return &optim.LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			r[0] = 10 * (x[1] - x[0]*x[0])
+			r[1] = 1 - x[0]
+		},
+		NumResiduals: 2,
+		Jacobian: func(x linalg.Vector, J linalg.Matrix) {
+			J.Set(0, 0, -20*x[0])
+			J.Set(0, 1, 10)
+			J.Set(1, 0, -1)
+			J.Set(1, 1, 0)
+		},
+	}
+}
+
+// decayProblem fits a * exp(-b * t) to samples of 5 * exp(-0.3 * t) with a deterministic
+// perturbation. The Jacobian is left to finite differences.
+func decayProblem() *optim.LeastSquaresProblem {
+	t := linalg.NewVector(20)
+	y := linalg.NewVector(20)
+	for i := range t {
+		t[i] = 0.5 * float64(i)
+		y[i] = 5*math.Exp(-0.3*t[i]) + 0.01*math.Sin(7*t[i])
+	}
+	return &optim.LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			for i := range t {
+				r[i] = x[0]*math.Exp(-x[1]*t[i]) - y[i]
+			}
+		},
+		NumResiduals: t.Len(),
+	}
+}
+
+func TestGaussNewton(t *testing.T) {
+	sol := optim.NewGaussNewtonSolver().Solve(rosenbrockResiduals(), linalg.Vector{-1.2, 1})
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-1) > 1e-6 || math.Abs(sol.Result[1]-1) > 1e-6 {
+		t.Errorf("expected (1, 1), got %v after %d iterations", sol.Result, sol.Iterations)
+	}
+
+	sol = optim.NewGaussNewtonSolver().Solve(decayProblem(), linalg.Vector{1, 1}, optim.WithTolerance(1e-10))
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-5) > 0.05 || math.Abs(sol.Result[1]-0.3) > 0.01 {
+		t.Errorf("expected about (5, 0.3), got %v after %d iterations", sol.Result, sol.Iterations)
+	}
+}
+
+func TestLevenbergMarquardt(t *testing.T) {
+	sol := optim.NewLevenbergMarquardtSolver().Solve(rosenbrockResiduals(), linalg.Vector{-1.2, 1})
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-1) > 1e-6 || math.Abs(sol.Result[1]-1) > 1e-6 {
+		t.Errorf("expected (1, 1), got %v after %d iterations", sol.Result, sol.Iterations)
+	}
+
+	gn := optim.NewGaussNewtonSolver().Solve(decayProblem(), linalg.Vector{1, 1}, optim.WithTolerance(1e-10))
+	lm := optim.NewLevenbergMarquardtSolver().Solve(decayProblem(), linalg.Vector{1, 1}, optim.WithTolerance(1e-10))
+	if !lm.ValidSolution || math.Abs(lm.Result[0]-gn.Result[0]) > 1e-6 || math.Abs(lm.Result[1]-gn.Result[1]) > 1e-6 {
+		t.Errorf("expected %v as with Gauss-Newton, got %v", gn.Result, lm.Result)
+	}
+	if math.Abs(lm.Objective-0.5*dot(lm.Residuals, lm.Residuals)) > 1e-12 {
+		t.Errorf("objective %v does not match the residuals", lm.Objective)
+	}
+}
+
+func TestLevenbergMarquardtBounds(t *testing.T) {
+	// r = (x0 + x1 + 1, x1) with x0 >= 0 has its minimum at (0, -1/2), where x0 is held
+	// at its bound while x1 moves
+	problem := &optim.LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			r[0] = x[0] + x[1] + 1
+			r[1] = x[1]
+		},
+		NumResiduals: 2,
+	}
+	bounds := optim.Bounds{Lower: linalg.Vector{0, math.Inf(-1)}}
+	for _, x0 := range []linalg.Vector{{0, 0}, {2, 3}} {
+		sol := optim.NewLevenbergMarquardtSolver().Solve(problem, x0, optim.WithBounds(bounds))
+		if !sol.ValidSolution || sol.Result[0] != 0 || math.Abs(sol.Result[1]+0.5) > 1e-6 {
+			t.Errorf("x0 = %v: expected (0, -0.5), got %v", x0, sol.Result)
+		}
+	}
+
+	// from (0, 0) the damped step of r = (10 * (x0 + x1), x0 - 3) heads along the valley
+	// toward (3, -3), and x1 >= -1 projects it off the valley to a cost far above the
+	// initial 4.5; the linear model predicts that increase exactly, so the step must be
+	// rejected on the sign of the predicted decrease rather than on the ratio
+	problem = &optim.LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			r[0] = 10 * (x[0] + x[1])
+			r[1] = x[0] - 3
+		},
+		NumResiduals: 2,
+	}
+	bounds = optim.Bounds{Lower: linalg.Vector{math.Inf(-1), -1}}
+	sol := optim.NewLevenbergMarquardtSolver().Solve(problem, linalg.Vector{0, 0}, optim.WithBounds(bounds), optim.WithMaxIterations(1))
+	if sol.Objective > 4.5 {
+		t.Errorf("expected the cost not to increase from 4.5, got %v at %v", sol.Objective, sol.Result)
+	}
+	sol = optim.NewLevenbergMarquardtSolver().Solve(problem, linalg.Vector{0, 0}, optim.WithBounds(bounds))
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-103.0/101) > 1e-6 || sol.Result[1] != -1 {
+		t.Errorf("expected (103/101, -1), got %v", sol.Result)
+	}
+}
+
+func dot(x, y linalg.Vector) float64 {
+	sum := 0.0
+	for i := range x {
+		sum += x[i] * y[i]
+	}
+	return sum
+}
+
+func TestLeastSquaresCovariance(t *testing.T) {
+	// for the straight line a + b * t the covariance is s^2 * (X^T * X)^-1 in closed form
+	ts := []float64{0, 1, 2, 3, 4, 5}
+	ys := []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2}
+	problem := &optim.LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			for i := range ts {
+				r[i] = x[0] + x[1]*ts[i] - ys[i]
+			}
+		},
+		NumResiduals: len(ts),
+	}
+	sol := optim.NewLevenbergMarquardtSolver().Solve(problem, linalg.Vector{0, 0}, optim.WithTolerance(1e-10))
+
+	m := float64(len(ts))
+	st, stt := 0.0, 0.0
+	for _, v := range ts {
+		st += v
+		stt += v * v
+	}
+	s2 := 2 * sol.Objective / (m - 2)
+	det := m*stt - st*st
+	expected := [][]float64{{s2 * stt / det, -s2 * st / det}, {-s2 * st / det, s2 * m / det}}
+	for i := range expected {
+		for j := range expected[i] {
+			if math.Abs(sol.Covariance.Get(i, j)-expected[i][j]) > 1e-8 {
+				t.Fatalf("expected covariance %v, got %v", expected, sol.Covariance)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package optim
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrLossScale = errors.New("loss scale must be positive")
+
+// Loss is a robust loss rho applied to the squared residuals s = r_i^2 of a
+// least-squares problem, which then minimizes 1/2 * sum_i rho(r_i^2). A loss grows
+// slower than s for large residuals, so outliers have less influence on the fit.
+//
+// Evaluate returns rho(s) and its first and second derivatives. rho(0) = 0 and
+// rho'(0) = 1 for the losses of this package, so they agree with plain least squares
+// for small residuals.
+type Loss interface {
+	Evaluate(s float64) (rho, drho, d2rho float64)
+}
+
+// WithLoss sets the robust loss of a least-squares problem.
+func WithLoss(loss Loss) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.loss = loss
+	}
+}
+
+// scaledLoss is a loss with unit scale evaluated at s / c^2 and scaled back, so
+// residuals much smaller than the scale c are treated as in least squares:
+// rho_c(s) = c^2 * rho(s / c^2).
+type scaledLoss struct {
+	scale2 float64
+	rho    func(z float64) (float64, float64, float64)
+}
+
+func (l scaledLoss) Evaluate(s float64) (float64, float64, float64) {
+	rho, d1, d2 := l.rho(s / l.scale2)
+	return l.scale2 * rho, d1, d2 / l.scale2
+}
+
+func newScaledLoss(scale float64, rho func(z float64) (float64, float64, float64)) Loss {
+	if scale <= 0 {
+		panic(ErrLossScale)
+	}
+	return scaledLoss{scale2: scale * scale, rho: rho}
+}
+
+// HuberLoss is quadratic for residuals below scale and linear above:
+// rho(s) = s for s <= 1 and 2 * sqrt(s) - 1 otherwise.
+func HuberLoss(scale float64) Loss {
+	return newScaledLoss(scale, func(z float64) (float64, float64, float64) {
+		if z <= 1 {
+			return z, 1, 0
+		}
+		r := math.Sqrt(z)
+		return 2*r - 1, 1 / r, -1 / (2 * z * r)
+	})
+}
+
+// SoftL1Loss is a smooth approximation of the Huber loss: rho(s) = 2 * (sqrt(1 + s) - 1).
+func SoftL1Loss(scale float64) Loss {
+	return newScaledLoss(scale, func(z float64) (float64, float64, float64) {
+		t := 1 + z
+		r := math.Sqrt(t)
+		return 2 * (r - 1), 1 / r, -1 / (2 * t * r)
+	})
+}
+
+// CauchyLoss is rho(s) = ln(1 + s), which grows logarithmically for large residuals.
+func CauchyLoss(scale float64) Loss {
+	return newScaledLoss(scale, func(z float64) (float64, float64, float64) {
+		t := 1 + z
+		return math.Log1p(z), 1 / t, -1 / (t * t)
+	})
+}
+
+// ArctanLoss is rho(s) = atan(s), which is bounded by pi / 2.
+func ArctanLoss(scale float64) Loss {
+	return newScaledLoss(scale, func(z float64) (float64, float64, float64) {
+		t := 1 + z*z
+		return math.Atan(z), 1 / t, -2 * z / (t * t)
+	})
+}
+
+// TukeyLoss is the Tukey biweight rho(s) = (1 - (1 - s)^3) / 3 for s <= 1 and 1/3
+// otherwise. Residuals beyond scale have no influence on the fit, so the starting point
+// should already be close to the inliers.
+func TukeyLoss(scale float64) Loss {
+	return newScaledLoss(scale, func(z float64) (float64, float64, float64) {
+		if z > 1 {
+			return 1.0 / 3, 0, 0
+		}
+		t := 1 - z
+		return (1 - t*t*t) / 3, t * t, -2 * t
+	})
+}
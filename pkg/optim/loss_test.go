@@ -0,0 +1,106 @@
+package optim_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func TestLossDerivatives(t *testing.T) {
+	losses := map[string]optim.Loss{
+		"huber":   optim.HuberLoss(0.7),
+		"soft-l1": optim.SoftL1Loss(0.7),
+		"cauchy":  optim.CauchyLoss(0.7),
+		"arctan":  optim.ArctanLoss(0.7),
+		"tukey":   optim.TukeyLoss(0.7),
+	}
+	const h = 1e-6
+	for name, loss := range losses {
+		if rho, d1, _ := loss.Evaluate(0); rho != 0 || math.Abs(d1-1) > 1e-12 {
+			t.Errorf("%s: expected rho(0) = 0 and rho'(0) = 1, got %v and %v", name, rho, d1)
+		}
+		for _, s := range []float64{0.1, 0.3, 1.2, 4} {
+			rho, d1, d2 := loss.Evaluate(s)
+			rhoP, d1P, _ := loss.Evaluate(s + h)
+			rhoM, d1M, _ := loss.Evaluate(s - h)
+			if fd := (rhoP - rhoM) / (2 * h); math.Abs(fd-d1) > 1e-6 {
+				t.Errorf("%s: rho'(%v) = %v, finite difference %v", name, s, d1, fd)
+			}
+			if fd := (d1P - d1M) / (2 * h); math.Abs(fd-d2) > 1e-5 {
+				t.Errorf("%s: rho''(%v) = %v, finite difference %v", name, s, d2, fd)
+			}
+			if rho > s+1e-12 {
+				t.Errorf("%s: rho(%v) = %v exceeds s", name, s, rho)
+			}
+		}
+	}
+}
+
+func TestRobustLineFit(t *testing.T) {
+	// y = 2 + 0.5 * t with small noise and three gross outliers
+	ts := linalg.NewVector(20)
+	ys := linalg.NewVector(20)
+	for i := range ts {
+		ts[i] = float64(i)
+		ys[i] = 2 + 0.5*ts[i] + 0.05*math.Sin(3*ts[i])
+	}
+	ys[3] += 15
+	ys[11] -= 20
+	ys[17] += 25
+	problem := &optim.LeastSquaresProblem{
+		Residual: func(x, r linalg.Vector) {
+			for i := range ts {
+				r[i] = x[0] + x[1]*ts[i] - ys[i]
+			}
+		},
+		NumResiduals: ts.Len(),
+		Jacobian: func(x linalg.Vector, J linalg.Matrix) {
+			for i := range ts {
+				J.Set(i, 0, 1)
+				J.Set(i, 1, ts[i])
+			}
+		},
+	}
+
+	plain := optim.NewLevenbergMarquardtSolver().Solve(problem, linalg.Vector{0, 0})
+	if math.Abs(plain.Result[1]-0.5) < 0.05 {
+		t.Fatalf("expected the outliers to bias the least-squares fit, got %v", plain.Result)
+	}
+
+	losses := map[string]optim.Loss{
+		"huber":   optim.HuberLoss(0.1),
+		"soft-l1": optim.SoftL1Loss(0.1),
+		"cauchy":  optim.CauchyLoss(0.1),
+		"arctan":  optim.ArctanLoss(0.1),
+	}
+	for name, loss := range losses {
+		sol := optim.NewLevenbergMarquardtSolver().Solve(problem, linalg.Vector{0, 0},
+			optim.WithLoss(loss), optim.WithMaxIterations(500))
+		if !sol.ValidSolution {
+			t.Errorf("%s: expected a valid solution", name)
+		}
+		if math.Abs(sol.Result[0]-2) > 0.1 || math.Abs(sol.Result[1]-0.5) > 0.01 {
+			t.Errorf("%s: expected [2 0.5], got %v", name, sol.Result)
+		}
+	}
+
+	// Tukey's loss ignores the outliers entirely, starting from the robust fit
+	start := optim.NewLevenbergMarquardtSolver().Solve(problem, linalg.Vector{0, 0}, optim.WithLoss(optim.HuberLoss(0.1)))
+	sol := optim.NewGaussNewtonSolver().Solve(problem, start.Result, optim.WithLoss(optim.TukeyLoss(1)))
+	if math.Abs(sol.Result[0]-2) > 0.05 || math.Abs(sol.Result[1]-0.5) > 0.005 {
+		t.Errorf("tukey: expected [2 0.5], got %v", sol.Result)
+	}
+}
+
+func TestLossScale(t *testing.T) {
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, optim.ErrLossScale) {
+			t.Errorf("expected ErrLossScale, got %v", err)
+		}
+	}()
+	optim.HuberLoss(0)
+	t.Errorf("expected a zero scale to panic")
+}
@@ -0,0 +1,289 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var ODR_DERIVATIVE_STEP = 1e-6 // relative step for the differences of a model without derivatives
+
+// ModelDerivativeFunc writes the derivatives of a model f(t; p) with respect to the
+// parameters into grad and returns the derivative with respect to t.
+type ModelDerivativeFunc func(t float64, p, grad linalg.Vector) float64
+
+// ODRProblem is the orthogonal distance regression of the model y = f(x; p) to the
+// points (X[i], Y[i]), both of which are measured with errors:
+//
+//	min_{p, delta} sum_i ((f(X[i] + delta[i]; p) - Y[i]) / SigmaY[i])^2 + (delta[i] / SigmaX[i])^2
+//
+// SigmaX and SigmaY are the uncertainties of the points; nil means one for every point.
+// Only their ratio affects the estimate. The derivative is optional; a missing
+// derivative is approximated by central differences.
+type ODRProblem struct {
+	Model      ModelFunc
+	Derivative ModelDerivativeFunc
+	X          linalg.Vector
+	Y          linalg.Vector
+	SigmaX     linalg.Vector
+	SigmaY     linalg.Vector
+}
+
+// odrSolution is the result of an orthogonal distance regression. As in ODRPACK, the
+// covariance of the parameters is scaled by the residual variance
+// ResidualVariance = ChiSquare / (N - k) for N points and k parameters.
+type odrSolution struct {
+	ValidSolution bool
+	Parameters    linalg.Vector
+	Corrections   linalg.Vector // delta, the estimated errors of X
+	Iterations    int
+	Evaluations   int
+
+	ChiSquare        float64 // the weighted sum of squares minimized
+	ResidualVariance float64
+	StandardErrors   linalg.Vector
+	Covariance       linalg.Matrix
+
+	Residuals linalg.Vector // Y[i] - f(X[i] + delta[i]; p), unweighted
+}
+
+// odrSolver is a Levenberg-Marquardt method for orthogonal distance regression in the
+// spirit of ODRPACK (Boggs, Byrd and Schnabel, A Stable and Efficient Algorithm for
+// Nonlinear Orthogonal Distance Regression, 1987). The residuals of the parameters p
+// and corrections delta are
+//
+//	r = [(f(X + delta; p) - Y) / SigmaY; delta / SigmaX]
+//
+// with the Jacobian [A D1; 0 D2], where A is the N x k Jacobian of the model with
+// respect to p and D1 and D2 are diagonal. The damped step eliminates delta through the
+// diagonal blocks, which leaves a k x k least-squares problem with row weights
+//
+//	W_i = (D2_i^2 + mu) / (D1_i^2 + D2_i^2 + mu)
+//
+// so each iteration costs O(N * k^2) instead of O((N + k)^3).
+type odrSolver struct{}
+
+func (s *odrSolver) Solve(problem *ODRProblem, p0 linalg.Vector, options ...func(*solveOptions)) *odrSolution {
+	opts := newSolveOptions(p0, options...)
+	return s.solve(problem, p0, opts)
+}
+
+func (s *odrSolver) solve(problem *ODRProblem, p0 linalg.Vector, opts *solveOptions) *odrSolution {
+	N := problem.X.Len()
+	k := p0.Len()
+	if problem.Y.Len() != N || N < k ||
+		(problem.SigmaX != nil && problem.SigmaX.Len() != N) ||
+		(problem.SigmaY != nil && problem.SigmaY.Len() != N) {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	wx := func(i int) float64 {
+		if problem.SigmaX == nil {
+			return 1
+		}
+		return 1 / problem.SigmaX[i]
+	}
+	wy := func(i int) float64 {
+		if problem.SigmaY == nil {
+			return 1
+		}
+		return 1 / problem.SigmaY[i]
+	}
+	derivative := problem.Derivative
+	if derivative == nil {
+		derivative = modelDifferences(problem.Model, k)
+	}
+
+	evaluations := 0
+	// residual writes the residuals of the model and of the corrections into r1 and r2
+	// and returns the objective 1/2 * ||r||^2.
+	residual := func(p, delta, r1, r2 linalg.Vector) float64 {
+		evaluations++
+		f := 0.0
+		for i := 0; i < N; i++ {
+			r1[i] = (problem.Model(problem.X[i]+delta[i], p) - problem.Y[i]) * wy(i)
+			r2[i] = delta[i] * wx(i)
+			f += r1[i]*r1[i] + r2[i]*r2[i]
+		}
+		return 0.5 * f
+	}
+	A := linalg.NewDenseMatrix(N, k)
+	d1 := linalg.NewVector(N)
+	d2 := linalg.NewVector(N)
+	grad := linalg.NewVector(k)
+	jacobian := func(p, delta linalg.Vector) {
+		for i := 0; i < N; i++ {
+			d1[i] = derivative(problem.X[i]+delta[i], p, grad) * wy(i)
+			d2[i] = wx(i)
+			for j := 0; j < k; j++ {
+				A.Set(i, j, grad[j]*wy(i))
+			}
+		}
+	}
+
+	p := linalg.NewVector(k)
+	pt := linalg.NewVector(k)
+	delta := linalg.NewVector(N)
+	deltaT := linalg.NewVector(N)
+	r1, r2 := linalg.NewVector(N), linalg.NewVector(N)
+	r1t, r2t := linalg.NewVector(N), linalg.NewVector(N)
+	gp := linalg.NewVector(k)    // A^T * r1
+	gd := linalg.NewVector(N)    // D1 * r1 + D2 * r2
+	step := linalg.NewVector(k)  // the step in p
+	stepD := linalg.NewVector(N) // the step in delta
+	Aw := linalg.NewDenseMatrix(N+k, k)
+	z := linalg.NewVector(N + k)
+	Ap := linalg.NewVector(N)
+	copy(p, p0)
+
+	f := residual(p, delta, r1, r2)
+	jacobian(p, delta)
+	gradientNorm := func() float64 {
+		blas.GEMVT(1, A, r1, 0, gp)
+		nrm := math.Abs(gp[blas.IAMAX(gp)])
+		for i := 0; i < N; i++ {
+			gd[i] = d1[i]*r1[i] + d2[i]*r2[i]
+			nrm = math.Max(nrm, math.Abs(gd[i]))
+		}
+		return nrm
+	}
+	mu := 0.0
+	for j := 0; j < k; j++ {
+		d := 0.0
+		for i := 0; i < N; i++ {
+			d += A.Get(i, j) * A.Get(i, j)
+		}
+		mu = math.Max(mu, d)
+	}
+	for i := 0; i < N; i++ {
+		mu = math.Max(mu, d1[i]*d1[i]+d2[i]*d2[i])
+	}
+	mu *= LM_INITIAL_DAMPING
+	nu := 2.0
+
+	sol := &odrSolution{}
+	iter := 0
+	for ; iter < opts.maxIterations; iter++ {
+		if gradientNorm() <= opts.tolerance {
+			sol.ValidSolution = true
+			break
+		}
+
+		// the step in p from the weighted least-squares problem
+		//
+		//	min ||sqrt(W) * (A * step - z)||^2 + mu * ||step||^2,  z_i = -(r1_i - D1_i * gd_i / E_i) / W_i
+		//
+		// with E = D1^2 + D2^2 + mu, then the step in delta from the diagonal block
+		sqrtMu := math.Sqrt(mu)
+		for i := 0; i < N; i++ {
+			e := d1[i]*d1[i] + d2[i]*d2[i] + mu
+			w := (d2[i]*d2[i] + mu) / e
+			sw := math.Sqrt(w)
+			for j := 0; j < k; j++ {
+				Aw.Set(i, j, sw*A.Get(i, j))
+			}
+			z[i] = -sw * (r1[i] - d1[i]*gd[i]/e) / w
+		}
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				Aw.Set(N+i, j, 0)
+			}
+			Aw.Set(N+i, i, sqrtMu)
+			z[N+i] = 0
+		}
+		linalg.FactorQR(Aw).SolveLeastSquares(z, step)
+		blas.GEMV(1, A, step, 0, Ap)
+		for i := 0; i < N; i++ {
+			e := d1[i]*d1[i] + d2[i]*d2[i] + mu
+			stepD[i] = -(gd[i] + d1[i]*Ap[i]) / e
+		}
+		size := math.Hypot(blas.NRM2(step), blas.NRM2(stepD))
+		if size <= opts.tolerance*(math.Hypot(blas.NRM2(p), blas.NRM2(delta))+opts.tolerance) {
+			sol.ValidSolution = true
+			break
+		}
+
+		blas.COPY(p, pt)
+		blas.AXPY(1, step, pt)
+		blas.COPY(delta, deltaT)
+		blas.AXPY(1, stepD, deltaT)
+		ft := residual(pt, deltaT, r1t, r2t)
+
+		// the decrease predicted by the linear model, -g^T * s - 1/2 * ||J * s||^2
+		predicted := -blas.DOT(gp, step) - blas.DOT(gd, stepD)
+		for i := 0; i < N; i++ {
+			a := Ap[i] + d1[i]*stepD[i]
+			b := d2[i] * stepD[i]
+			predicted -= 0.5 * (a*a + b*b)
+		}
+		rho := (f - ft) / predicted
+		if rho > 0 && !math.IsNaN(ft) {
+			blas.COPY(pt, p)
+			blas.COPY(deltaT, delta)
+			blas.COPY(r1t, r1)
+			blas.COPY(r2t, r2)
+			f = ft
+			jacobian(p, delta)
+			mu *= math.Max(1.0/3.0, 1-math.Pow(2*rho-1, 3))
+			nu = 2
+		} else {
+			mu *= nu
+			nu *= 2
+		}
+	}
+
+	sol.Parameters = p
+	sol.Corrections = delta
+	sol.Iterations = iter
+	sol.Evaluations = evaluations
+	sol.ChiSquare = 2 * f
+	sol.Residuals = linalg.NewVector(N)
+	for i := 0; i < N; i++ {
+		sol.Residuals[i] = problem.Y[i] - problem.Model(problem.X[i]+delta[i], p)
+	}
+
+	// the covariance of p from the Schur complement A^T * W * A at mu = 0
+	sol.StandardErrors = linalg.NewVector(k)
+	sol.Covariance = linalg.NewDenseMatrix(k, k)
+	if N > k {
+		sol.ResidualVariance = sol.ChiSquare / float64(N-k)
+		Ws := linalg.NewDenseMatrix(N, k)
+		for i := 0; i < N; i++ {
+			sw := math.Sqrt(d2[i] * d2[i] / (d1[i]*d1[i] + d2[i]*d2[i]))
+			for j := 0; j < k; j++ {
+				Ws.Set(i, j, sw*A.Get(i, j))
+			}
+		}
+		linalg.FactorQR(Ws).NormalInverse(sol.Covariance)
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				sol.Covariance.Set(i, j, sol.ResidualVariance*sol.Covariance.Get(i, j))
+			}
+			sol.StandardErrors[i] = math.Sqrt(sol.Covariance.Get(i, i))
+		}
+	}
+	return sol
+}
+
+// modelDifferences returns the derivatives of a model by central differences.
+func modelDifferences(model ModelFunc, k int) ModelDerivativeFunc {
+	shifted := linalg.NewVector(k)
+	return func(t float64, p, grad linalg.Vector) float64 {
+		copy(shifted, p)
+		for j := 0; j < k; j++ {
+			h := ODR_DERIVATIVE_STEP * math.Max(1, math.Abs(p[j]))
+			shifted[j] = p[j] + h
+			fp := model(t, shifted)
+			shifted[j] = p[j] - h
+			fm := model(t, shifted)
+			shifted[j] = p[j]
+			grad[j] = (fp - fm) / (2 * h)
+		}
+		h := ODR_DERIVATIVE_STEP * math.Max(1, math.Abs(t))
+		return (model(t+h, p) - model(t-h, p)) / (2 * h)
+	}
+}
+
+func NewODRSolver() *odrSolver {
+	return &odrSolver{}
+}
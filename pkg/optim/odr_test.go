@@ -0,0 +1,99 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func TestODRLine(t *testing.T) {
+	// with equal errors in x and y the fit of a line is Deming regression, which has a
+	// closed form
+	xs := linalg.NewVector(25)
+	ys := linalg.NewVector(25)
+	for i := range xs {
+		u := float64(i) / 4
+		xs[i] = u + 0.3*math.Sin(2.1*u)
+		ys[i] = 1 + 0.8*u + 0.3*math.Cos(1.7*u)
+	}
+	line := func(t float64, p linalg.Vector) float64 { return p[0] + p[1]*t }
+	sol := optim.NewODRSolver().Solve(&optim.ODRProblem{Model: line, X: xs, Y: ys}, linalg.Vector{0, 1},
+		optim.WithTolerance(1e-10))
+	if !sol.ValidSolution {
+		t.Fatalf("expected a valid solution, got %+v", sol)
+	}
+
+	n := float64(xs.Len())
+	mx, my := 0.0, 0.0
+	for i := range xs {
+		mx += xs[i] / n
+		my += ys[i] / n
+	}
+	sxx, syy, sxy := 0.0, 0.0, 0.0
+	for i := range xs {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		syy += (ys[i] - my) * (ys[i] - my)
+		sxy += (xs[i] - mx) * (ys[i] - my)
+	}
+	slope := (syy - sxx + math.Sqrt((syy-sxx)*(syy-sxx)+4*sxy*sxy)) / (2 * sxy)
+	intercept := my - slope*mx
+	if math.Abs(sol.Parameters[0]-intercept) > 1e-6 || math.Abs(sol.Parameters[1]-slope) > 1e-6 {
+		t.Errorf("expected [%v %v], got %v", intercept, slope, sol.Parameters)
+	}
+
+	// the corrected points lie on the line, reached orthogonally from the data
+	for i := range xs {
+		if math.Abs(sol.Residuals[i]-sol.Corrections[i]/slope) > 1e-6 {
+			t.Fatalf("point %d: correction %v is not orthogonal to the line", i, sol.Corrections[i])
+		}
+	}
+	ols := optim.CurveFit(line, xs, ys, nil, linalg.Vector{0, 1})
+	if math.Abs(ols.Parameters[1]-slope) < 1e-3 {
+		t.Errorf("expected ordinary least squares to differ from ODR, got slope %v", ols.Parameters[1])
+	}
+	if sol.StandardErrors[0] <= 0 || sol.StandardErrors[1] <= 0 {
+		t.Errorf("expected positive standard errors, got %v", sol.StandardErrors)
+	}
+}
+
+func TestODRExponential(t *testing.T) {
+	// 4 * exp(-0.5 * x) + 1 with errors in both coordinates, and SigmaX much smaller
+	// than SigmaY, so ODR approaches ordinary least squares
+	xs := linalg.NewVector(40)
+	ys := linalg.NewVector(40)
+	sx := linalg.NewVector(40)
+	sy := linalg.NewVector(40)
+	for i := range xs {
+		u := 0.2 * float64(i)
+		xs[i] = u + 0.01*math.Sin(3*u)
+		ys[i] = exponentialModel(u, linalg.Vector{4, 0.5, 1}) + 0.02*math.Cos(5*u)
+		sx[i] = 0.01
+		sy[i] = 0.02
+	}
+	derivative := func(t float64, p, grad linalg.Vector) float64 {
+		e := math.Exp(-p[1] * t)
+		grad[0] = e
+		grad[1] = -p[0] * t * e
+		grad[2] = 1
+		return -p[0] * p[1] * e
+	}
+	problem := &optim.ODRProblem{Model: exponentialModel, X: xs, Y: ys, SigmaX: sx, SigmaY: sy}
+	numeric := optim.NewODRSolver().Solve(problem, linalg.Vector{1, 1, 0})
+	problem.Derivative = derivative
+	exact := optim.NewODRSolver().Solve(problem, linalg.Vector{1, 1, 0})
+	for name, p := range map[string]linalg.Vector{"numeric": numeric.Parameters, "exact": exact.Parameters} {
+		if math.Abs(p[0]-4) > 0.05 || math.Abs(p[1]-0.5) > 0.01 || math.Abs(p[2]-1) > 0.05 {
+			t.Errorf("%s: expected [4 0.5 1], got %v", name, p)
+		}
+	}
+	for j := range exact.Parameters {
+		if math.Abs(numeric.Parameters[j]-exact.Parameters[j]) > 1e-6 {
+			t.Errorf("expected the same fit with and without derivatives, got %v and %v", numeric.Parameters, exact.Parameters)
+		}
+		if math.Abs(numeric.StandardErrors[j]-exact.StandardErrors[j]) > 1e-4*exact.StandardErrors[j] {
+			t.Errorf("expected the same standard errors, got %v and %v", numeric.StandardErrors, exact.StandardErrors)
+		}
+	}
+}
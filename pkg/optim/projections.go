@@ -0,0 +1,136 @@
+package optim
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var (
+	ErrSimplexRadius    = errors.New("simplex radius must be positive")
+	ErrHyperplaneNormal = errors.New("hyperplane normal must be nonzero")
+)
+
+// Projector projects points onto a closed convex set.
+//
+// Bounds is the projector onto a box.
+type Projector interface {
+	// Project replaces x with the closest point of the set to x in the Euclidean norm.
+	Project(x linalg.Vector)
+}
+
+// Simplex is the set {x : x >= 0, sum(x) = Radius}. Radius must be positive;
+// the probability simplex has Radius = 1.
+type Simplex struct {
+	Radius float64
+}
+
+// Project projects x onto the simplex using the sort-based method of
+// Held, Wolfe and Crowder (1974). It panics with ErrSimplexRadius if the radius is not
+// positive.
+func (s Simplex) Project(x linalg.Vector) {
+	if s.Radius <= 0 {
+		panic(ErrSimplexRadius)
+	}
+	u := linalg.NewVector(x.Len())
+	blas.COPY(x, u)
+	sort.Sort(sort.Reverse(sort.Float64Slice(u)))
+
+	sum := 0.0
+	theta := 0.0
+	for j := range u {
+		sum += u[j]
+		t := (sum - s.Radius) / float64(j+1)
+		if u[j]-t > 0 {
+			theta = t
+		}
+	}
+	for i := range x {
+		x[i] = math.Max(x[i]-theta, 0)
+	}
+}
+
+// L2Ball is the set {x : |x - Center| <= Radius}. A nil Center is the origin.
+type L2Ball struct {
+	Center linalg.Vector
+	Radius float64
+}
+
+func (b L2Ball) Project(x linalg.Vector) {
+	if b.Center != nil && b.Center.Len() != x.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	nrm := 0.0
+	for i := range x {
+		nrm = blas.Hypot(nrm, x[i]-b.center(i))
+	}
+	if nrm <= b.Radius {
+		return
+	}
+	scale := b.Radius / nrm
+	for i := range x {
+		c := b.center(i)
+		x[i] = c + scale*(x[i]-c)
+	}
+}
+
+func (b L2Ball) center(i int) float64 {
+	if b.Center == nil {
+		return 0
+	}
+	return b.Center[i]
+}
+
+// L1Ball is the set {x : sum(|x|) <= Radius}.
+type L1Ball struct {
+	Radius float64
+}
+
+// Project projects x onto the L1 ball by projecting |x| onto the simplex of the
+// same radius and restoring the signs (Duchi et al., 2008).
+func (b L1Ball) Project(x linalg.Vector) {
+	if blas.ASUM(x) <= b.Radius {
+		return
+	}
+	if b.Radius <= 0 {
+		x.Zero()
+		return
+	}
+	u := linalg.NewVector(x.Len())
+	for i := range x {
+		u[i] = math.Abs(x[i])
+	}
+	Simplex{Radius: b.Radius}.Project(u)
+	for i := range x {
+		x[i] = blas.Sign(x[i]) * u[i]
+	}
+}
+
+// Hyperplane is the affine set {x : Normal^T * x = Offset}.
+type Hyperplane struct {
+	Normal linalg.Vector
+	Offset float64
+}
+
+// Project projects x onto the hyperplane. It panics with ErrHyperplaneNormal if the
+// normal is zero.
+func (h Hyperplane) Project(x linalg.Vector) {
+	nn := blas.DOT(h.Normal, h.Normal)
+	if nn == 0 {
+		panic(ErrHyperplaneNormal)
+	}
+	r := blas.DOT(h.Normal, x) - h.Offset
+	blas.AXPY(-r/nn, h.Normal, x)
+}
+
+// projectedGradient computes pg = P(x - g) - x and returns its infinity norm.
+func projectedGradient(projector Projector, x, g, pg linalg.Vector) float64 {
+	blas.COPY(x, pg)
+	blas.AXPY(-1.0, g, pg)
+	projector.Project(pg)
+	blas.AXPY(-1.0, x, pg)
+	return math.Abs(pg[blas.IAMAX(pg)])
+}
@@ -59,3 +59,75 @@ func UpdateHessianInverseBFGS(N linalg.Matrix, y linalg.Vector, dx linalg.Vector
 
 	// fmt.Printf("N after update: %v\n", N)
 }
+
+// UpdateHessianDampedBFGS is the damped BFGS update of Powell (1978), which keeps the
+// Hessian approximation positive definite when y^T * dx is small or negative by
+// replacing y with a combination of y and H * dx.
+func UpdateHessianDampedBFGS(H linalg.Matrix, y linalg.Vector, dx linalg.Vector) {
+	if y.Len() != dx.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if y.Len() != H.Rows() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	t1 := linalg.NewVector(y.Len())
+	blas.GEMV(1.0, H, dx, 0.0, t1) // t1 = Hk * dxk
+
+	sBs := blas.DOT(dx, t1)
+	sy := blas.DOT(dx, y)
+	if sBs <= 0 {
+		return
+	}
+	theta := 1.0
+	if sy < 0.2*sBs {
+		theta = 0.8 * sBs / (sBs - sy)
+	}
+
+	r := linalg.NewVector(y.Len())
+	blas.CPSC(theta, y, r)      // r = theta * y
+	blas.AXPY(1.0-theta, t1, r) // r = theta * y + (1 - theta) * Hk * dxk
+	UpdateHessianBFGS(H, r, dx)
+}
+
+// UpdateJacobianBroyden is the rank-1 update of a Jacobian approximation using Broyden's
+// good method: J = J + (y - J * dx) * dx^T / (dx^T * dx), with y the change of F.
+func UpdateJacobianBroyden(J linalg.Matrix, y linalg.Vector, dx linalg.Vector) {
+	if y.Len() != J.Rows() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if dx.Len() != J.Cols() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	ss := blas.DOT(dx, dx)
+	if ss == 0 {
+		return
+	}
+	t1 := linalg.NewVector(y.Len())
+	blas.COPY(y, t1)
+	blas.GEMV(-1.0, J, dx, 1.0, t1) // t1 = y - Jk * dxk
+
+	J.AddOuterProduct(t1, dx, 1.0/ss)
+}
+
+// UpdateJacobianInverseBroyden is the rank-1 update of an inverse Jacobian approximation
+// using Broyden's bad method: N = N + (dx - N * y) * y^T / (y^T * y).
+func UpdateJacobianInverseBroyden(N linalg.Matrix, y linalg.Vector, dx linalg.Vector) {
+	if y.Len() != N.Cols() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	if dx.Len() != N.Rows() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+
+	yy := blas.DOT(y, y)
+	if yy == 0 {
+		return
+	}
+	t1 := linalg.NewVector(dx.Len())
+	blas.COPY(dx, t1)
+	blas.GEMV(-1.0, N, y, 1.0, t1) // t1 = dxk - Nk * yk
+
+	N.AddOuterProduct(t1, y, 1.0/yy)
+}
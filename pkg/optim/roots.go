@@ -0,0 +1,354 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var ROOT_ARMIJO = 1e-4            // sufficient decrease of ||F|| in the line search
+var ROOT_MIN_STEP = 1e-10         // smallest step length of the line search
+var HYBRID_INITIAL_RADIUS = 100.0 // initial trust region radius relative to ||x0||
+
+// VectorFunc is a function from R^n to R^m that writes F(x) into fx.
+type VectorFunc func(x linalg.Vector, fx linalg.Vector)
+
+// RootMethod selects the method used by RootFind.
+type RootMethod int
+
+const (
+	// HybridRoot is Powell's hybrid dogleg method.
+	HybridRoot RootMethod = iota
+	// NewtonRoot is the Newton-Raphson method with a line search.
+	NewtonRoot
+	// BroydenGoodRoot is Broyden's good method, which updates the Jacobian.
+	BroydenGoodRoot
+	// BroydenBadRoot is Broyden's bad method, which updates the inverse Jacobian.
+	BroydenBadRoot
+)
+
+// WithRootMethod sets the method used by RootFind.
+func WithRootMethod(method RootMethod) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.rootMethod = method
+	}
+}
+
+// RootStatus is the outcome of RootFind.
+type RootStatus int
+
+const (
+	// RootConverged means ||F(x)||_inf is within the tolerance.
+	RootConverged RootStatus = iota
+	// RootMaxIterations means the iteration limit was reached.
+	RootMaxIterations
+	// RootNoProgress means the steps stopped reducing ||F||, usually near a local
+	// minimum of ||F|| that is not a root or where the Jacobian is singular.
+	RootNoProgress
+)
+
+// rootSolution is the result of RootFind.
+type rootSolution struct {
+	Status              RootStatus
+	ValidSolution       bool // Status == RootConverged
+	Result              linalg.Vector
+	Residual            linalg.Vector // F(Result)
+	ResidualNorm        float64       // ||F(Result)||
+	Iterations          int
+	Evaluations         int
+	JacobianEvaluations int
+}
+
+// rootEvaluator counts the evaluations of the function and its Jacobian.
+type rootEvaluator struct {
+	f                   VectorFunc
+	jacobian            JacobianFunc
+	evaluations         int
+	jacobianEvaluations int
+}
+
+func (e *rootEvaluator) eval(x, fx linalg.Vector) float64 {
+	e.evaluations++
+	fx.Zero()
+	e.f(x, fx)
+	return blas.NRM2(fx)
+}
+
+func (e *rootEvaluator) jac(x linalg.Vector, J linalg.Matrix) {
+	e.jacobianEvaluations++
+	e.jacobian(x, J)
+}
+
+// RootFind solves the square nonlinear system F(x) = 0 starting at x0. The Jacobian is
+// optional; a missing Jacobian is approximated by central differences. The method is
+// set with WithRootMethod and defaults to Powell's hybrid method; the iteration stops
+// when ||F(x)||_inf is within the tolerance.
+func RootFind(F VectorFunc, jacobian JacobianFunc, x0 linalg.Vector, options ...func(*solveOptions)) *rootSolution {
+	opts := newSolveOptions(x0, options...)
+	n := x0.Len()
+	if jacobian == nil {
+		jacobian = CentralJacobianConstantStep(1e-6, ConstraintFunc(F), n)
+	}
+	e := &rootEvaluator{f: F, jacobian: jacobian}
+
+	var sol *rootSolution
+	switch opts.rootMethod {
+	case NewtonRoot, BroydenGoodRoot, BroydenBadRoot:
+		sol = lineSearchRoot(e, x0, opts)
+	default:
+		sol = hybridRoot(e, x0, opts)
+	}
+	sol.ValidSolution = sol.Status == RootConverged
+	sol.ResidualNorm = blas.NRM2(sol.Residual)
+	sol.Evaluations = e.evaluations
+	sol.JacobianEvaluations = e.jacobianEvaluations
+	return sol
+}
+
+// rootConverged returns whether ||fx||_inf is within the tolerance.
+func rootConverged(fx linalg.Vector, opts *solveOptions) bool {
+	return fx.Len() == 0 || math.Abs(fx[blas.IAMAX(fx)]) <= opts.tolerance
+}
+
+// lineSearchRoot is the Newton-Raphson method or one of Broyden's methods, with a
+// backtracking line search on ||F||. Newton steps solve J * p = -F by QR; Broyden's good
+// method does the same with the updated Jacobian, and the bad method multiplies by the
+// updated inverse. When a quasi-Newton step fails the line search, the Jacobian is
+// recomputed before giving up.
+func lineSearchRoot(e *rootEvaluator, x0 linalg.Vector, opts *solveOptions) *rootSolution {
+	n := x0.Len()
+	method := opts.rootMethod
+	x := linalg.NewVector(n)
+	xt := linalg.NewVector(n)
+	fx := linalg.NewVector(n)
+	ft := linalg.NewVector(n)
+	p := linalg.NewVector(n)
+	y := linalg.NewVector(n)
+	J := linalg.NewDenseMatrix(n, n) // the Jacobian, or its inverse for the bad method
+	blas.COPY(x0, x)
+
+	// refresh recomputes the Jacobian at x, and inverts it for the bad method
+	refresh := func() bool {
+		e.jac(x, J)
+		if method != BroydenBadRoot {
+			return true
+		}
+		lu, err := linalg.FactorLU(J)
+		if err != nil {
+			return false
+		}
+		lu.Inverse(J)
+		return true
+	}
+
+	sol := &rootSolution{Status: RootMaxIterations, Result: x, Residual: fx}
+	norm := e.eval(x, fx)
+	fresh := refresh()
+	if !fresh {
+		sol.Status = RootNoProgress
+		return sol
+	}
+	for ; sol.Iterations < opts.maxIterations; sol.Iterations++ {
+		if rootConverged(fx, opts) {
+			sol.Status = RootConverged
+			return sol
+		}
+		if method == BroydenBadRoot {
+			blas.GEMV(-1, J, fx, 0, p)
+		} else {
+			blas.SCAL(-1, fx)
+			linalg.FactorQR(J).SolveLeastSquares(fx, p)
+			blas.SCAL(-1, fx)
+		}
+
+		// ||F(x + alpha * p)||^2 <= (1 - 2 * c * alpha) * ||F(x)||^2, the Armijo condition
+		// on 1/2 * ||F||^2 for the Newton direction
+		alpha := 1.0
+		normT := 0.0
+		for {
+			blas.COPY(x, xt)
+			blas.AXPY(alpha, p, xt)
+			normT = e.eval(xt, ft)
+			if normT*normT <= (1-2*ROOT_ARMIJO*alpha)*norm*norm || alpha < ROOT_MIN_STEP {
+				break
+			}
+			alpha /= 2
+		}
+		if !(normT < norm) {
+			if method == NewtonRoot || fresh {
+				sol.Status = RootNoProgress
+				return sol
+			}
+			if fresh = refresh(); !fresh {
+				sol.Status = RootNoProgress
+				return sol
+			}
+			continue
+		}
+
+		blas.SCAL(alpha, p)
+		blas.COPY(ft, y)
+		blas.AXPY(-1, fx, y)
+		blas.COPY(xt, x)
+		blas.COPY(ft, fx)
+		norm = normT
+		switch method {
+		case NewtonRoot:
+			e.jac(x, J)
+		case BroydenGoodRoot:
+			UpdateJacobianBroyden(J, y, p)
+			fresh = false
+		case BroydenBadRoot:
+			UpdateJacobianInverseBroyden(J, y, p)
+			fresh = false
+		}
+	}
+	if rootConverged(fx, opts) {
+		sol.Status = RootConverged
+	}
+	return sol
+}
+
+// hybridRoot is Powell's hybrid method as in MINPACK's hybrj (More, Garbow and
+// Hillstrom, User Guide for MINPACK-1, 1980). Each step is a dogleg step between the
+// Newton step and the steepest descent step of ||F||^2 in a trust region. The Jacobian
+// is recomputed only when the steps fail twice in a row, and otherwise kept current with
+// Broyden's rank-1 updates.
+func hybridRoot(e *rootEvaluator, x0 linalg.Vector, opts *solveOptions) *rootSolution {
+	n := x0.Len()
+	x := linalg.NewVector(n)
+	xt := linalg.NewVector(n)
+	fx := linalg.NewVector(n)
+	ft := linalg.NewVector(n)
+	p := linalg.NewVector(n)
+	pn := linalg.NewVector(n)
+	g := linalg.NewVector(n)
+	Jg := linalg.NewVector(n)
+	Jp := linalg.NewVector(n)
+	y := linalg.NewVector(n)
+	J := linalg.NewDenseMatrix(n, n)
+	blas.COPY(x0, x)
+
+	sol := &rootSolution{Status: RootMaxIterations, Result: x, Residual: fx}
+	norm := e.eval(x, fx)
+	e.jac(x, J)
+	delta := HYBRID_INITIAL_RADIUS * blas.NRM2(x)
+	if delta == 0 {
+		delta = HYBRID_INITIAL_RADIUS
+	}
+	successes, failures := 0, 0
+	for ; sol.Iterations < opts.maxIterations; sol.Iterations++ {
+		if rootConverged(fx, opts) {
+			sol.Status = RootConverged
+			return sol
+		}
+
+		// the Newton step and the steepest descent direction g = -J^T * F
+		blas.SCAL(-1, fx)
+		linalg.FactorQR(J).SolveLeastSquares(fx, pn)
+		blas.GEMVT(1, J, fx, 0, g)
+		blas.SCAL(-1, fx)
+		blas.GEMV(1, J, g, 0, Jg)
+		doglegRootStep(pn, g, Jg, delta, p)
+
+		blas.COPY(x, xt)
+		blas.AXPY(1, p, xt)
+		normT := e.eval(xt, ft)
+		pnorm := blas.NRM2(p)
+		if sol.Iterations == 0 {
+			delta = math.Min(delta, pnorm)
+		}
+
+		// the ratio of the actual to the predicted reduction of ||F||
+		blas.COPY(fx, Jp)
+		blas.GEMV(1, J, p, 1, Jp)
+		actual, predicted := -1.0, 0.0
+		if normT < norm {
+			actual = 1 - normT/norm
+		}
+		if mnorm := blas.NRM2(Jp); mnorm < norm {
+			predicted = 1 - mnorm/norm
+		}
+		ratio := 0.0
+		if predicted > 0 {
+			ratio = actual / predicted
+		}
+
+		if ratio < 0.1 {
+			successes = 0
+			failures++
+			delta *= 0.5
+		} else {
+			failures = 0
+			successes++
+			if ratio >= 0.5 || successes > 1 {
+				delta = math.Max(delta, pnorm/0.5)
+			}
+			if math.Abs(ratio-1) <= 0.1 {
+				delta = pnorm / 0.5
+			}
+		}
+
+		blas.COPY(ft, y)
+		blas.AXPY(-1, fx, y)
+		if ratio >= 1e-4 && !math.IsNaN(normT) {
+			blas.COPY(xt, x)
+			blas.COPY(ft, fx)
+			norm = normT
+		}
+		if delta <= opts.tolerance*(blas.NRM2(x)+opts.tolerance) {
+			sol.Status = RootNoProgress
+			if rootConverged(fx, opts) {
+				sol.Status = RootConverged
+			}
+			return sol
+		}
+		if failures == 2 {
+			e.jac(x, J)
+			failures = 0
+		} else if !math.IsNaN(normT) {
+			UpdateJacobianBroyden(J, y, p)
+		}
+	}
+	if rootConverged(fx, opts) {
+		sol.Status = RootConverged
+	}
+	return sol
+}
+
+// doglegRootStep writes into p the dogleg step of length at most delta between the
+// Newton step pn and the minimizer of the linear model along the steepest descent
+// direction g, with Jg = J * g.
+func doglegRootStep(pn, g, Jg linalg.Vector, delta float64, p linalg.Vector) {
+	if blas.NRM2(pn) <= delta {
+		blas.COPY(pn, p)
+		return
+	}
+	gnorm := blas.NRM2(g)
+	jgnorm := blas.NRM2(Jg)
+	if gnorm == 0 || jgnorm == 0 {
+		blas.CPSC(delta/blas.NRM2(pn), pn, p)
+		return
+	}
+
+	// the Cauchy point, scaled to the boundary if it lies outside
+	tau := gnorm * gnorm / (jgnorm * jgnorm)
+	if tau*gnorm >= delta {
+		blas.CPSC(delta/gnorm, g, p)
+		return
+	}
+
+	// p = pc + t * (pn - pc) with ||p|| = delta
+	pc := linalg.NewVector(g.Len())
+	blas.CPSC(tau, g, pc)
+	d := linalg.NewVector(g.Len())
+	blas.COPY(pn, d)
+	blas.AXPY(-1, pc, d)
+	a := blas.DOT(d, d)
+	b := 2 * blas.DOT(pc, d)
+	c := blas.DOT(pc, pc) - delta*delta
+	t := (-b + math.Sqrt(b*b-4*a*c)) / (2 * a)
+	blas.COPY(pc, p)
+	blas.AXPY(t, d, p)
+}
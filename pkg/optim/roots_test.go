@@ -0,0 +1,115 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// broydenTridiagonal is Broyden's tridiagonal function
+// F_i = (3 - 2 * x_i) * x_i - x_{i-1} - 2 * x_{i+1} + 1 with x_0 = x_{n+1} = 0.
+func broydenTridiagonal(x, fx linalg.Vector) {
+	n := x.Len()
+	for i := 0; i < n; i++ {
+		fx[i] = (3-2*x[i])*x[i] + 1
+		if i > 0 {
+			fx[i] -= x[i-1]
+		}
+		if i < n-1 {
+			fx[i] -= 2 * x[i+1]
+		}
+	}
+}
+
+func broydenTridiagonalJacobian(x linalg.Vector, J linalg.Matrix) {
+	n := x.Len()
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			J.Set(i, j, 0)
+		}
+		J.Set(i, i, 3-4*x[i])
+		if i > 0 {
+			J.Set(i, i-1, -1)
+		}
+		if i < n-1 {
+			J.Set(i, i+1, -2)
+		}
+	}
+}
+
+func TestRootFindMethods(t *testing.T) {
+	methods := map[string]optim.RootMethod{
+		"hybrid":       optim.HybridRoot,
+		"newton":       optim.NewtonRoot,
+		"broyden good": optim.BroydenGoodRoot,
+		"broyden bad":  optim.BroydenBadRoot,
+	}
+	for name, method := range methods {
+		for _, jacobian := range []optim.JacobianFunc{broydenTridiagonalJacobian, nil} {
+			x0 := linalg.NewVector(10).Set(-1)
+			sol := optim.RootFind(broydenTridiagonal, jacobian, x0, optim.WithRootMethod(method))
+			if !sol.ValidSolution || sol.Status != optim.RootConverged {
+				t.Fatalf("%s: expected convergence, got %+v", name, sol)
+			}
+			fx := linalg.NewVector(10)
+			broydenTridiagonal(sol.Result, fx)
+			for i := range fx {
+				if math.Abs(fx[i]) > 1e-8 {
+					t.Errorf("%s: expected a root, got F = %v", name, fx)
+					break
+				}
+			}
+			if math.Abs(sol.ResidualNorm-math.Sqrt(dot(fx, fx))) > 1e-12 {
+				t.Errorf("%s: expected residual norm %v, got %v", name, math.Sqrt(dot(fx, fx)), sol.ResidualNorm)
+			}
+			if method != optim.NewtonRoot && jacobian != nil && sol.JacobianEvaluations >= sol.Iterations {
+				t.Errorf("%s: expected fewer Jacobians than iterations, got %d and %d", name, sol.JacobianEvaluations, sol.Iterations)
+			}
+		}
+	}
+}
+
+func TestRootFindRosenbrock(t *testing.T) {
+	// F = (10 * (x2 - x1^2), 1 - x1) from the standard start, which defeats undamped Newton
+	F := func(x, fx linalg.Vector) {
+		fx[0] = 10 * (x[1] - x[0]*x[0])
+		fx[1] = 1 - x[0]
+	}
+	for _, method := range []optim.RootMethod{optim.HybridRoot, optim.NewtonRoot} {
+		sol := optim.RootFind(F, nil, linalg.Vector{-1.2, 1}, optim.WithRootMethod(method))
+		if !sol.ValidSolution || math.Abs(sol.Result[0]-1) > 1e-8 || math.Abs(sol.Result[1]-1) > 1e-8 {
+			t.Errorf("method %d: expected [1 1], got %+v", method, sol)
+		}
+	}
+}
+
+func TestRootFindNoRoot(t *testing.T) {
+	// F = (x1^2 + 1, x2) has no root; ||F|| is smallest at the origin
+	F := func(x, fx linalg.Vector) {
+		fx[0] = x[0]*x[0] + 1
+		fx[1] = x[1]
+	}
+	for _, method := range []optim.RootMethod{optim.HybridRoot, optim.NewtonRoot, optim.BroydenGoodRoot} {
+		sol := optim.RootFind(F, nil, linalg.Vector{2, 1}, optim.WithRootMethod(method), optim.WithMaxIterations(200))
+		if sol.ValidSolution || sol.Status == optim.RootConverged {
+			t.Errorf("method %d: expected no convergence, got %+v", method, sol)
+		}
+		if sol.ResidualNorm < 1-1e-6 {
+			t.Errorf("method %d: expected a residual of at least 1, got %v", method, sol.ResidualNorm)
+		}
+	}
+}
+
+func TestRootFindHybridCollapsedRegion(t *testing.T) {
+	// the exact Newton step of a steep linear F lands on the root at 0, where the trust
+	// region is already smaller than the tolerance allows
+	F := func(x, fx linalg.Vector) {
+		fx[0] = 1e10 * x[0]
+	}
+	sol := optim.RootFind(F, nil, linalg.Vector{1e-15}, optim.WithTolerance(1e-6))
+	if !sol.ValidSolution || sol.Status != optim.RootConverged || sol.Result[0] != 0 {
+		t.Errorf("expected convergence to the root 0, got %+v", sol)
+	}
+}
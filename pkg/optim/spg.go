@@ -0,0 +1,149 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// Safeguards for the spectral step length and the nonmonotone line search.
+var SPG_LAMBDA_MIN = 1e-30
+var SPG_LAMBDA_MAX = 1e30
+var SPG_GAMMA = 1e-4
+var SPG_SIGMA1 = 0.1
+var SPG_SIGMA2 = 0.9
+
+// spgSolver is the spectral projected gradient method (SPG2) of Birgin, Martinez
+// and Raydan, "Nonmonotone Spectral Projected Gradient Methods on Convex Sets",
+// SIAM J. Optim., 2000.
+type spgSolver struct{}
+
+func (s *spgSolver) Solve(f ObjectiveFunc, x0 linalg.Vector, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(x0, options...)
+	if check := failedGradientCheck(f, x0, opts); check != nil {
+		return &solution{Result: vectorCopy(x0), Objective: f(x0), GradientCheck: check}
+	}
+	return s.solve(f, x0, opts)
+}
+
+func (s *spgSolver) solve(f ObjectiveFunc, x0 linalg.Vector, opts *solveOptions) *solution {
+	evaluateGradient := opts.gradientFunc
+	tolerance := opts.tolerance
+	maxIterations := opts.maxIterations
+	projector := opts.projector
+	if projector == nil {
+		if opts.bounds != nil {
+			projector = *opts.bounds
+		} else {
+			projector = Bounds{}
+		}
+	}
+
+	n := x0.Len()
+	x := linalg.NewVector(n)
+	x1 := linalg.NewVector(n)
+	g := linalg.NewVector(n)
+	g1 := linalg.NewVector(n)
+	d := linalg.NewVector(n)
+	sk := linalg.NewVector(n)
+	yk := linalg.NewVector(n)
+
+	blas.COPY(x0, x)
+	projector.Project(x)
+	fx := f(x)
+	evaluateGradient(x, f, g)
+	pgNorm := projectedGradient(projector, x, g, d)
+
+	// history of objective values for the nonmonotone (GLL) line search
+	memory := max(opts.memory, 1)
+	history := make([]float64, 0, memory)
+	history = append(history, fx)
+
+	lambda := 1.0
+	if pgNorm > 0 {
+		lambda = math.Min(SPG_LAMBDA_MAX, math.Max(SPG_LAMBDA_MIN, 1.0/pgNorm))
+	}
+
+	iter := 0
+	for pgNorm > tolerance && iter < maxIterations {
+		// spectral projected gradient direction d = P(x - lambda * g) - x
+		blas.COPY(x, d)
+		blas.AXPY(-lambda, g, d)
+		projector.Project(d)
+		blas.AXPY(-1.0, x, d)
+
+		fmax := history[0]
+		for _, fh := range history {
+			fmax = math.Max(fmax, fh)
+		}
+
+		gd := blas.DOT(g, d)
+		alpha := 1.0
+		f1 := 0.0
+		for {
+			blas.COPY(x, x1)
+			blas.AXPY(alpha, d, x1)
+			f1 = f(x1)
+			if f1 <= fmax+SPG_GAMMA*alpha*gd {
+				break
+			}
+			alphaTmp := -0.5 * alpha * alpha * gd / (f1 - fx - alpha*gd)
+			if alphaTmp >= SPG_SIGMA1 && alphaTmp <= SPG_SIGMA2*alpha {
+				alpha = alphaTmp
+			} else {
+				alpha = 0.5 * alpha
+			}
+			if alpha*blas.NRM2(d) <= epsilon*math.Max(1.0, blas.NRM2(x)) {
+				break
+			}
+		}
+		if f1 > fmax+SPG_GAMMA*alpha*gd {
+			// the line search stalled
+			break
+		}
+
+		evaluateGradient(x1, f, g1)
+		blas.COPY(x1, sk)
+		blas.AXPY(-1.0, x, sk)
+		blas.COPY(g1, yk)
+		blas.AXPY(-1.0, g, yk)
+
+		// Barzilai-Borwein step length
+		sts := blas.DOT(sk, sk)
+		sty := blas.DOT(sk, yk)
+		if sty <= 0 {
+			lambda = SPG_LAMBDA_MAX
+		} else {
+			lambda = math.Min(SPG_LAMBDA_MAX, math.Max(SPG_LAMBDA_MIN, sts/sty))
+		}
+
+		x, x1 = x1, x
+		g, g1 = g1, g
+		fx = f1
+		if len(history) == memory {
+			history = history[1:]
+		}
+		history = append(history, fx)
+		iter++
+
+		pgNorm = projectedGradient(projector, x, g, d)
+	}
+
+	result := linalg.NewVector(n)
+	blas.COPY(x, result)
+
+	return &solution{
+		ValidSolution: pgNorm <= tolerance,
+		Result:        result,
+		Iterations:    iter,
+		GradientNorm:  pgNorm,
+		Objective:     fx,
+	}
+}
+
+// NewSPGSolver returns a spectral projected gradient solver for minimizing over the
+// convex set given by WithProjector, or by WithBounds if no projector is set.
+func NewSPGSolver() *spgSolver {
+	return &spgSolver{}
+}
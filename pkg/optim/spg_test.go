@@ -0,0 +1,122 @@
+package optim_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+func TestProjectors(t *testing.T) {
+	x := linalg.Vector{0.5, 2.0, -1.0}
+	optim.Simplex{Radius: 1}.Project(x)
+	if math.Abs(x[0]) > 1e-12 || math.Abs(x[1]-1) > 1e-12 || math.Abs(x[2]) > 1e-12 {
+		t.Errorf("Expected [0 1 0], got %v", x)
+	}
+
+	x = linalg.Vector{0.5, 0.2, 0.9}
+	optim.Simplex{Radius: 1}.Project(x)
+	if math.Abs(x[0]-0.3) > 1e-12 || math.Abs(x[1]) > 1e-12 || math.Abs(x[2]-0.7) > 1e-12 {
+		t.Errorf("Expected [0.3 0 0.7], got %v", x)
+	}
+
+	x = linalg.Vector{3, 4}
+	optim.L2Ball{Radius: 1}.Project(x)
+	if math.Abs(x[0]-0.6) > 1e-12 || math.Abs(x[1]-0.8) > 1e-12 {
+		t.Errorf("Expected [0.6 0.8], got %v", x)
+	}
+
+	x = linalg.Vector{2, -1}
+	optim.L1Ball{Radius: 1}.Project(x)
+	if math.Abs(x[0]-1) > 1e-12 || math.Abs(x[1]) > 1e-12 {
+		t.Errorf("Expected [1 0], got %v", x)
+	}
+
+	x = linalg.Vector{1, 1}
+	optim.Hyperplane{Normal: linalg.Vector{1, 1}, Offset: 0}.Project(x)
+	if math.Abs(x[0]) > 1e-12 || math.Abs(x[1]) > 1e-12 {
+		t.Errorf("Expected [0 0], got %v", x)
+	}
+}
+
+func TestProjectorErrors(t *testing.T) {
+	projectors := []struct {
+		projector optim.Projector
+		err       error
+	}{
+		{optim.Simplex{Radius: 0}, optim.ErrSimplexRadius},
+		{optim.Hyperplane{Normal: linalg.Vector{0, 0}}, optim.ErrHyperplaneNormal},
+	}
+	for _, p := range projectors {
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !errors.Is(err, p.err) {
+					t.Errorf("%T: expected %v, got %v", p.projector, p.err, err)
+				}
+			}()
+			p.projector.Project(linalg.Vector{1, 2})
+		}()
+	}
+}
+
+func TestSPGSolver_Simplex(t *testing.T) {
+	solver := optim.NewSPGSolver()
+
+	// minimize |x - c|^2 over the probability simplex
+	c := linalg.Vector{0.9, 0.6, -0.3, 0.1}
+	f := func(X linalg.Vector) float64 {
+		sum := 0.0
+		for i := range X {
+			sum += (X[i] - c[i]) * (X[i] - c[i])
+		}
+		return sum
+	}
+	grad := func(X linalg.Vector, f optim.ObjectiveFunc, gradF linalg.Vector) float64 {
+		for i := range X {
+			gradF[i] = 2 * (X[i] - c[i])
+		}
+		return blas.NRM2(gradF)
+	}
+
+	x0 := linalg.NewVector(4)
+	solution := solver.Solve(f, x0,
+		optim.WithTolerance(1e-10),
+		optim.WithGradientFunc(grad),
+		optim.WithProjector(optim.Simplex{Radius: 1}),
+	)
+
+	expected := linalg.Vector{0.65, 0.35, 0, 0}
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	for i := range expected {
+		if math.Abs(solution.Result[i]-expected[i]) > 1e-8 {
+			t.Errorf("Expected %v, got %v", expected, solution.Result)
+			break
+		}
+	}
+}
+
+func TestSPGSolver_L2Ball(t *testing.T) {
+	solver := optim.NewSPGSolver()
+
+	x0 := linalg.Vector{0, 0}
+	solution := solver.Solve(RosenbrockFunction, x0,
+		optim.WithTolerance(1e-8),
+		optim.WithMaxIterations(10000),
+		optim.WithGradientFunc(RosenbrockFunctionGradient),
+		optim.WithProjector(optim.L2Ball{Radius: 1}),
+	)
+
+	// the constrained minimizer lies on the boundary of the unit ball
+	r := math.Hypot(solution.Result[0], solution.Result[1])
+	if !solution.ValidSolution || math.Abs(r-1) > 1e-6 {
+		t.Errorf("Expected a solution on the unit circle, got %+v", solution)
+	}
+	if math.Abs(solution.Result[0]-0.7864) > 1e-3 || math.Abs(solution.Result[1]-0.6177) > 1e-3 {
+		t.Errorf("Expected [0.7864 0.6177], got %v", solution.Result)
+	}
+}
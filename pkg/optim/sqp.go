@@ -0,0 +1,314 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/internal/quadprog"
+)
+
+var SQP_ETA = 1e-4            // sufficient decrease parameter of the merit line search
+var SQP_PENALTY_MARGIN = 1e-3 // margin of the merit penalty over the largest multiplier
+var SQP_MIN_STEP = 1e-10      // smallest step length tried by the line search
+
+// sqpSolver is a line search SQP method with a damped BFGS approximation of the
+// Hessian of the Lagrangian and the l1 merit function
+//
+//	phi(x) = f(x) + nu * (|g(x)|_1 + |max(h(x), 0)|_1)
+//
+// A second-order correction is tried when the full step is rejected to avoid the
+// Maratos effect (Nocedal and Wright, Numerical Optimization, 18.3).
+type sqpSolver struct{}
+
+// sqpIterate holds the problem functions evaluated at a point.
+type sqpIterate struct {
+	x     linalg.Vector
+	f     float64
+	gradF linalg.Vector
+	g     linalg.Vector
+	h     linalg.Vector
+	Jg    linalg.Matrix
+	Jh    linalg.Matrix
+}
+
+func newSQPIterate(n, me, mi int) *sqpIterate {
+	return &sqpIterate{
+		x:     linalg.NewVector(n),
+		gradF: linalg.NewVector(n),
+		g:     linalg.NewVector(me),
+		h:     linalg.NewVector(mi),
+		Jg:    linalg.NewDenseMatrix(me, n),
+		Jh:    linalg.NewDenseMatrix(mi, n),
+	}
+}
+
+// violation returns the l1 norm of the constraint violation.
+func (it *sqpIterate) violation() float64 {
+	v := blas.ASUM(it.g)
+	for i := range it.h {
+		v += math.Max(it.h[i], 0)
+	}
+	return v
+}
+
+func (s *sqpSolver) Solve(problem *ConstrainedProblem, x0 linalg.Vector, options ...func(*solveOptions)) *constrainedSolution {
+	opts := newSolveOptions(x0, options...)
+	if check := failedGradientCheck(problem.Objective, x0, opts); check != nil {
+		return &constrainedSolution{Result: vectorCopy(x0), Objective: problem.Objective(x0), GradientCheck: check}
+	}
+	return s.solve(problem, x0, opts)
+}
+
+func (s *sqpSolver) solve(problem *ConstrainedProblem, x0 linalg.Vector, opts *solveOptions) *constrainedSolution {
+	f := problem.Objective
+	evaluateGradient := opts.gradientFunc
+	tolerance := opts.tolerance
+	maxIterations := opts.maxIterations
+	bounds := Bounds{}
+	if opts.bounds != nil {
+		bounds = *opts.bounds
+	}
+
+	n := x0.Len()
+	bounds.check(n)
+	e := newConstraintEvaluator(problem, n)
+	me := e.me
+	mi := e.mi
+
+	evaluate := func(it *sqpIterate, derivatives bool) {
+		it.f = f(it.x)
+		e.equality(it.x, it.g)
+		e.inequality(it.x, it.h)
+		if derivatives {
+			evaluateGradient(it.x, f, it.gradF)
+			e.jacobians(it.x, it.Jg, it.Jh)
+		}
+	}
+
+	cur := newSQPIterate(n, me, mi)
+	trial := newSQPIterate(n, me, mi)
+	soc := newSQPIterate(n, me, mi)
+	B := linalg.NewDenseMatrix(n, n)
+	B.Identity()
+
+	lambda := linalg.NewVector(me)
+	mu := linalg.NewVector(mi)
+	gradL := linalg.NewVector(n)
+	gradL0 := linalg.NewVector(n)
+	d := linalg.NewVector(n)
+	step := linalg.NewVector(n)
+	y := linalg.NewVector(n)
+	nu := 0.0
+
+	blas.COPY(x0, cur.x)
+	bounds.Project(cur.x)
+	evaluate(cur, true)
+
+	var active []int
+	iter := 0
+	converged := false
+	var stationarity, primal, complementarity float64
+	for !converged && iter < maxIterations {
+		res, ok := s.solveSubproblem(B, cur, cur.g, cur.h, bounds, active)
+		if !ok {
+			// the Hessian approximation lost positive definiteness or left the QP unsolved,
+			// so fall back to a steepest-descent subproblem
+			B.Identity()
+			res, ok = s.solveSubproblem(B, cur, cur.g, cur.h, bounds, active)
+			if !ok {
+				break
+			}
+		}
+		active = res.Active
+		blas.COPY(res.X, d)
+		lambdaHat := res.EqualityMultipliers
+		muHat := res.InequalityMultipliers[:mi]
+
+		// update the merit penalty so that d is a descent direction
+		maxMultiplier := 0.0
+		for i := range lambdaHat {
+			maxMultiplier = math.Max(maxMultiplier, math.Abs(lambdaHat[i]))
+		}
+		for i := range muHat {
+			maxMultiplier = math.Max(maxMultiplier, muHat[i])
+		}
+		if nu < maxMultiplier+SQP_PENALTY_MARGIN {
+			nu = 1.5*maxMultiplier + SQP_PENALTY_MARGIN
+		}
+
+		viol := cur.violation()
+		phi0 := cur.f + nu*viol
+		D := blas.DOT(cur.gradF, d) - nu*viol
+
+		// line search on the merit function, with a second-order correction for the full step
+		alpha := 1.0
+		accepted := false
+		next := trial
+		for alpha >= SQP_MIN_STEP {
+			blas.COPY(cur.x, trial.x)
+			blas.AXPY(alpha, d, trial.x)
+			bounds.Project(trial.x)
+			evaluate(trial, false)
+			if trial.f+nu*trial.violation() <= phi0+SQP_ETA*alpha*D {
+				accepted = true
+				break
+			}
+
+			if alpha == 1.0 && (me > 0 || mi > 0) {
+				// shift the linearized constraints by c(x + d) - J * d
+				gs := linalg.NewVector(me)
+				hs := linalg.NewVector(mi)
+				blas.COPY(trial.g, gs)
+				blas.COPY(trial.h, hs)
+				if me > 0 {
+					blas.GEMV(-1.0, cur.Jg, d, 1.0, gs)
+				}
+				if mi > 0 {
+					blas.GEMV(-1.0, cur.Jh, d, 1.0, hs)
+				}
+				if resSOC, ok := s.solveSubproblem(B, cur, gs, hs, bounds, active); ok && resSOC.Status == quadprog.Optimal {
+					blas.COPY(cur.x, soc.x)
+					blas.AXPY(1.0, resSOC.X, soc.x)
+					bounds.Project(soc.x)
+					evaluate(soc, false)
+					if soc.f+nu*soc.violation() <= phi0+SQP_ETA*D {
+						next = soc
+						accepted = true
+						break
+					}
+				}
+			}
+			alpha *= 0.5
+		}
+		if !accepted {
+			break
+		}
+
+		// damped BFGS update of the Lagrangian Hessian with the QP multipliers
+		lagrangianGradient(cur.gradF, cur.Jg, lambdaHat, cur.Jh, muHat, gradL0)
+		evaluate(next, true)
+		lagrangianGradient(next.gradF, next.Jg, lambdaHat, next.Jh, muHat, gradL)
+		blas.COPY(next.x, step)
+		blas.AXPY(-1.0, cur.x, step)
+		blas.COPY(gradL, y)
+		blas.AXPY(-1.0, gradL0, y)
+		UpdateHessianDampedBFGS(B, y, step)
+
+		blas.COPY(lambdaHat, lambda)
+		blas.COPY(muHat, mu)
+		if next == soc {
+			cur, soc = soc, cur
+		} else {
+			cur, trial = trial, cur
+		}
+		iter++
+
+		stationarity, primal, complementarity = kktResiduals(cur.x, gradL, cur.g, cur.h, mu, bounds)
+		xScale := 1.0 + math.Abs(cur.x[blas.IAMAX(cur.x)])
+		stepNorm := math.Abs(step[blas.IAMAX(step)])
+		converged = primal <= tolerance && complementarity <= tolerance &&
+			(stationarity <= tolerance || stepNorm <= tolerance*xScale)
+	}
+
+	result := linalg.NewVector(n)
+	blas.COPY(cur.x, result)
+	activeSet := make([]int, 0, len(active))
+	for _, i := range active {
+		if i < mi {
+			activeSet = append(activeSet, i)
+		}
+	}
+
+	return &constrainedSolution{
+		ValidSolution:         converged,
+		Result:                result,
+		Iterations:            iter,
+		Objective:             cur.f,
+		EqualityMultipliers:   lambda,
+		InequalityMultipliers: mu,
+		ActiveSet:             activeSet,
+		Stationarity:          stationarity,
+		PrimalInfeasibility:   primal,
+		Complementarity:       complementarity,
+	}
+}
+
+// solveSubproblem solves the QP
+//
+//	minimize 1/2 * d^T * B * d + gradF^T * d
+//	subject to g + Jg * d = 0, h + Jh * d <= 0, l <= x + d <= u
+//
+// If the linearized constraints are inconsistent, their right-hand sides are relaxed
+// towards the current point until the QP is feasible. Returns false if B is not
+// positive definite or the QP is not solved within its iteration limit.
+func (s *sqpSolver) solveSubproblem(B linalg.Matrix, it *sqpIterate, g, h linalg.Vector, bounds Bounds, warm []int) (quadprog.Result, bool) {
+	n := it.x.Len()
+	me := g.Len()
+	mi := h.Len()
+
+	rows := make([]int, 0, 2*n)
+	signs := make([]float64, 0, 2*n)
+	for i := 0; i < n; i++ {
+		if !math.IsInf(bounds.upper(i), 1) {
+			rows = append(rows, i)
+			signs = append(signs, 1)
+		}
+		if !math.IsInf(bounds.lower(i), -1) {
+			rows = append(rows, i)
+			signs = append(signs, -1)
+		}
+	}
+
+	Aineq := linalg.NewDenseMatrix(mi+len(rows), n)
+	bineq := linalg.NewVector(mi + len(rows))
+	for i := 0; i < mi; i++ {
+		for j := 0; j < n; j++ {
+			Aineq.Set(i, j, it.Jh.Get(i, j))
+		}
+	}
+	for k, i := range rows {
+		Aineq.Set(mi+k, i, signs[k])
+		if signs[k] > 0 {
+			bineq[mi+k] = bounds.upper(i) - it.x[i]
+		} else {
+			bineq[mi+k] = it.x[i] - bounds.lower(i)
+		}
+	}
+	beq := linalg.NewVector(me)
+
+	qp := quadprog.Problem{
+		G:     B,
+		C:     it.gradF,
+		Aeq:   it.Jg,
+		Beq:   beq,
+		Aineq: Aineq,
+		Bineq: bineq,
+	}
+	maxIterations := 10*(n+me+mi+len(rows)) + 50
+	for _, gamma := range []float64{1.0, 0.1, 0.01, 0.0} {
+		blas.CPSC(-gamma, g, beq)
+		for i := 0; i < mi; i++ {
+			if h[i] > 0 {
+				bineq[i] = -gamma * h[i]
+			} else {
+				bineq[i] = -h[i]
+			}
+		}
+		res := quadprog.Solve(qp, warm, maxIterations, quadprog.TOLERANCE)
+		switch res.Status {
+		case quadprog.NotConvex, quadprog.IterationLimit:
+			return res, false
+		case quadprog.Infeasible:
+			continue
+		}
+		return res, true
+	}
+	return quadprog.Result{}, false
+}
+
+// NewSQPSolver returns a sequential quadratic programming solver for small to medium
+// smooth problems with equality and inequality constraints.
+func NewSQPSolver() *sqpSolver {
+	return &sqpSolver{}
+}
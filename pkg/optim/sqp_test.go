@@ -0,0 +1,90 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// Example 15.4 from Nocedal and Wright, where the Maratos effect rejects full steps
+// without a second-order correction.
+func TestSQPSolver_Maratos(t *testing.T) {
+	solver := optim.NewSQPSolver()
+
+	problem := &optim.ConstrainedProblem{
+		Objective: func(X linalg.Vector) float64 {
+			return 2*(X[0]*X[0]+X[1]*X[1]-1) - X[0]
+		},
+		Equality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = X[0]*X[0] + X[1]*X[1] - 1
+		},
+		NumEquality: 1,
+		EqualityJacobian: func(X linalg.Vector, J linalg.Matrix) {
+			J.Set(0, 0, 2*X[0])
+			J.Set(0, 1, 2*X[1])
+		},
+	}
+
+	x0 := linalg.Vector{math.Cos(0.5), math.Sin(0.5)}
+	solution := solver.Solve(problem, x0, optim.WithTolerance(1e-8))
+
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	if math.Abs(solution.Result[0]-1) > 1e-6 || math.Abs(solution.Result[1]) > 1e-6 {
+		t.Errorf("Expected [1 0], got %v", solution.Result)
+	}
+	if math.Abs(solution.EqualityMultipliers[0]+1.5) > 1e-5 {
+		t.Errorf("Expected multiplier -1.5, got %v", solution.EqualityMultipliers)
+	}
+}
+
+func TestSQPSolver_HS071(t *testing.T) {
+	solver := optim.NewSQPSolver()
+
+	problem := &optim.ConstrainedProblem{
+		Objective: func(X linalg.Vector) float64 {
+			return X[0]*X[3]*(X[0]+X[1]+X[2]) + X[2]
+		},
+		Equality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = X[0]*X[0] + X[1]*X[1] + X[2]*X[2] + X[3]*X[3] - 40
+		},
+		NumEquality: 1,
+		Inequality: func(X linalg.Vector, c linalg.Vector) {
+			c[0] = 25 - X[0]*X[1]*X[2]*X[3]
+			c[1] = X[0] + X[1] - 20
+		},
+		NumInequality: 2,
+	}
+
+	x0 := linalg.Vector{1, 5, 5, 1}
+	solution := solver.Solve(problem, x0,
+		optim.WithTolerance(1e-7),
+		optim.WithBounds(optim.Bounds{
+			Lower: linalg.Vector{1, 1, 1, 1},
+			Upper: linalg.Vector{5, 5, 5, 5},
+		}),
+	)
+
+	expected := linalg.Vector{1, 4.74299963, 3.82114998, 1.37940829}
+	if !solution.ValidSolution {
+		t.Errorf("Expected valid solution, got %+v", solution)
+	}
+	for i := range expected {
+		if math.Abs(solution.Result[i]-expected[i]) > 1e-5 {
+			t.Errorf("Expected %v, got %v", expected, solution.Result)
+			break
+		}
+	}
+	if len(solution.ActiveSet) != 1 || solution.ActiveSet[0] != 0 {
+		t.Errorf("Expected active set [0], got %v", solution.ActiveSet)
+	}
+	if math.Abs(solution.InequalityMultipliers[0]-0.55229366) > 1e-4 {
+		t.Errorf("Expected multiplier 0.55229366, got %v", solution.InequalityMultipliers)
+	}
+	if solution.PrimalInfeasibility > 1e-7 {
+		t.Errorf("Expected a feasible solution, got violation %v", solution.PrimalInfeasibility)
+	}
+}
@@ -0,0 +1,268 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var NOISE_STEP = 1e-8   // spacing of the noise estimate points relative to max(1, ||x||)
+var NOISE_POINTS = 8    // number of spacings in the noise estimate difference table
+var GMSW_MAX_TRIALS = 6 // most trial intervals per coordinate in the step estimate
+
+const epsilon = 2.220446049250313e-16 // machine epsilon
+
+var (
+	forwardStepScale = math.Sqrt(epsilon)
+	centralStepScale = math.Cbrt(epsilon)
+)
+
+// scale returns max(|x_i|, typ_i), with typ_i = 1 if typical is nil.
+func scale(x linalg.Vector, typical linalg.Vector, i int) float64 {
+	t := 1.0
+	if typical != nil {
+		t = math.Abs(typical[i])
+	}
+	return math.Max(math.Abs(x[i]), t)
+}
+
+// ForwardGradientRelativeStep returns a gradientFunc of forward differences with the
+// steps h_i = sqrt(eps) * max(|x_i|, typ_i), which suit variables of any magnitude. The
+// typical magnitudes typ may be nil, in which case they are all 1.
+func ForwardGradientRelativeStep(typical linalg.Vector) gradientFunc {
+	return differenceGradient(false, nil, func(x linalg.Vector, i int) float64 {
+		return forwardStepScale * scale(x, typical, i)
+	})
+}
+
+// CentralGradientRelativeStep returns a gradientFunc of central differences with the
+// steps h_i = eps^(1/3) * max(|x_i|, typ_i), balancing truncation and rounding errors of
+// the second-order formula. The typical magnitudes typ may be nil.
+func CentralGradientRelativeStep(typical linalg.Vector) gradientFunc {
+	return differenceGradient(true, nil, func(x linalg.Vector, i int) float64 {
+		return centralStepScale * scale(x, typical, i)
+	})
+}
+
+// ForwardGradientSteps returns a gradientFunc of forward differences with the steps h.
+func ForwardGradientSteps(h linalg.Vector) gradientFunc {
+	return differenceGradient(false, nil, func(_ linalg.Vector, i int) float64 {
+		return h[i]
+	})
+}
+
+// ForwardGradientEstimatedStep returns a gradientFunc of forward differences whose steps
+// are chosen at the first point it is called with, normally x0: EstimateNoise measures
+// the noise in f there and EstimateForwardSteps picks the step of each coordinate. The
+// steps are then kept for the rest of the solve.
+func ForwardGradientEstimatedStep(n int) gradientFunc {
+	var gradient gradientFunc
+	return func(x linalg.Vector, f ObjectiveFunc, gradF linalg.Vector) float64 {
+		if x.Len() != n {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		if gradient == nil {
+			gradient = ForwardGradientSteps(EstimateForwardSteps(f, x, EstimateNoise(f, x)))
+		}
+		return gradient(x, f, gradF)
+	}
+}
+
+// WithBoundedDifferences replaces the gradient with central differences at the relative
+// steps of CentralGradientRelativeStep that never evaluate the objective outside the
+// bounds set by WithBounds. Near a bound a coordinate switches to the one-sided
+// second-order formula into the box. It takes precedence over WithGradientFunc.
+func WithBoundedDifferences(typical linalg.Vector) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.boundedDifferences = true
+		opts.typical = typical
+	}
+}
+
+// boundedGradient returns the gradient used by WithBoundedDifferences.
+func boundedGradient(bounds *Bounds, typical linalg.Vector) gradientFunc {
+	return differenceGradient(true, bounds, func(x linalg.Vector, i int) float64 {
+		return centralStepScale * scale(x, typical, i)
+	})
+}
+
+// differenceGradient approximates the gradient by forward or central differences with
+// the step of coordinate i at x given by step. With bounds, the points stay inside them:
+// a central difference becomes a one-sided second-order difference, and a forward
+// difference becomes a backward one, when the step would cross a bound.
+func differenceGradient(central bool, bounds *Bounds, step func(x linalg.Vector, i int) float64) gradientFunc {
+	return func(x linalg.Vector, f ObjectiveFunc, gradF linalg.Vector) float64 {
+		if gradF.Len() != x.Len() {
+			panic(linalg.ErrDimensionMismatch)
+		}
+		f0 := f(x)
+		nrm2 := 0.0
+		for i := range x {
+			xi := x[i]
+			h := math.Abs(step(x, i))
+
+			// room to the bounds on either side
+			below, above := math.Inf(1), math.Inf(1)
+			if bounds != nil {
+				below, above = xi-bounds.lower(i), bounds.upper(i)-xi
+			}
+
+			// eval returns f with x_i moved by s
+			eval := func(s float64) float64 {
+				x[i] = xi + s
+				fs := f(x)
+				x[i] = xi
+				return fs
+			}
+
+			// each step hp is rounded to one representable at x_i, so the differences
+			// divide by the step actually taken
+			g := 0.0
+			switch {
+			case below+above == 0:
+				// the box has zero width, so the variable is fixed and has no slope
+			case central && h <= below && h <= above:
+				hp := (xi + h) - xi
+				g = (eval(hp) - eval(-hp)) / (2 * hp)
+			case central && 2*h <= above:
+				hp := (xi + h) - xi
+				g = (-3*f0 + 4*eval(hp) - eval(2*hp)) / (2 * hp)
+			case central && 2*h <= below:
+				hp := xi - (xi - h)
+				g = (3*f0 - 4*eval(-hp) + eval(-2*hp)) / (2 * hp)
+			case h <= above:
+				hp := (xi + h) - xi
+				g = (eval(hp) - f0) / hp
+			case h <= below:
+				hp := xi - (xi - h)
+				g = (f0 - eval(-hp)) / hp
+			case above >= below:
+				// the box is narrower than the step, so use what there is of it
+				hp := (xi + above) - xi
+				g = (eval(hp) - f0) / hp
+			default:
+				hp := xi - (xi - below)
+				g = (f0 - eval(-hp)) / hp
+			}
+			gradF[i] = g
+			nrm2 = blas.Hypot(nrm2, g)
+		}
+		return nrm2
+	}
+}
+
+// EstimateNoise estimates the standard deviation of the noise in f near x from a table
+// of differences of f along a fixed direction, after Moré and Wild (Estimating
+// Computational Noise, 2011). The k-th differences of a smooth function at a small
+// spacing vanish, while those of noise with deviation sigma have mean square
+// sigma^2 * (2k)! / (k!)^2, so the estimate is taken at the first order where the
+// scaled differences agree and change sign. It is never below eps * |f(x)|.
+func EstimateNoise(f ObjectiveFunc, x linalg.Vector) float64 {
+	n := x.Len()
+	m := NOISE_POINTS
+	delta := NOISE_STEP * math.Max(1, blas.NRM2(x))
+
+	// the direction (1, -1, 1, ...) / sqrt(n)
+	p := linalg.NewVector(n)
+	for i := range p {
+		p[i] = 1 / math.Sqrt(float64(n))
+		if i%2 == 1 {
+			p[i] = -p[i]
+		}
+	}
+	xt := linalg.NewVector(n)
+	values := make([]float64, m+1)
+	for j := range values {
+		blas.COPY(x, xt)
+		blas.AXPY(float64(j-m/2)*delta, p, xt)
+		values[j] = f(xt)
+	}
+	floor := epsilon * math.Abs(values[m/2])
+
+	// sigma[k-1] is the noise estimate from the k-th differences
+	sigma := make([]float64, m)
+	signChange := make([]bool, m)
+	gamma := 1.0
+	for k := 1; k <= m; k++ {
+		for j := 0; j <= m-k; j++ {
+			values[j] = values[j+1] - values[j]
+		}
+		gamma *= float64(k) / float64(2*(2*k-1)) // (k!)^2 / (2k)!
+		sum := 0.0
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for j := 0; j <= m-k; j++ {
+			sum += values[j] * values[j]
+			lo, hi = math.Min(lo, values[j]), math.Max(hi, values[j])
+		}
+		sigma[k-1] = math.Sqrt(gamma * sum / float64(m-k+1))
+		signChange[k-1] = lo < 0 && hi > 0
+	}
+	for k := 0; k+2 < m; k++ {
+		lo := math.Min(sigma[k], math.Min(sigma[k+1], sigma[k+2]))
+		hi := math.Max(sigma[k], math.Max(sigma[k+1], sigma[k+2]))
+		if signChange[k] && hi <= 4*lo {
+			return math.Max(sigma[k], floor)
+		}
+	}
+	return math.Max(floor, epsilon)
+}
+
+// EstimateForwardSteps returns a forward-difference step for each coordinate of x by the
+// method of Gill, Murray, Saunders and Wright (Computing Forward-Difference Intervals for
+// Numerical Optimization, 1983) for an objective with absolute noise noise. Trial
+// intervals are scaled by 10 until the second difference at one of them has a relative
+// cancellation error between 0.001 and 0.1; that curvature phi then gives the step
+// 2 * sqrt(noise / |phi|), which minimizes the sum of the truncation and noise errors.
+func EstimateForwardSteps(f ObjectiveFunc, x linalg.Vector, noise float64) linalg.Vector {
+	n := x.Len()
+	f0 := f(x)
+	h := linalg.NewVector(n)
+	for i := range x {
+		xi := x[i]
+		hbar := 2 * (1 + math.Abs(xi)) * math.Sqrt(noise/(1+math.Abs(f0)))
+
+		// curvature returns the second difference at step s and its relative cancellation error
+		curvature := func(s float64) (float64, float64) {
+			x[i] = xi + s
+			fp := f(x)
+			x[i] = xi - s
+			fm := f(x)
+			x[i] = xi
+			phi := (fp - 2*f0 + fm) / (s * s)
+			return phi, 4 * noise / (s * s * math.Abs(phi))
+		}
+
+		s := 10 * hbar
+		phi, accepted := 0.0, false
+		direction := 0
+		for k := 0; k < GMSW_MAX_TRIALS && !accepted; k++ {
+			trial, cancellation := curvature(s)
+			switch {
+			case cancellation >= 1e-3 && cancellation <= 0.1:
+				phi, accepted = trial, true
+			case cancellation < 1e-3:
+				// truncation error dominates, so shrink the interval unless it just grew
+				phi = trial
+				if direction > 0 {
+					accepted = true
+				}
+				direction = -1
+				s /= 10
+			default:
+				// cancellation dominates, so keep the last smaller interval or grow
+				if direction < 0 {
+					accepted = true
+				}
+				direction = 1
+				s *= 10
+			}
+		}
+		if phi != 0 && !math.IsInf(phi, 0) && !math.IsNaN(phi) {
+			h[i] = 2 * math.Sqrt(noise/math.Abs(phi))
+		} else {
+			h[i] = hbar
+		}
+	}
+	return h
+}
@@ -0,0 +1,150 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// badlyScaled has variables of magnitude 1e-6, 1 and 1e6 around its minimum s.
+var badlyScaledMinimum = linalg.Vector{2e-6, 3, 5e6}
+
+func badlyScaled(x linalg.Vector) float64 {
+	f := 0.0
+	for i, s := range badlyScaledMinimum {
+		d := x[i]/s - 1
+		f += d*d + d*d*d
+	}
+	return f
+}
+
+func badlyScaledGradient(x linalg.Vector) linalg.Vector {
+	g := linalg.NewVector(3)
+	for i, s := range badlyScaledMinimum {
+		d := x[i]/s - 1
+		g[i] = (2*d + 3*d*d) / s
+	}
+	return g
+}
+
+func TestRelativeStepGradients(t *testing.T) {
+	x := linalg.Vector{3e-6, 2.5, 4e6}
+	want := badlyScaledGradient(x)
+	g := linalg.NewVector(3)
+
+	relativeError := func() float64 {
+		e := 0.0
+		for i := range g {
+			e = math.Max(e, math.Abs(g[i]-want[i])/math.Abs(want[i]))
+		}
+		return e
+	}
+
+	// a constant step swamps the smallest variable and vanishes next to the largest
+	optim.CentralGradientConstantStep(1e-4, 3)(x, badlyScaled, g)
+	if e := relativeError(); e < 1 {
+		t.Errorf("expected the constant step to fail, got relative error %v", e)
+	}
+
+	typical := linalg.Vector{1e-6, 1, 1e6}
+	for name, test := range map[string]struct {
+		grad  func(linalg.Vector, optim.ObjectiveFunc, linalg.Vector) float64
+		limit float64
+	}{
+		"forward": {optim.ForwardGradientRelativeStep(typical), 1e-6},
+		"central": {optim.CentralGradientRelativeStep(typical), 1e-9},
+	} {
+		g.Zero()
+		test.grad(x, badlyScaled, g)
+		if e := relativeError(); e > test.limit {
+			t.Errorf("%s: expected relative error below %v, got %v", name, test.limit, e)
+		}
+	}
+}
+
+// noisy is a smooth function plus deterministic noise, uniform on [-1e-8, 1e-8]
+func noisy(x linalg.Vector) float64 {
+	u := uint64(0x9e3779b97f4a7c15)
+	for _, v := range x {
+		u = (u ^ math.Float64bits(v)) * 0xbf58476d1ce4e5b9
+		u ^= u >> 31
+	}
+	noise := 2*float64(u>>11)/(1<<53) - 1
+	return math.Exp(x[0]) + x[0]*x[1]*x[1] + 1e-8*noise
+}
+
+func TestEstimatedSteps(t *testing.T) {
+	x := linalg.Vector{0.5, -1.5}
+	want := linalg.Vector{math.Exp(x[0]) + x[1]*x[1], 2 * x[0] * x[1]}
+
+	// uniform noise on [-1e-8, 1e-8] has deviation 1e-8 / sqrt(3)
+	sigma := optim.EstimateNoise(noisy, x)
+	if sigma < 1e-9 || sigma > 3e-8 {
+		t.Errorf("expected a noise estimate near 5.8e-9, got %v", sigma)
+	}
+	smooth := func(x linalg.Vector) float64 { return math.Exp(x[0]) + x[0]*x[1]*x[1] }
+	if s := optim.EstimateNoise(smooth, x); s > 1e-14 {
+		t.Errorf("expected noise near rounding error for a smooth function, got %v", s)
+	}
+
+	h := optim.EstimateForwardSteps(noisy, x, sigma)
+	for i := range h {
+		// the optimal step 2 * sqrt(sigma / |f''|) is around 1e-4
+		if h[i] < 1e-5 || h[i] > 1e-3 {
+			t.Errorf("h[%d]: expected a step near 1e-4, got %v", i, h[i])
+		}
+	}
+
+	g := linalg.NewVector(2)
+	errorOf := func(grad func(linalg.Vector, optim.ObjectiveFunc, linalg.Vector) float64) float64 {
+		grad(x, noisy, g)
+		return math.Max(math.Abs(g[0]-want[0]), math.Abs(g[1]-want[1]))
+	}
+	estimated := errorOf(optim.ForwardGradientEstimatedStep(2))
+	naive := errorOf(optim.ForwardGradientRelativeStep(nil))
+	if estimated > 1e-3 || estimated > naive/10 {
+		t.Errorf("expected the estimated steps to beat sqrt(eps) steps, got errors %v and %v", estimated, naive)
+	}
+}
+
+func TestBoundedDifferences(t *testing.T) {
+	// the objective is undefined below x0 = 0, where its constrained minimum lies
+	f := func(x linalg.Vector) float64 {
+		return (x[0]+1)*(x[0]+1) + 0*math.Sqrt(x[0]) + (x[1]-2)*(x[1]-2)
+	}
+	bounds := optim.Bounds{Lower: linalg.Vector{0, math.Inf(-1)}, Upper: linalg.Vector{math.Inf(1), 1e-9 + 2}}
+	x0 := linalg.Vector{0, 2}
+
+	g := linalg.NewVector(2)
+	optim.CentralGradientConstantStep(1e-4, 2)(x0, f, g)
+	if !math.IsNaN(g[0]) {
+		t.Fatalf("expected central differences to leave the domain, got %v", g)
+	}
+
+	sol := optim.NewLBFGSBSolver().Solve(f, x0, optim.WithBounds(bounds),
+		optim.WithBoundedDifferences(nil), optim.WithTolerance(1e-8))
+	if !sol.ValidSolution || sol.Result[0] != 0 || math.Abs(sol.Result[1]-2) > 1e-8 {
+		t.Errorf("expected the minimum [0 2], got %+v", sol)
+	}
+	if math.Abs(sol.GradientNorm) > 1e-8 {
+		t.Errorf("expected a small projected gradient, got %v", sol.GradientNorm)
+	}
+}
+
+func TestBoundedDifferencesFixedVariable(t *testing.T) {
+	// x1 is fixed at 1 by equal bounds
+	f := func(x linalg.Vector) float64 {
+		return (x[0]-3)*(x[0]-3) + x[0]*x[1] + (x[1]+1)*(x[1]+1)
+	}
+	bounds := optim.Bounds{Lower: linalg.Vector{math.Inf(-1), 1}, Upper: linalg.Vector{math.Inf(1), 1}}
+	sol := optim.NewLBFGSBSolver().Solve(f, linalg.Vector{0, 1}, optim.WithBounds(bounds),
+		optim.WithBoundedDifferences(nil), optim.WithTolerance(1e-8))
+	if !sol.ValidSolution || math.Abs(sol.Result[0]-2.5) > 1e-6 || sol.Result[1] != 1 {
+		t.Errorf("expected the minimum [2.5 1], got %+v", sol)
+	}
+	if math.IsNaN(sol.GradientNorm) {
+		t.Errorf("expected a finite gradient norm, got %v", sol.GradientNorm)
+	}
+}
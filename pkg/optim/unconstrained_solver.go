@@ -14,6 +14,38 @@ type solveOptions struct {
 	tolerance     float64
 	maxIterations int
 	gradientFunc  gradientFunc
+	bounds        *Bounds
+	projector     Projector
+	memory        int
+	innerSolver   Solver
+	kktSystem     KKTSystem
+	fixed         []bool
+	loss          Loss
+	rootMethod    RootMethod
+
+	boundedDifferences bool
+	typical            linalg.Vector
+	checkGradient      bool
+	checkTypical       linalg.Vector
+}
+
+// newSolveOptions returns the default options for a problem starting at x0
+// with the given options applied.
+func newSolveOptions(x0 linalg.Vector, options ...func(*solveOptions)) *solveOptions {
+	opts := &solveOptions{
+		tolerance:     1e-8,
+		maxIterations: 1000,
+		gradientFunc:  CentralGradientConstantStep(1e-4, x0.Len()),
+		memory:        10,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.boundedDifferences {
+		opts.gradientFunc = boundedGradient(opts.bounds, opts.typical)
+	}
+	return opts
 }
 
 func WithTolerance(tolerance float64) func(*solveOptions) {
@@ -34,6 +66,28 @@ func WithGradientFunc(gradientFunc gradientFunc) func(*solveOptions) {
 	}
 }
 
+// WithBounds sets lower and upper bounds on the variables for solvers that support them.
+func WithBounds(bounds Bounds) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.bounds = &bounds
+	}
+}
+
+// WithProjector sets the convex set for projection-based solvers.
+func WithProjector(projector Projector) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.projector = projector
+	}
+}
+
+// WithMemory sets the length of the history kept by solvers: the number of correction
+// pairs for L-BFGS-B and the nonmonotone line search window for SPG.
+func WithMemory(memory int) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.memory = memory
+	}
+}
+
 // solution is the result of an unconstrained optimization.
 type solution struct {
 	ValidSolution bool
@@ -41,6 +95,7 @@ type solution struct {
 	Iterations    int
 	GradientNorm  float64
 	Objective     float64
+	GradientCheck *gradientCheck // the failed check of WithGradientCheck, if any
 }
 
 type quasiNewtonSolver struct {
@@ -48,16 +103,10 @@ type quasiNewtonSolver struct {
 }
 
 func (s *quasiNewtonSolver) Solve(f ObjectiveFunc, x0 linalg.Vector, options ...func(*solveOptions)) *solution {
-	opts := &solveOptions{
-		tolerance:     1e-8,
-		maxIterations: 1000,
-		gradientFunc:  CentralGradientConstantStep(1e-4, x0.Len()),
-	}
-
-	for _, option := range options {
-		option(opts)
+	opts := newSolveOptions(x0, options...)
+	if check := failedGradientCheck(f, x0, opts); check != nil {
+		return &solution{Result: vectorCopy(x0), Objective: f(x0), GradientCheck: check}
 	}
-
 	return s.solve(f, x0, opts)
 }
 
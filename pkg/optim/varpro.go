@@ -0,0 +1,197 @@
+package optim
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var VARPRO_DERIVATIVE_STEP = 1e-6 // relative step for the differences of a basis without derivatives
+
+// BasisFunc writes the m x p basis matrix Phi(alpha) of a separable problem into Phi.
+type BasisFunc func(alpha linalg.Vector, Phi linalg.Matrix)
+
+// BasisDerivativeFunc writes the derivative of the basis matrix with respect to
+// alpha[k] into dPhi.
+type BasisDerivativeFunc func(alpha linalg.Vector, k int, dPhi linalg.Matrix)
+
+// SeparableProblem is the separable least-squares problem
+//
+//	min_{alpha, c} 1/2 * ||Y - Phi(alpha) * c||^2
+//
+// which is linear in the NumLinear parameters c and nonlinear in alpha. The derivative
+// of the basis is optional; a missing derivative is approximated by central differences.
+type SeparableProblem struct {
+	Basis      BasisFunc
+	Derivative BasisDerivativeFunc
+	Y          linalg.Vector
+	NumLinear  int
+}
+
+// varProSolution is the result of a separable least-squares problem. Covariance is the
+// estimated covariance of all the parameters, ordered as alpha followed by c, with the
+// residual variance s^2 = ||r||^2 / (m - n - p).
+type varProSolution struct {
+	ValidSolution bool
+	Nonlinear     linalg.Vector // alpha
+	Linear        linalg.Vector // c
+	Iterations    int
+	Evaluations   int
+	Objective     float64 // 1/2 * ||Y - Phi(alpha) * c||^2
+	Residuals     linalg.Vector
+	Covariance    linalg.Matrix
+}
+
+// varProSolver is the variable projection method of Golub and Pereyra (The
+// Differentiation of Pseudo-Inverses and Nonlinear Least Squares Problems Whose
+// Variables Separate, 1973). For fixed alpha the optimal linear parameters are
+// c(alpha) = Phi^+ * Y, computed by QR, which leaves the reduced problem
+//
+//	min_alpha 1/2 * ||P(alpha) * Y||^2,  P = I - Phi * Phi^+
+//
+// in the nonlinear parameters only. It is solved with the Levenberg-Marquardt method
+// using the exact Jacobian of the projected residual, whose column k is
+//
+//	-(P * dPhi_k * c + (Phi^+)^T * dPhi_k^T * r)
+//
+// Bounds set with WithBounds apply to alpha. Robust losses are not supported.
+type varProSolver struct{}
+
+func (s *varProSolver) Solve(problem *SeparableProblem, alpha0 linalg.Vector, options ...func(*solveOptions)) *varProSolution {
+	opts := newSolveOptions(alpha0, options...)
+	return s.solve(problem, alpha0, opts)
+}
+
+func (s *varProSolver) solve(problem *SeparableProblem, alpha0 linalg.Vector, opts *solveOptions) *varProSolution {
+	n := alpha0.Len()
+	m := problem.Y.Len()
+	p := problem.NumLinear
+	if m < n+p {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	Y := problem.Y
+	Phi := linalg.NewDenseMatrix(m, p)
+	dPhi := linalg.NewDenseMatrix(m, p)
+	c := linalg.NewVector(p)
+	fitted := linalg.NewVector(m)
+
+	// project evaluates the basis at alpha, solves for c and writes the residuals
+	// Y - Phi * c into r.
+	project := func(alpha, r linalg.Vector) linalg.QR {
+		problem.Basis(alpha, Phi)
+		qr := linalg.FactorQR(Phi)
+		qr.SolveLeastSquares(Y, c)
+		blas.GEMV(1, Phi, c, 0, fitted)
+		for i := 0; i < m; i++ {
+			r[i] = Y[i] - fitted[i]
+		}
+		return qr
+	}
+	derivative := s.basisDerivative(problem, m, p)
+
+	r := linalg.NewVector(m)
+	v := linalg.NewVector(m)
+	w := linalg.NewVector(p)
+	u := linalg.NewVector(p)
+	Pv := linalg.NewVector(p)
+	inverse := linalg.NewDenseMatrix(p, p)
+	reduced := &LeastSquaresProblem{
+		Residual: func(alpha, r linalg.Vector) {
+			project(alpha, r)
+		},
+		NumResiduals: m,
+		Jacobian: func(alpha linalg.Vector, J linalg.Matrix) {
+			qr := project(alpha, r)
+			qr.NormalInverse(inverse)
+			for k := 0; k < n; k++ {
+				derivative(alpha, k, dPhi)
+
+				// v = P * dPhi_k * c
+				blas.GEMV(1, dPhi, c, 0, v)
+				qr.SolveLeastSquares(v, Pv)
+				blas.GEMV(-1, Phi, Pv, 1, v)
+
+				// v += (Phi^+)^T * dPhi_k^T * r = Phi * (Phi^T * Phi)^-1 * dPhi_k^T * r
+				blas.GEMVT(1, dPhi, r, 0, w)
+				blas.GEMV(1, inverse, w, 0, u)
+				blas.GEMV(1, Phi, u, 1, v)
+				for i := 0; i < m; i++ {
+					J.Set(i, k, -v[i])
+				}
+			}
+		},
+	}
+	opts.loss = nil
+	ls := (&levenbergMarquardtSolver{}).solve(reduced, alpha0, opts)
+
+	sol := &varProSolution{
+		ValidSolution: ls.ValidSolution,
+		Nonlinear:     ls.Result,
+		Linear:        linalg.NewVector(p),
+		Iterations:    ls.Iterations,
+		Evaluations:   ls.Evaluations,
+		Residuals:     linalg.NewVector(m),
+		Covariance:    linalg.NewDenseMatrix(n+p, n+p),
+	}
+	project(sol.Nonlinear, sol.Residuals)
+	copy(sol.Linear, c)
+	nrm := blas.NRM2(sol.Residuals)
+	sol.Objective = 0.5 * nrm * nrm
+
+	// the covariance from the Jacobian [dPhi_k * c, Phi] of the full model
+	if m > n+p {
+		J := linalg.NewDenseMatrix(m, n+p)
+		for k := 0; k < n; k++ {
+			derivative(sol.Nonlinear, k, dPhi)
+			blas.GEMV(1, dPhi, sol.Linear, 0, v)
+			for i := 0; i < m; i++ {
+				J.Set(i, k, v[i])
+			}
+		}
+		for i := 0; i < m; i++ {
+			for j := 0; j < p; j++ {
+				J.Set(i, n+j, Phi.Get(i, j))
+			}
+		}
+		linalg.FactorQR(J).NormalInverse(sol.Covariance)
+		s2 := nrm * nrm / float64(m-n-p)
+		for i := 0; i < n+p; i++ {
+			for j := 0; j < n+p; j++ {
+				sol.Covariance.Set(i, j, s2*sol.Covariance.Get(i, j))
+			}
+		}
+	}
+	return sol
+}
+
+// basisDerivative returns the derivative of the basis, approximated by central
+// differences if the problem has none.
+func (s *varProSolver) basisDerivative(problem *SeparableProblem, m, p int) BasisDerivativeFunc {
+	if problem.Derivative != nil {
+		return problem.Derivative
+	}
+	plus := linalg.NewDenseMatrix(m, p)
+	minus := linalg.NewDenseMatrix(m, p)
+	var shifted linalg.Vector
+	return func(alpha linalg.Vector, k int, dPhi linalg.Matrix) {
+		if shifted.Len() != alpha.Len() {
+			shifted = linalg.NewVector(alpha.Len())
+		}
+		copy(shifted, alpha)
+		h := VARPRO_DERIVATIVE_STEP * math.Max(1, math.Abs(alpha[k]))
+		shifted[k] = alpha[k] + h
+		problem.Basis(shifted, plus)
+		shifted[k] = alpha[k] - h
+		problem.Basis(shifted, minus)
+		for i := 0; i < m; i++ {
+			for j := 0; j < p; j++ {
+				dPhi.Set(i, j, (plus.Get(i, j)-minus.Get(i, j))/(2*h))
+			}
+		}
+	}
+}
+
+func NewVariableProjectionSolver() *varProSolver {
+	return &varProSolver{}
+}
@@ -0,0 +1,74 @@
+package optim_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/optim"
+)
+
+// twoExponentials fits c1 * exp(-a1 * t) + c2 * exp(-a2 * t) to samples of
+// 3 * exp(-0.4 * t) + 1.5 * exp(-2 * t) with a deterministic perturbation.
+func twoExponentials(withDerivative bool) (*optim.SeparableProblem, linalg.Vector) {
+	ts := linalg.NewVector(30)
+	ys := linalg.NewVector(30)
+	for i := range ts {
+		ts[i] = 0.2 * float64(i)
+		ys[i] = 3*math.Exp(-0.4*ts[i]) + 1.5*math.Exp(-2*ts[i]) + 0.01*math.Cos(7*ts[i])
+	}
+	problem := &optim.SeparableProblem{
+		Basis: func(alpha linalg.Vector, Phi linalg.Matrix) {
+			for i, t := range ts {
+				Phi.Set(i, 0, math.Exp(-alpha[0]*t))
+				Phi.Set(i, 1, math.Exp(-alpha[1]*t))
+			}
+		},
+		Y:         ys,
+		NumLinear: 2,
+	}
+	if withDerivative {
+		problem.Derivative = func(alpha linalg.Vector, k int, dPhi linalg.Matrix) {
+			for i, t := range ts {
+				dPhi.Set(i, 0, 0)
+				dPhi.Set(i, 1, 0)
+				dPhi.Set(i, k, -t*math.Exp(-alpha[k]*t))
+			}
+		}
+	}
+	return problem, ts
+}
+
+func TestVariableProjection(t *testing.T) {
+	for _, withDerivative := range []bool{true, false} {
+		problem, ts := twoExponentials(withDerivative)
+		sol := optim.NewVariableProjectionSolver().Solve(problem, linalg.Vector{0.1, 1})
+		if !sol.ValidSolution {
+			t.Fatalf("expected a valid solution, got %+v", sol)
+		}
+		if math.Abs(sol.Nonlinear[0]-0.4) > 0.01 || math.Abs(sol.Nonlinear[1]-2) > 0.05 {
+			t.Errorf("expected rates [0.4 2], got %v", sol.Nonlinear)
+		}
+		if math.Abs(sol.Linear[0]-3) > 0.02 || math.Abs(sol.Linear[1]-1.5) > 0.02 {
+			t.Errorf("expected amplitudes [3 1.5], got %v", sol.Linear)
+		}
+
+		// the full problem fitted from the solution has the same optimum and covariance
+		model := func(t float64, p linalg.Vector) float64 {
+			return p[2]*math.Exp(-p[0]*t) + p[3]*math.Exp(-p[1]*t)
+		}
+		p0 := linalg.Vector{sol.Nonlinear[0], sol.Nonlinear[1], sol.Linear[0], sol.Linear[1]}
+		fit := optim.CurveFit(model, ts, problem.Y, nil, p0)
+		for i := range p0 {
+			if math.Abs(fit.Parameters[i]-p0[i]) > 1e-6 {
+				t.Errorf("expected the full fit to stay at %v, got %v", p0, fit.Parameters)
+			}
+			for j := range p0 {
+				expected := fit.Covariance.Get(i, j)
+				if math.Abs(sol.Covariance.Get(i, j)-expected) > 1e-3*math.Abs(expected)+1e-10 {
+					t.Errorf("covariance (%d, %d): expected %v, got %v", i, j, expected, sol.Covariance.Get(i, j))
+				}
+			}
+		}
+	}
+}
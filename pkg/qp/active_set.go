@@ -0,0 +1,97 @@
+package qp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/internal/quadprog"
+)
+
+// activeSetSolver is the dual active-set method of Goldfarb and Idnani. It starts from
+// the unconstrained minimizer and adds violated constraints one at a time while
+// keeping the iterates dual feasible, so Q must be positive definite.
+type activeSetSolver struct{}
+
+func (s *activeSetSolver) Solve(p *Problem, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	return s.solve(p, opts)
+}
+
+func (s *activeSetSolver) solve(p *Problem, opts *solveOptions) *solution {
+	A, E := p.constraints()
+	var warm []int
+	if opts.warmStart != nil {
+		warm = opts.warmStart.Active
+	}
+	tolerance := opts.tolerance
+	if tolerance == 0 {
+		tolerance = quadprog.TOLERANCE
+	}
+
+	res := quadprog.Solve(quadprog.Problem{
+		G:     p.Q,
+		C:     p.C,
+		Aeq:   E,
+		Beq:   p.D,
+		Aineq: A,
+		Bineq: p.B,
+	}, warm, opts.maxIterations, tolerance)
+
+	sol := &solution{
+		X:                     res.X,
+		Objective:             res.Objective,
+		InequalityMultipliers: res.InequalityMultipliers,
+		EqualityMultipliers:   res.EqualityMultipliers,
+		Active:                res.Active,
+		Iterations:            res.Iterations,
+	}
+	switch res.Status {
+	case quadprog.Optimal:
+		sol.Status = Optimal
+	case quadprog.Infeasible:
+		sol.Status = Infeasible
+		sol.InequalityRay = res.InequalityRay
+		sol.EqualityRay = res.EqualityRay
+	case quadprog.IterationLimit:
+		sol.Status = IterationLimit
+	case quadprog.NotConvex:
+		sol.Status = NotConvex
+		return sol
+	}
+	sol.PrimalResidual, sol.DualResidual = p.residuals(A, E, sol)
+	return sol
+}
+
+// residuals returns the largest constraint violation and the largest component of the
+// gradient of the Lagrangian.
+func (p *Problem) residuals(A, E linalg.Matrix, sol *solution) (primal, dual float64) {
+	n := p.C.Len()
+	Ax := linalg.NewVector(A.Rows())
+	Ex := linalg.NewVector(E.Rows())
+	blas.GEMV(1.0, A, sol.X, 0.0, Ax)
+	blas.GEMV(1.0, E, sol.X, 0.0, Ex)
+	for i := range Ax {
+		primal = math.Max(primal, Ax[i]-p.B[i])
+	}
+	for i := range Ex {
+		primal = math.Max(primal, math.Abs(Ex[i]-p.D[i]))
+	}
+
+	gradL := linalg.NewVector(n)
+	blas.COPY(p.C, gradL)
+	blas.GEMV(1.0, p.Q, sol.X, 1.0, gradL)
+	blas.GEMVT(1.0, A, sol.InequalityMultipliers, 1.0, gradL)
+	blas.GEMVT(1.0, E, sol.EqualityMultipliers, 1.0, gradL)
+	for i := range gradL {
+		dual = math.Max(dual, math.Abs(gradL[i]))
+	}
+	return primal, dual
+}
+
+// NewActiveSetSolver returns a dense dual active-set solver for strictly convex quadratic
+// programs. A strictly convex problem is never unbounded; a Q that is not positive
+// definite is reported as NotConvex.
+func NewActiveSetSolver() *activeSetSolver {
+	return &activeSetSolver{}
+}
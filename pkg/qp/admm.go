@@ -0,0 +1,273 @@
+package qp
+
+import (
+	"math"
+
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+var ADMM_RHO = 0.1                // initial step size of the dual update
+var ADMM_SIGMA = 1e-6             // primal regularization of the KKT system
+var ADMM_ALPHA = 1.6              // over-relaxation parameter in (0, 2)
+var ADMM_EQUALITY_SCALE = 1e3     // step size multiplier for equality rows
+var ADMM_ADAPTIVE_INTERVAL = 25   // iterations between step size updates
+var ADMM_ADAPTIVE_TOLERANCE = 5.0 // change in the step size that triggers a refactorization
+var ADMM_TOLERANCE = 1e-6         // default tolerance on the scaled residuals
+
+// admmSolver is the operator splitting method of OSQP. The constraints are written as
+// l <= K * x <= u with K = [A; E] and each iteration solves the quasi-definite system
+//
+//	[ Q + sigma*I   K^T      ] [ x ]   [ sigma*x_k - c       ]
+//	[ K             -rho^-1  ] [ v ] = [ z_k - rho^-1 * y_k  ]
+//
+// with a sparse LDL^T factorization that is reused until the step size rho changes.
+//
+// Reference: B. Stellato et al., "OSQP: an operator splitting solver for quadratic
+// programs", Mathematical Programming Computation 12, 2020.
+type admmSolver struct{}
+
+func (s *admmSolver) Solve(p *SparseProblem, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	return s.solve(p, opts)
+}
+
+func (s *admmSolver) solve(p *SparseProblem, opts *solveOptions) *solution {
+	A, E := p.constraints()
+	tolerance := opts.tolerance
+	if tolerance == 0 {
+		tolerance = ADMM_TOLERANCE
+	}
+	n := p.C.Len()
+	mA := A.Rows()
+	mE := E.Rows()
+	m := mA + mE
+
+	// stacked constraints l <= K * x <= u
+	t := linalg.NewTripletMatrix(m, n)
+	for j := 0; j < n; j++ {
+		rows, values := A.Column(j)
+		for k, i := range rows {
+			t.Append(i, j, values[k])
+		}
+		rows, values = E.Column(j)
+		for k, i := range rows {
+			t.Append(mA+i, j, values[k])
+		}
+	}
+	K := t.ToCSC()
+	l := linalg.NewVector(m)
+	u := linalg.NewVector(m)
+	for i := 0; i < mA; i++ {
+		l[i] = math.Inf(-1)
+		u[i] = p.B[i]
+	}
+	for i := 0; i < mE; i++ {
+		l[mA+i] = p.D[i]
+		u[mA+i] = p.D[i]
+	}
+
+	rho := ADMM_RHO
+	rhoVec := linalg.NewVector(m)
+	setRho := func() {
+		for i := range rhoVec {
+			if l[i] == u[i] {
+				rhoVec[i] = ADMM_EQUALITY_SCALE * rho
+			} else {
+				rhoVec[i] = rho
+			}
+		}
+	}
+	factor := func() (linalg.SparseLDL, error) {
+		kkt := linalg.NewTripletMatrix(n+m, n+m)
+		for j := 0; j < n; j++ {
+			rows, values := p.Q.Column(j)
+			for k, i := range rows {
+				if i <= j {
+					kkt.Append(i, j, values[k])
+				}
+			}
+			kkt.Append(j, j, ADMM_SIGMA)
+			rows, values = K.Column(j)
+			for k, i := range rows {
+				kkt.Append(j, n+i, values[k])
+			}
+		}
+		for i := 0; i < m; i++ {
+			kkt.Append(n+i, n+i, -1/rhoVec[i])
+		}
+		return linalg.FactorSparseLDL(kkt.ToCSC())
+	}
+
+	x := linalg.NewVector(n)
+	z := linalg.NewVector(m)
+	y := linalg.NewVector(m)
+	xPrev := linalg.NewVector(n)
+	yPrev := linalg.NewVector(m)
+	rhs := linalg.NewVector(n + m)
+	kktSol := linalg.NewVector(n + m)
+	Kx := linalg.NewVector(m)
+	Qx := linalg.NewVector(n)
+	Kty := linalg.NewVector(n)
+	dx := linalg.NewVector(n)
+	dy := linalg.NewVector(m)
+	Kdx := linalg.NewVector(m)
+	Qdx := linalg.NewVector(n)
+
+	if w := opts.warmStart; w != nil && w.X.Len() == n {
+		blas.COPY(w.X, x)
+		if w.InequalityMultipliers.Len() == mA && w.EqualityMultipliers.Len() == mE {
+			copy(y[:mA], w.InequalityMultipliers)
+			copy(y[mA:], w.EqualityMultipliers)
+		}
+		blas.SPMV(1.0, K, x, 0.0, z)
+		for i := range z {
+			z[i] = math.Min(math.Max(z[i], l[i]), u[i])
+		}
+	}
+
+	setRho()
+	ldl, err := factor()
+	if err != nil {
+		return &solution{Status: NotConvex, X: x}
+	}
+
+	sol := &solution{Status: IterationLimit}
+	cNorm := infNorm(p.C)
+	iter := 0
+	for iter < opts.maxIterations {
+		iter++
+		blas.COPY(x, xPrev)
+		blas.COPY(y, yPrev)
+
+		for j := 0; j < n; j++ {
+			rhs[j] = ADMM_SIGMA*x[j] - p.C[j]
+		}
+		for i := 0; i < m; i++ {
+			rhs[n+i] = z[i] - y[i]/rhoVec[i]
+		}
+		ldl.Solve(rhs, kktSol)
+
+		// relaxed updates of x, z and y
+		for j := 0; j < n; j++ {
+			x[j] = ADMM_ALPHA*kktSol[j] + (1-ADMM_ALPHA)*xPrev[j]
+		}
+		for i := 0; i < m; i++ {
+			zTilde := z[i] + (kktSol[n+i]-y[i])/rhoVec[i]
+			w := ADMM_ALPHA*zTilde + (1-ADMM_ALPHA)*z[i]
+			zNext := math.Min(math.Max(w+y[i]/rhoVec[i], l[i]), u[i])
+			y[i] += rhoVec[i] * (w - zNext)
+			z[i] = zNext
+		}
+
+		// residuals r_prim = K * x - z and r_dual = Q * x + c + K^T * y
+		blas.SPMV(1.0, K, x, 0.0, Kx)
+		symmetricProduct(p.Q, x, Qx)
+		blas.SPMVT(1.0, K, y, 0.0, Kty)
+		primal := 0.0
+		for i := 0; i < m; i++ {
+			primal = math.Max(primal, math.Abs(Kx[i]-z[i]))
+		}
+		dual := 0.0
+		for j := 0; j < n; j++ {
+			dual = math.Max(dual, math.Abs(Qx[j]+p.C[j]+Kty[j]))
+		}
+		primalScale := math.Max(infNorm(Kx), infNorm(z))
+		dualScale := math.Max(math.Max(infNorm(Qx), infNorm(Kty)), cNorm)
+		sol.PrimalResidual = primal
+		sol.DualResidual = dual
+		if primal <= tolerance*(1+primalScale) && dual <= tolerance*(1+dualScale) {
+			sol.Status = Optimal
+			break
+		}
+
+		// primal infeasibility: dy approaches a Farkas certificate
+		for i := 0; i < m; i++ {
+			dy[i] = y[i] - yPrev[i]
+			if i < mA {
+				dy[i] = math.Max(dy[i], 0)
+			}
+		}
+		if dyNorm := infNorm(dy); dyNorm > 0 {
+			blas.SPMVT(1.0, K, dy, 0.0, dx)
+			support := 0.0
+			for i := 0; i < m; i++ {
+				if dy[i] > 0 {
+					support += u[i] * dy[i]
+				} else if dy[i] < 0 {
+					support += l[i] * dy[i]
+				}
+			}
+			if infNorm(dx) <= tolerance*dyNorm && support < -tolerance*dyNorm {
+				sol.Status = Infeasible
+				sol.InequalityRay = linalg.NewVector(mA)
+				sol.EqualityRay = linalg.NewVector(mE)
+				blas.CPSC(1/dyNorm, dy[:mA], sol.InequalityRay)
+				blas.CPSC(1/dyNorm, dy[mA:], sol.EqualityRay)
+				break
+			}
+		}
+
+		// dual infeasibility: dx approaches a direction of unbounded descent
+		blas.COPY(x, dx)
+		blas.AXPY(-1.0, xPrev, dx)
+		if dxNorm := infNorm(dx); dxNorm > 0 {
+			eps := tolerance * dxNorm
+			symmetricProduct(p.Q, dx, Qdx)
+			blas.SPMV(1.0, K, dx, 0.0, Kdx)
+			unbounded := infNorm(Qdx) <= eps && blas.DOT(p.C, dx) < -eps
+			for i := 0; unbounded && i < m; i++ {
+				if (!math.IsInf(u[i], 1) && Kdx[i] > eps) || (!math.IsInf(l[i], -1) && Kdx[i] < -eps) {
+					unbounded = false
+				}
+			}
+			if unbounded {
+				sol.Status = Unbounded
+				sol.Direction = linalg.NewVector(n)
+				blas.CPSC(1/dxNorm, dx, sol.Direction)
+				break
+			}
+		}
+
+		// balance the primal and dual residuals by rescaling rho
+		if iter%ADMM_ADAPTIVE_INTERVAL == 0 && primal > 0 && dual > 0 {
+			ratio := (primal / math.Max(primalScale, 1e-10)) / (dual / math.Max(dualScale, 1e-10))
+			next := math.Min(math.Max(rho*math.Sqrt(ratio), 1e-6), 1e6)
+			if next > ADMM_ADAPTIVE_TOLERANCE*rho || next < rho/ADMM_ADAPTIVE_TOLERANCE {
+				rho = next
+				setRho()
+				if ldl, err = factor(); err != nil {
+					sol.Status = NotConvex
+					break
+				}
+			}
+		}
+	}
+
+	sol.X = x
+	sol.Iterations = iter
+	sol.InequalityMultipliers = y[:mA]
+	sol.EqualityMultipliers = y[mA:]
+	sol.Active = make([]int, 0, mA)
+	for i := 0; i < mA; i++ {
+		if y[i] > 0 {
+			sol.Active = append(sol.Active, i)
+		}
+	}
+	sol.Objective = p.Objective(x)
+	return sol
+}
+
+func infNorm(v linalg.Vector) float64 {
+	if v.Len() == 0 {
+		return 0
+	}
+	return math.Abs(v[blas.IAMAX(v)])
+}
+
+// NewADMMSolver returns an ADMM solver for large sparse convex quadratic programs. Q may
+// be positive semidefinite, including zero for linear programs. Solutions are accurate
+// to the tolerance, which is relative to the size of the residual terms.
+func NewADMMSolver() *admmSolver {
+	return &admmSolver{}
+}
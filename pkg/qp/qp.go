@@ -0,0 +1,214 @@
+// Package qp solves convex quadratic programs
+//
+//	minimize 1/2 * x^T * Q * x + c^T * x
+//	subject to A * x <= b, E * x = d
+//
+// with a dense dual active-set method for strictly convex problems and an ADMM
+// method for large sparse problems.
+package qp
+
+import (
+	"github.com/tab58/go-optimize/internal/blas"
+	"github.com/tab58/go-optimize/internal/linalg"
+)
+
+// Problem is a quadratic program with dense matrices. Q must be symmetric. Either
+// constraint block may be left empty.
+type Problem struct {
+	Q linalg.Matrix
+	C linalg.Vector
+	A linalg.Matrix
+	B linalg.Vector
+	E linalg.Matrix
+	D linalg.Vector
+}
+
+// SparseProblem is a quadratic program with sparse matrices. Only the upper triangle
+// of Q (entries with i <= j) is referenced. Either constraint block may be left empty.
+type SparseProblem struct {
+	Q linalg.SparseMatrix
+	C linalg.Vector
+	A linalg.SparseMatrix
+	B linalg.Vector
+	E linalg.SparseMatrix
+	D linalg.Vector
+}
+
+type Status int
+
+const (
+	Optimal Status = iota
+	Infeasible
+	Unbounded
+	IterationLimit
+	NotConvex
+)
+
+func (s Status) String() string {
+	switch s {
+	case Optimal:
+		return "optimal"
+	case Infeasible:
+		return "infeasible"
+	case Unbounded:
+		return "unbounded"
+	case IterationLimit:
+		return "iteration limit"
+	case NotConvex:
+		return "not convex"
+	}
+	return "unknown"
+}
+
+// solution is the result of a quadratic program.
+//
+// The multipliers are those of the Lagrangian
+//
+//	L(x, lambda, mu) = f(x) + mu^T * (A * x - b) + lambda^T * (E * x - d)
+//
+// with mu >= 0. If the problem is infeasible, the rays satisfy
+// A^T * yA + E^T * yE = 0, yA >= 0 and b^T * yA + d^T * yE < 0. If it is unbounded,
+// the direction satisfies Q * dx = 0, c^T * dx < 0, A * dx <= 0 and E * dx = 0.
+type solution struct {
+	Status                Status
+	X                     linalg.Vector
+	Objective             float64
+	InequalityMultipliers linalg.Vector
+	EqualityMultipliers   linalg.Vector
+	Active                []int
+	Iterations            int
+	PrimalResidual        float64
+	DualResidual          float64
+
+	InequalityRay linalg.Vector
+	EqualityRay   linalg.Vector
+	Direction     linalg.Vector
+}
+
+// solveOptions are the options for a quadratic program.
+type solveOptions struct {
+	tolerance     float64 // zero selects the default of each solver
+	maxIterations int
+	warmStart     *solution
+}
+
+func newSolveOptions(options ...func(*solveOptions)) *solveOptions {
+	opts := &solveOptions{
+		maxIterations: 4000,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithTolerance sets the relative constraint violation the active-set solver accepts,
+// 1e-10 by default, or the tolerance on the scaled residuals of ADMM, 1e-6 by default.
+func WithTolerance(tolerance float64) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.tolerance = tolerance
+	}
+}
+
+func WithMaxIterations(maxIterations int) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.maxIterations = maxIterations
+	}
+}
+
+// WithWarmStart starts the solver from a previous solution of a problem with the same
+// dimensions: its active set for the active-set solver, its primal and dual iterates
+// for ADMM.
+func WithWarmStart(previous *solution) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.warmStart = previous
+	}
+}
+
+// Objective returns 1/2 * x^T * Q * x + c^T * x.
+func (p *Problem) Objective(x linalg.Vector) float64 {
+	Qx := linalg.NewVector(x.Len())
+	blas.GEMV(1.0, p.Q, x, 0.0, Qx)
+	return 0.5*blas.DOT(x, Qx) + blas.DOT(p.C, x)
+}
+
+// Objective returns 1/2 * x^T * Q * x + c^T * x.
+func (p *SparseProblem) Objective(x linalg.Vector) float64 {
+	Qx := linalg.NewVector(x.Len())
+	symmetricProduct(p.Q, x, Qx)
+	return 0.5*blas.DOT(x, Qx) + blas.DOT(p.C, x)
+}
+
+// Sparse returns the problem with its matrices in compressed sparse column form.
+func (p *Problem) Sparse() *SparseProblem {
+	n := p.C.Len()
+	q := linalg.NewTripletMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			if v := p.Q.Get(i, j); v != 0 {
+				q.Append(i, j, v)
+			}
+		}
+	}
+	A, E := p.constraints()
+	return &SparseProblem{
+		Q: q.ToCSC(),
+		C: p.C,
+		A: linalg.NewSparseFromDense(A),
+		B: p.B,
+		E: linalg.NewSparseFromDense(E),
+		D: p.D,
+	}
+}
+
+// constraints returns the constraint matrices with empty blocks sized to the problem.
+func (p *Problem) constraints() (A, E linalg.Matrix) {
+	n := p.C.Len()
+	A, E = p.A, p.E
+	if A.Rows() == 0 {
+		A = linalg.NewDenseMatrix(0, n)
+	}
+	if E.Rows() == 0 {
+		E = linalg.NewDenseMatrix(0, n)
+	}
+	if p.Q.Rows() != n || p.Q.Cols() != n || A.Cols() != n || E.Cols() != n ||
+		A.Rows() != p.B.Len() || E.Rows() != p.D.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	return A, E
+}
+
+// symmetricProduct computes y = Q * x where only the upper triangle of Q is stored.
+func symmetricProduct(Q linalg.SparseMatrix, x, y linalg.Vector) {
+	y.Zero()
+	for j := 0; j < Q.Cols(); j++ {
+		rows, values := Q.Column(j)
+		for k, i := range rows {
+			if i > j {
+				continue
+			}
+			y[i] += values[k] * x[j]
+			if i != j {
+				y[j] += values[k] * x[i]
+			}
+		}
+	}
+}
+
+// constraints returns the constraint matrices with empty blocks sized to the problem.
+func (p *SparseProblem) constraints() (A, E linalg.SparseMatrix) {
+	n := p.C.Len()
+	A, E = p.A, p.E
+	if A.Rows() == 0 {
+		A = linalg.NewTripletMatrix(0, n).ToCSC()
+	}
+	if E.Rows() == 0 {
+		E = linalg.NewTripletMatrix(0, n).ToCSC()
+	}
+	if p.Q.Rows() != n || p.Q.Cols() != n || A.Cols() != n || E.Cols() != n ||
+		A.Rows() != p.B.Len() || E.Rows() != p.D.Len() {
+		panic(linalg.ErrDimensionMismatch)
+	}
+	return A, E
+}
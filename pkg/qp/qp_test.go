@@ -0,0 +1,181 @@
+package qp_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/internal/linalg"
+	"github.com/tab58/go-optimize/pkg/qp"
+)
+
+func denseMatrix(rows [][]float64) linalg.Matrix {
+	cols := 0
+	if len(rows) > 0 {
+		cols = len(rows[0])
+	}
+	M := linalg.NewDenseMatrix(len(rows), cols)
+	for i := range rows {
+		for j := range rows[i] {
+			M.Set(i, j, rows[i][j])
+		}
+	}
+	return M
+}
+
+// smallProblem is the example from the OSQP documentation with the solution (0.3, 0.7).
+func smallProblem() *qp.Problem {
+	return &qp.Problem{
+		Q: denseMatrix([][]float64{{4, 1}, {1, 2}}),
+		C: linalg.Vector{1, 1},
+		A: denseMatrix([][]float64{{-1, 0}, {0, -1}, {1, 0}, {0, 1}}),
+		B: linalg.Vector{0, 0, 0.7, 0.7},
+		E: denseMatrix([][]float64{{1, 1}}),
+		D: linalg.Vector{1},
+	}
+}
+
+func TestSmallQP(t *testing.T) {
+	problem := smallProblem()
+	expected := linalg.Vector{0.3, 0.7}
+
+	dense := qp.NewActiveSetSolver().Solve(problem)
+	if dense.Status != qp.Optimal {
+		t.Fatalf("active set: expected optimal, got %v", dense.Status)
+	}
+	sparse := qp.NewADMMSolver().Solve(problem.Sparse(), qp.WithTolerance(1e-8))
+	if sparse.Status != qp.Optimal {
+		t.Fatalf("ADMM: expected optimal, got %v", sparse.Status)
+	}
+	for i := range expected {
+		if math.Abs(dense.X[i]-expected[i]) > 1e-10 {
+			t.Errorf("active set: expected %v, got %v", expected, dense.X)
+		}
+		if math.Abs(sparse.X[i]-expected[i]) > 1e-6 {
+			t.Errorf("ADMM: expected %v, got %v", expected, sparse.X)
+		}
+	}
+	if math.Abs(dense.EqualityMultipliers[0]-sparse.EqualityMultipliers[0]) > 1e-5 {
+		t.Errorf("equality multipliers differ: %v and %v", dense.EqualityMultipliers, sparse.EqualityMultipliers)
+	}
+	if len(dense.Active) != 1 || dense.Active[0] != 3 {
+		t.Errorf("expected the bound x2 <= 0.7 to be active, got %v", dense.Active)
+	}
+}
+
+func TestInfeasibleQP(t *testing.T) {
+	// x1 <= -1 and x1 >= 1
+	problem := &qp.Problem{
+		Q: denseMatrix([][]float64{{1, 0}, {0, 1}}),
+		C: linalg.Vector{0, 0},
+		A: denseMatrix([][]float64{{1, 0}, {-1, 0}}),
+		B: linalg.Vector{-1, -1},
+	}
+	check := func(name string, status qp.Status, ray linalg.Vector) {
+		if status != qp.Infeasible {
+			t.Fatalf("%s: expected infeasible, got %v", name, status)
+		}
+		// A^T * y = 0, y >= 0 and b^T * y < 0
+		if ray[0] < 0 || ray[1] < 0 || math.Abs(ray[0]-ray[1]) > 1e-4 || -ray[0]-ray[1] >= 0 {
+			t.Errorf("%s: invalid certificate %v", name, ray)
+		}
+	}
+	dense := qp.NewActiveSetSolver().Solve(problem)
+	check("active set", dense.Status, dense.InequalityRay)
+	sparse := qp.NewADMMSolver().Solve(problem.Sparse())
+	check("ADMM", sparse.Status, sparse.InequalityRay)
+}
+
+func TestUnboundedQP(t *testing.T) {
+	// minimize 1/2 * x2^2 - x1 subject to x2 <= 1
+	problem := &qp.Problem{
+		Q: denseMatrix([][]float64{{0, 0}, {0, 1}}),
+		C: linalg.Vector{-1, 0},
+		A: denseMatrix([][]float64{{0, 1}}),
+		B: linalg.Vector{1},
+	}
+	sol := qp.NewADMMSolver().Solve(problem.Sparse())
+	if sol.Status != qp.Unbounded {
+		t.Fatalf("expected unbounded, got %v", sol.Status)
+	}
+	if sol.Direction[0] <= 0 || math.Abs(sol.Direction[1]) > 1e-4 {
+		t.Errorf("invalid direction %v", sol.Direction)
+	}
+
+	if dense := qp.NewActiveSetSolver().Solve(problem); dense.Status != qp.NotConvex {
+		t.Errorf("active set: expected not convex, got %v", dense.Status)
+	}
+}
+
+// boxLeastSquares returns the problem of minimizing |x - target|^2 / 2 over a chain of
+// constraints x_i - x_(i+1) <= 0.1 with sum(x) = 0.
+func boxLeastSquares(n int, shift float64) *qp.Problem {
+	Q := linalg.NewDenseMatrix(n, n)
+	A := linalg.NewDenseMatrix(n-1, n)
+	E := linalg.NewDenseMatrix(1, n)
+	c := linalg.NewVector(n)
+	b := linalg.NewVector(n - 1)
+	for i := 0; i < n; i++ {
+		Q.Set(i, i, 1)
+		c[i] = -math.Sin(float64(i)/3) - shift
+		E.Set(0, i, 1)
+		if i < n-1 {
+			A.Set(i, i, 1)
+			A.Set(i, i+1, -1)
+			b[i] = 0.1
+		}
+	}
+	return &qp.Problem{Q: Q, C: c, A: A, B: b, E: E, D: linalg.Vector{0}}
+}
+
+func TestWarmStartQP(t *testing.T) {
+	problem := boxLeastSquares(60, 0)
+	perturbed := boxLeastSquares(60, 0.01)
+
+	activeSet := qp.NewActiveSetSolver()
+	first := activeSet.Solve(problem)
+	cold := activeSet.Solve(perturbed)
+	warm := activeSet.Solve(perturbed, qp.WithWarmStart(first))
+	if first.Status != qp.Optimal || cold.Status != qp.Optimal || warm.Status != qp.Optimal {
+		t.Fatalf("active set: expected optimal, got %v, %v and %v", first.Status, cold.Status, warm.Status)
+	}
+	if math.Abs(cold.Objective-warm.Objective) > 1e-10 {
+		t.Errorf("active set: warm objective %v differs from cold %v", warm.Objective, cold.Objective)
+	}
+	if warm.Iterations > cold.Iterations {
+		t.Errorf("active set: warm start took %d iterations, cold start %d", warm.Iterations, cold.Iterations)
+	}
+
+	admm := qp.NewADMMSolver()
+	sparseFirst := admm.Solve(problem.Sparse())
+	sparseCold := admm.Solve(perturbed.Sparse())
+	sparseWarm := admm.Solve(perturbed.Sparse(), qp.WithWarmStart(sparseFirst))
+	if sparseCold.Status != qp.Optimal || sparseWarm.Status != qp.Optimal {
+		t.Fatalf("ADMM: expected optimal, got %v and %v", sparseCold.Status, sparseWarm.Status)
+	}
+	if math.Abs(sparseWarm.Objective-cold.Objective) > 1e-4 {
+		t.Errorf("ADMM: objective %v differs from active set %v", sparseWarm.Objective, cold.Objective)
+	}
+	if sparseWarm.Iterations >= sparseCold.Iterations {
+		t.Errorf("ADMM: warm start took %d iterations, cold start %d", sparseWarm.Iterations, sparseCold.Iterations)
+	}
+}
+
+func TestActiveSetTolerance(t *testing.T) {
+	// minimize (x - 1)^2 subject to x <= 1 - 1e-5
+	problem := &qp.Problem{
+		Q: denseMatrix([][]float64{{2}}),
+		C: linalg.Vector{-2},
+		A: denseMatrix([][]float64{{1}}),
+		B: linalg.Vector{1 - 1e-5},
+	}
+	exact := qp.NewActiveSetSolver().Solve(problem)
+	if exact.Status != qp.Optimal || math.Abs(exact.X[0]-(1-1e-5)) > 1e-12 || len(exact.Active) != 1 {
+		t.Errorf("expected x = 1 - 1e-5 on the constraint, got %+v", exact)
+	}
+
+	// a violation of 1e-5 is within a tolerance of 1e-3
+	loose := qp.NewActiveSetSolver().Solve(problem, qp.WithTolerance(1e-3))
+	if loose.Status != qp.Optimal || math.Abs(loose.X[0]-1) > 1e-12 || len(loose.Active) != 0 {
+		t.Errorf("expected the unconstrained minimum x = 1, got %+v", loose)
+	}
+}
@@ -0,0 +1,148 @@
+package scalar
+
+import (
+	"math"
+)
+
+const goldenSection = 0.3819660112501051 // (3 - sqrt(5)) / 2
+
+// GoldenSection finds a local minimum of f in [a, b] by golden-section search, which
+// shrinks the interval by the golden ratio with one evaluation per iteration.
+func GoldenSection(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	if a > b {
+		a, b = b, a
+	}
+	x1 := a + goldenSection*(b-a)
+	x2 := b - goldenSection*(b-a)
+	f1, f2 := c.eval(x1), c.eval(x2)
+	for k := 0; k < opts.maxIterations; k++ {
+		if b-a <= 2*opts.minTolerance(0.5*(a+b)) {
+			return minimumSolution(Converged, a, b, x1, f1, x2, f2, k, c)
+		}
+		if f1 <= f2 {
+			b, x2, f2 = x2, x1, f1
+			x1 = a + goldenSection*(b-a)
+			f1 = c.eval(x1)
+		} else {
+			a, x1, f1 = x1, x2, f2
+			x2 = b - goldenSection*(b-a)
+			f2 = c.eval(x2)
+		}
+	}
+	status := IterationLimit
+	if b-a <= 2*opts.minTolerance(0.5*(a+b)) {
+		status = Converged
+	}
+	return minimumSolution(status, a, b, x1, f1, x2, f2, opts.maxIterations, c)
+}
+
+// minimumSolution returns the lower of the points x1 and x2 within [a, b].
+func minimumSolution(status Status, a, b, x1, f1, x2, f2 float64, iterations int, c *counter) *solution {
+	sol := &solution{
+		Status:      status,
+		X:           x1,
+		Value:       f1,
+		Lower:       a,
+		Upper:       b,
+		Width:       b - a,
+		Iterations:  iterations,
+		Evaluations: c.evaluations,
+	}
+	if f2 < f1 {
+		sol.X, sol.Value = x2, f2
+	}
+	return sol
+}
+
+// BrentMinimize finds a local minimum of f in [a, b] with Brent's method (Brent,
+// Algorithms for Minimization without Derivatives, 1973, chapter 5), which takes
+// parabolic interpolation steps through the three best points when they are well behaved
+// and golden-section steps otherwise.
+func BrentMinimize(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	if a > b {
+		a, b = b, a
+	}
+	// x is the best point, w the second best and v the previous w
+	x := a + goldenSection*(b-a)
+	w, v := x, x
+	fx := c.eval(x)
+	fw, fv := fx, fx
+	d, e := 0.0, 0.0
+	for k := 0; k < opts.maxIterations; k++ {
+		m := 0.5 * (a + b)
+		tol := opts.minTolerance(x)
+		if math.Abs(x-m) <= 2*tol-0.5*(b-a) {
+			return minimumSolution(Converged, a, b, x, fx, x, fx, k, c)
+		}
+
+		golden := true
+		if math.Abs(e) > tol {
+			// the parabola through x, w and v
+			r := (x - w) * (fx - fv)
+			q := (x - v) * (fx - fw)
+			p := (x-v)*q - (x-w)*r
+			q = 2 * (q - r)
+			if q > 0 {
+				p = -p
+			} else {
+				q = -q
+			}
+			if math.Abs(p) < math.Abs(0.5*q*e) && p > q*(a-x) && p < q*(b-x) {
+				e = d
+				d = p / q
+				u := x + d
+				// not too close to the ends
+				if u-a < 2*tol || b-u < 2*tol {
+					d = math.Copysign(tol, m-x)
+				}
+				golden = false
+			}
+		}
+		if golden {
+			if x < m {
+				e = b - x
+			} else {
+				e = a - x
+			}
+			d = goldenSection * e
+		}
+
+		u := x + d
+		if math.Abs(d) < tol {
+			u = x + math.Copysign(tol, d)
+		}
+		fu := c.eval(u)
+		if fu <= fx {
+			if u < x {
+				b = x
+			} else {
+				a = x
+			}
+			v, fv = w, fw
+			w, fw = x, fx
+			x, fx = u, fu
+		} else {
+			if u < x {
+				a = u
+			} else {
+				b = u
+			}
+			switch {
+			case fu <= fw || w == x:
+				v, fv = w, fw
+				w, fw = u, fu
+			case fu <= fv || v == x || v == w:
+				v, fv = u, fu
+			}
+		}
+	}
+	status := IterationLimit
+	if math.Abs(x-0.5*(a+b)) <= 2*opts.minTolerance(x)-0.5*(b-a) {
+		status = Converged
+	}
+	return minimumSolution(status, a, b, x, fx, x, fx, opts.maxIterations, c)
+}
@@ -0,0 +1,68 @@
+package scalar_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/pkg/scalar"
+)
+
+func TestMinimizers(t *testing.T) {
+	functions := []struct {
+		name    string
+		f       scalar.Func
+		a, b    float64
+		minimum float64
+	}{
+		{"quadratic", func(x float64) float64 { return (x - 1.5) * (x - 1.5) }, -3, 4, 1.5},
+		{"quartic", func(x float64) float64 { return x*x*x*x - 3*x + 1 }, -2, 2, math.Cbrt(0.75)},
+		{"cosine", math.Cos, 2, 4, math.Pi},
+		{"kink", func(x float64) float64 { return math.Abs(x - 0.3) }, -1, 1, 0.3},
+	}
+	for _, fn := range functions {
+		golden := scalar.GoldenSection(fn.f, fn.a, fn.b)
+		brent := scalar.BrentMinimize(fn.f, fn.a, fn.b)
+		for name, s := range map[string]struct {
+			status      scalar.Status
+			x, width    float64
+			lower       float64
+			upper       float64
+			evaluations int
+		}{
+			"golden section": {golden.Status, golden.X, golden.Width, golden.Lower, golden.Upper, golden.Evaluations},
+			"brent":          {brent.Status, brent.X, brent.Width, brent.Lower, brent.Upper, brent.Evaluations},
+		} {
+			if s.status != scalar.Converged {
+				t.Errorf("%s on %s: expected convergence, got %v", name, fn.name, s.status)
+			}
+			if math.Abs(s.x-fn.minimum) > 1e-7 {
+				t.Errorf("%s on %s: expected %v, got %v", name, fn.name, fn.minimum, s.x)
+			}
+			if s.lower > s.x || s.x > s.upper || s.width != s.upper-s.lower || s.width > 1e-6 {
+				t.Errorf("%s on %s: expected a small bracket around %v, got [%v, %v]", name, fn.name, s.x, s.lower, s.upper)
+			}
+		}
+		if fn.name != "kink" && brent.Evaluations >= golden.Evaluations {
+			t.Errorf("brent on %s: expected fewer evaluations than golden section, got %d and %d", fn.name, brent.Evaluations, golden.Evaluations)
+		}
+	}
+}
+
+func TestBracketMinimum(t *testing.T) {
+	f := func(x float64) float64 { return (x - 20) * (x - 20) * (1 + 0.01*math.Sin(x)) }
+	for _, start := range [][2]float64{{0, 1}, {1, 0}, {50, 49}} {
+		br := scalar.BracketMinimum(f, start[0], start[1])
+		if !br.Found || br.Lower >= br.Middle || br.Middle >= br.Upper ||
+			f(br.Middle) > f(br.Lower) || f(br.Middle) > f(br.Upper) {
+			t.Fatalf("start %v: expected a bracket of the minimum, got %+v", start, br)
+		}
+		s := scalar.BrentMinimize(f, br.Lower, br.Upper)
+		if math.Abs(s.X-20) > 1e-6 {
+			t.Errorf("start %v: expected 20, got %v", start, s.X)
+		}
+	}
+	br := scalar.BracketMinimum(func(x float64) float64 { return -x }, 0, 1, scalar.WithMaxIterations(10))
+	if br.Found {
+		t.Errorf("expected no bracket for an unbounded function, got %+v", br)
+	}
+}
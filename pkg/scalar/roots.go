@@ -0,0 +1,329 @@
+package scalar
+
+import (
+	"math"
+)
+
+// Bisection finds a root of f in [a, b] by halving the bracket.
+func Bisection(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	fa, fb := c.eval(a), c.eval(b)
+	if !changesSign(fa, fb) {
+		return newSolution(NotBracketed, a, fa, b, fb, 0, c)
+	}
+	for k := 0; k < opts.maxIterations; k++ {
+		if fa == 0 || fb == 0 || math.Abs(b-a) <= 2*opts.xTolerance(0.5*(a+b)) {
+			return newSolution(Converged, a, fa, b, fb, k, c)
+		}
+		m := 0.5 * (a + b)
+		fm := c.eval(m)
+		if changesSign(fa, fm) {
+			b, fb = m, fm
+		} else {
+			a, fa = m, fm
+		}
+	}
+	return bracketSolution(a, fa, b, fb, opts, c)
+}
+
+// bracketSolution is the solution after the iteration limit, which may still have
+// reached the tolerance on the last step.
+func bracketSolution(a, fa, b, fb float64, opts *solveOptions, c *counter) *solution {
+	status := IterationLimit
+	if fa == 0 || fb == 0 || math.Abs(b-a) <= 2*opts.xTolerance(0.5*(a+b)) {
+		status = Converged
+	}
+	return newSolution(status, a, fa, b, fb, opts.maxIterations, c)
+}
+
+// Brent finds a root of f in [a, b] with the method of Brent and Dekker (Brent,
+// Algorithms for Minimization without Derivatives, 1973, chapter 4), which combines
+// inverse quadratic interpolation and secant steps with bisection. It converges
+// superlinearly for smooth functions and never takes more than about twice the
+// evaluations of bisection.
+func Brent(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	cnt := &counter{f: f}
+	fa, fb := cnt.eval(a), cnt.eval(b)
+	if !changesSign(fa, fb) {
+		return newSolution(NotBracketed, a, fa, b, fb, 0, cnt)
+	}
+
+	// b is the best estimate, c the previous b or the other end of the bracket [b, c]
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for k := 0; k < opts.maxIterations; k++ {
+		if !changesSign(fb, fc) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol := opts.xTolerance(b)
+		m := 0.5 * (c - b)
+		if math.Abs(m) <= tol || fb == 0 {
+			return newSolution(Converged, b, fb, c, fc, k, cnt)
+		}
+
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			// interpolate: secant if a == c, inverse quadratic otherwise
+			s := fb / fa
+			var p, q float64
+			if a == c {
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = m
+				e = m
+			}
+		} else {
+			d = m
+			e = m
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else {
+			b += math.Copysign(tol, m)
+		}
+		fb = cnt.eval(b)
+	}
+	return newSolution(IterationLimit, b, fb, c, fc, opts.maxIterations, cnt)
+}
+
+// Ridders finds a root of f in [a, b] with Ridders' method (A New Algorithm for
+// Computing a Single Root of a Real Continuous Function, 1979), which fits an
+// exponential through the ends and the midpoint of the bracket. Each iteration costs two
+// evaluations and at least halves the bracket.
+func Ridders(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	fa, fb := c.eval(a), c.eval(b)
+	if !changesSign(fa, fb) {
+		return newSolution(NotBracketed, a, fa, b, fb, 0, c)
+	}
+	for k := 0; k < opts.maxIterations; k++ {
+		if fa == 0 || fb == 0 || math.Abs(b-a) <= 2*opts.xTolerance(0.5*(a+b)) {
+			return newSolution(Converged, a, fa, b, fb, k, c)
+		}
+		m := 0.5 * (a + b)
+		fm := c.eval(m)
+		s := math.Sqrt(fm*fm - fa*fb)
+		if s == 0 {
+			return newSolution(Converged, m, fm, m, fm, k, c)
+		}
+		sign := 1.0
+		if fa < fb {
+			sign = -1
+		}
+		x := m + (m-a)*sign*fm/s
+		fx := c.eval(x)
+
+		// the smallest bracket among a, m, x, b
+		switch {
+		case changesSign(fm, fx):
+			a, fa, b, fb = m, fm, x, fx
+		case changesSign(fa, fx):
+			b, fb = x, fx
+		default:
+			a, fa = x, fx
+		}
+		if a > b {
+			a, fa, b, fb = b, fb, a, fa
+		}
+	}
+	return bracketSolution(a, fa, b, fb, opts, c)
+}
+
+var ITP_K1 = 0.2 // truncation scale relative to 1 / (b - a)
+var ITP_K2 = 2.0 // truncation exponent, in [1, 1 + golden ratio)
+var ITP_N0 = 1   // iterations allowed beyond those of bisection
+
+// ITP finds a root of f in [a, b] with the interpolate-truncate-project method of
+// Oliveira and Takahashi (An Enhancement of the Bisection Method Average Performance
+// Preserving Minmax Optimality, 2020). It converges superlinearly for smooth functions
+// while never taking more than ITP_N0 iterations beyond bisection.
+func ITP(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	if a > b {
+		a, b = b, a
+	}
+	fa, fb := c.eval(a), c.eval(b)
+	if !changesSign(fa, fb) {
+		return newSolution(NotBracketed, a, fa, b, fb, 0, c)
+	}
+	// the method assumes f(a) < 0 < f(b); sign flips f otherwise
+	sign := 1.0
+	if fa > fb {
+		sign = -1
+	}
+	eps := opts.xTolerance(math.Max(math.Abs(a), math.Abs(b)))
+	nHalf := math.Max(0, math.Ceil(math.Log2((b-a)/(2*eps))))
+	nMax := nHalf + float64(ITP_N0)
+	k1 := ITP_K1 / (b - a)
+	for j := 0; j < opts.maxIterations; j++ {
+		if fa == 0 || fb == 0 || b-a <= 2*eps {
+			return newSolution(Converged, a, fa, b, fb, j, c)
+		}
+		// interpolation by regula falsi, truncated toward the midpoint
+		mid := 0.5 * (a + b)
+		r := eps*math.Pow(2, nMax-float64(j)) - 0.5*(b-a)
+		delta := k1 * math.Pow(b-a, ITP_K2)
+		xf := (fb*a - fa*b) / (fb - fa)
+		sigma := math.Copysign(1, mid-xf)
+		xt := mid
+		if delta <= math.Abs(mid-xf) {
+			xt = xf + sigma*delta
+		}
+		// projection onto the minmax interval around the midpoint
+		x := mid - sigma*r
+		if math.Abs(xt-mid) <= r {
+			x = xt
+		}
+
+		fx := c.eval(x)
+		switch {
+		case sign*fx > 0:
+			b, fb = x, fx
+		case sign*fx < 0:
+			a, fa = x, fx
+		default:
+			return newSolution(Converged, x, fx, x, fx, j+1, c)
+		}
+	}
+	return bracketSolution(a, fa, b, fb, opts, c)
+}
+
+// Newton finds a root of f in [a, b] with Newton's method safeguarded by bisection: a
+// step that leaves the bracket or does not halve the previous step is replaced by
+// bisection (Press et al., Numerical Recipes, 9.4).
+func Newton(f, df Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	fa, fb := c.eval(a), c.eval(b)
+	if !changesSign(fa, fb) {
+		return newSolution(NotBracketed, a, fa, b, fb, 0, c)
+	}
+	// keep f(lo) < 0 < f(hi)
+	lo, hi := a, b
+	flo, fhi := fa, fb
+	if fa > 0 {
+		lo, hi, flo, fhi = b, a, fb, fa
+	}
+	x := 0.5 * (a + b)
+	fx := c.eval(x)
+	dx, dxOld := math.Abs(b-a), math.Abs(b-a)
+	for k := 0; k < opts.maxIterations; k++ {
+		if fx == 0 {
+			return newSolution(Converged, x, fx, x, fx, k, c)
+		}
+		if fx < 0 {
+			lo, flo = x, fx
+		} else {
+			hi, fhi = x, fx
+		}
+		if flo == 0 || fhi == 0 || math.Abs(hi-lo) <= 2*opts.xTolerance(x) {
+			return newSolution(Converged, lo, flo, hi, fhi, k, c)
+		}
+
+		d := df(x)
+		step := 0.0
+		if d != 0 {
+			step = fx / d
+		}
+		next := x - step
+		if d == 0 || (next-lo)*(next-hi) >= 0 || math.Abs(2*step) > dxOld {
+			dxOld = dx
+			next = 0.5 * (lo + hi)
+		} else {
+			dxOld = dx
+		}
+		dx = math.Abs(next - x)
+		if dx <= opts.xTolerance(x) {
+			// the step is below the tolerance: the root lies within it
+			fnext := c.eval(next)
+			if fnext < 0 {
+				lo, flo = next, fnext
+			} else {
+				hi, fhi = next, fnext
+			}
+			return newSolution(Converged, lo, flo, hi, fhi, k+1, c)
+		}
+		x = next
+		fx = c.eval(x)
+	}
+	return bracketSolution(lo, flo, hi, fhi, opts, c)
+}
+
+// Secant finds a root of f in [a, b] with the secant method through the two latest
+// points, safeguarded like Newton: a secant step outside the bracket or one that does
+// not halve the previous step is replaced by bisection.
+func Secant(f Func, a, b float64, options ...func(*solveOptions)) *solution {
+	opts := newSolveOptions(options...)
+	c := &counter{f: f}
+	fa, fb := c.eval(a), c.eval(b)
+	if !changesSign(fa, fb) {
+		return newSolution(NotBracketed, a, fa, b, fb, 0, c)
+	}
+	lo, hi := a, b
+	flo, fhi := fa, fb
+	if fa > 0 {
+		lo, hi, flo, fhi = b, a, fb, fa
+	}
+	// x0 and x1 are the two latest points, starting from the bracket
+	x0, f0 := a, fa
+	x1, f1 := b, fb
+	if math.Abs(fa) < math.Abs(fb) {
+		x0, f0, x1, f1 = b, fb, a, fa
+	}
+	dxOld := math.Abs(b - a)
+	for k := 0; k < opts.maxIterations; k++ {
+		if flo == 0 || fhi == 0 || math.Abs(hi-lo) <= 2*opts.xTolerance(x1) {
+			return newSolution(Converged, lo, flo, hi, fhi, k, c)
+		}
+		next := 0.5 * (lo + hi)
+		if f1 != f0 {
+			s := x1 - f1*(x1-x0)/(f1-f0)
+			if (s-lo)*(s-hi) < 0 && 2*math.Abs(s-x1) <= dxOld {
+				next = s
+			}
+		}
+		dxOld = math.Abs(next - x1)
+		// a step below the tolerance is pushed to the tolerance so the bracket shrinks
+		if tol := opts.xTolerance(x1); dxOld < tol {
+			next = x1 + math.Copysign(tol, next-x1)
+			if (next-lo)*(next-hi) >= 0 {
+				next = 0.5 * (lo + hi)
+			}
+		}
+		fnext := c.eval(next)
+		x0, f0, x1, f1 = x1, f1, next, fnext
+		if fnext < 0 {
+			lo, flo = next, fnext
+		} else {
+			hi, fhi = next, fnext
+		}
+	}
+	return bracketSolution(lo, flo, hi, fhi, opts, c)
+}
@@ -0,0 +1,94 @@
+package scalar_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tab58/go-optimize/pkg/scalar"
+)
+
+func TestRootFinders(t *testing.T) {
+	functions := []struct {
+		name string
+		f    scalar.Func
+		df   scalar.Func
+		a, b float64
+		root float64
+		tol  float64
+	}{
+		{"cubic", func(x float64) float64 { return x*x*x - 2*x - 5 }, func(x float64) float64 { return 3*x*x - 2 }, 2, 3, 2.0945514815423265, 1e-11},
+		{"cosine", func(x float64) float64 { return math.Cos(x) - x }, func(x float64) float64 { return -math.Sin(x) - 1 }, 0, 1, 0.7390851332151607, 1e-11},
+		{"exponential", func(x float64) float64 { return math.Exp(x) - 10 }, math.Exp, -5, 5, math.Log(10), 1e-11},
+		{"decreasing", func(x float64) float64 { return 1/x - 4 }, func(x float64) float64 { return -1 / (x * x) }, 0.1, 1, 0.25, 1e-11},
+		// f is below the resolution of float64 near the root, so only the bracket is exact
+		{"flat", func(x float64) float64 { return math.Pow(x-1, 5) }, func(x float64) float64 { return 5 * math.Pow(x-1, 4) }, 0, 3, 1, 1e-2},
+	}
+	for _, fn := range functions {
+		bisection := 0
+		check := func(method string, status scalar.Status, x, lower, upper, width float64, evaluations int) {
+			if status != scalar.Converged {
+				t.Errorf("%s on %s: expected convergence, got %v", method, fn.name, status)
+			}
+			if math.Abs(x-fn.root) > fn.tol {
+				t.Errorf("%s on %s: expected %v, got %v", method, fn.name, fn.root, x)
+			}
+			if lower > x || x > upper || width != upper-lower || width > 1e-11 {
+				t.Errorf("%s on %s: expected a bracket of width at most 1e-11 around %v, got [%v, %v]", method, fn.name, x, lower, upper)
+			}
+			if evaluations <= 0 || (bisection > 0 && evaluations > 3*bisection) {
+				t.Errorf("%s on %s: unexpected %d evaluations", method, fn.name, evaluations)
+			}
+		}
+
+		s := scalar.Bisection(fn.f, fn.a, fn.b)
+		check("bisection", s.Status, s.X, s.Lower, s.Upper, s.Width, s.Evaluations)
+		bisection = s.Evaluations
+		s = scalar.Brent(fn.f, fn.a, fn.b)
+		check("brent", s.Status, s.X, s.Lower, s.Upper, s.Width, s.Evaluations)
+		if fn.name != "flat" && s.Evaluations >= bisection/2 {
+			t.Errorf("brent on %s: expected far fewer evaluations than bisection, got %d and %d", fn.name, s.Evaluations, bisection)
+		}
+		s = scalar.Ridders(fn.f, fn.a, fn.b)
+		check("ridders", s.Status, s.X, s.Lower, s.Upper, s.Width, s.Evaluations)
+		s = scalar.ITP(fn.f, fn.a, fn.b)
+		check("itp", s.Status, s.X, s.Lower, s.Upper, s.Width, s.Evaluations)
+		if s.Evaluations > bisection+scalar.ITP_N0+1 {
+			t.Errorf("itp on %s: expected at most %d evaluations, got %d", fn.name, bisection+scalar.ITP_N0+1, s.Evaluations)
+		}
+		s = scalar.Newton(fn.f, fn.df, fn.a, fn.b)
+		check("newton", s.Status, s.X, s.Lower, s.Upper, s.Width, s.Evaluations)
+		s = scalar.Secant(fn.f, fn.a, fn.b)
+		check("secant", s.Status, s.X, s.Lower, s.Upper, s.Width, s.Evaluations)
+	}
+}
+
+func TestRootNotBracketed(t *testing.T) {
+	f := func(x float64) float64 { return x*x + 1 }
+	if s := scalar.Brent(f, -1, 2); s.Status != scalar.NotBracketed || s.Evaluations != 2 {
+		t.Errorf("expected not bracketed after 2 evaluations, got %+v", s)
+	}
+	if s := scalar.Bisection(f, -1, 2, scalar.WithMaxIterations(3)); s.Status != scalar.NotBracketed {
+		t.Errorf("expected not bracketed, got %v", s.Status)
+	}
+	g := func(x float64) float64 { return math.Cos(x) - x }
+	if s := scalar.Bisection(g, 0, 1, scalar.WithMaxIterations(5)); s.Status != scalar.IterationLimit || s.Width > 1.0/32+1e-15 {
+		t.Errorf("expected the iteration limit with width 1/32, got %+v", s)
+	}
+}
+
+func TestBracketRoot(t *testing.T) {
+	f := func(x float64) float64 { return math.Exp(x) - 1000 }
+	br := scalar.BracketRoot(f, 0, 1)
+	if !br.Found || f(br.Lower) > 0 || f(br.Upper) < 0 {
+		t.Fatalf("expected a bracket of the root, got %+v", br)
+	}
+	s := scalar.Brent(f, br.Lower, br.Upper)
+	if math.Abs(s.X-math.Log(1000)) > 1e-11 {
+		t.Errorf("expected %v, got %v", math.Log(1000), s.X)
+	}
+
+	br = scalar.BracketRoot(func(x float64) float64 { return x*x + 1 }, 0, 1, scalar.WithMaxIterations(20))
+	if br.Found || br.Evaluations != 22 {
+		t.Errorf("expected no bracket after 22 evaluations, got %+v", br)
+	}
+}
@@ -0,0 +1,239 @@
+// Package scalar finds roots and minima of functions of one variable.
+//
+// The root finders start from a bracket [a, b] on which f changes sign and the
+// minimizers from an interval that contains a minimum; BracketRoot and BracketMinimum
+// find such intervals from a rough initial guess. Each method reports the number of
+// function evaluations it used and the width of the final bracket.
+package scalar
+
+import (
+	"math"
+)
+
+// Func is a function of one variable.
+type Func func(x float64) float64
+
+type Status int
+
+const (
+	Converged Status = iota
+	IterationLimit
+	NotBracketed // f has the same sign at both ends of the bracket
+)
+
+func (s Status) String() string {
+	switch s {
+	case Converged:
+		return "converged"
+	case IterationLimit:
+		return "iteration limit"
+	case NotBracketed:
+		return "not bracketed"
+	}
+	return "unknown"
+}
+
+// solution is the result of a root finder or minimizer. The root or minimum X lies in
+// [Lower, Upper], whose width is Width.
+type solution struct {
+	Status      Status
+	X           float64
+	Value       float64 // f(X)
+	Lower       float64
+	Upper       float64
+	Width       float64
+	Iterations  int
+	Evaluations int
+}
+
+// solveOptions are the options for a root finder or minimizer.
+type solveOptions struct {
+	tolerance     float64
+	maxIterations int
+}
+
+func newSolveOptions(options ...func(*solveOptions)) *solveOptions {
+	opts := &solveOptions{
+		tolerance:     1e-12,
+		maxIterations: 200,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithTolerance sets the absolute tolerance on X. The root finders also allow a
+// relative error of a few machine epsilons and the minimizers one of sqrt(epsilon),
+// below which f cannot resolve a minimum, so the tolerance may be zero.
+func WithTolerance(tolerance float64) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.tolerance = tolerance
+	}
+}
+
+func WithMaxIterations(maxIterations int) func(*solveOptions) {
+	return func(opts *solveOptions) {
+		opts.maxIterations = maxIterations
+	}
+}
+
+const epsilon = 2.220446049250313e-16
+
+// xTolerance returns the tolerance on a point near x.
+func (opts *solveOptions) xTolerance(x float64) float64 {
+	return 2*epsilon*math.Abs(x) + 0.5*opts.tolerance
+}
+
+// minTolerance returns the tolerance on a minimum near x.
+func (opts *solveOptions) minTolerance(x float64) float64 {
+	return 1.4901161193847656e-08*math.Abs(x) + 0.5*opts.tolerance
+}
+
+// counter wraps f to count its evaluations.
+type counter struct {
+	f           Func
+	evaluations int
+}
+
+func (c *counter) eval(x float64) float64 {
+	c.evaluations++
+	return c.f(x)
+}
+
+// newSolution returns a solution with X at the end of the bracket where |f| is smallest.
+// The bracket of an exact root is the root itself.
+func newSolution(status Status, a, fa, b, fb float64, iterations int, c *counter) *solution {
+	sol := &solution{
+		Status:      status,
+		X:           a,
+		Value:       fa,
+		Lower:       math.Min(a, b),
+		Upper:       math.Max(a, b),
+		Iterations:  iterations,
+		Evaluations: c.evaluations,
+	}
+	if math.Abs(fb) < math.Abs(fa) {
+		sol.X, sol.Value = b, fb
+	}
+	if sol.Value == 0 {
+		// an exact root
+		sol.Lower, sol.Upper = sol.X, sol.X
+	}
+	sol.Width = sol.Upper - sol.Lower
+	return sol
+}
+
+var BRACKET_GROWTH = 1.618034  // growth of the interval in each expansion step
+var BRACKET_MAX_GROWTH = 100.0 // largest parabolic extrapolation step relative to the interval
+
+// Bracket is an interval found by BracketRoot or BracketMinimum. For a minimum, Middle
+// lies between Lower and Upper with f(Middle) <= min(f(Lower), f(Upper)).
+type Bracket struct {
+	Lower       float64
+	Middle      float64
+	Upper       float64
+	Found       bool
+	Evaluations int
+}
+
+// BracketRoot expands the interval [a, b] geometrically away from the end where |f| is
+// larger until f changes sign over it, for at most the maximum number of iterations.
+func BracketRoot(f Func, a, b float64, options ...func(*solveOptions)) Bracket {
+	opts := newSolveOptions(options...)
+	if a == b {
+		b = a + 1
+	}
+	if a > b {
+		a, b = b, a
+	}
+	c := &counter{f: f}
+	fa, fb := c.eval(a), c.eval(b)
+	for k := 0; k < opts.maxIterations && !changesSign(fa, fb); k++ {
+		if math.Abs(fa) < math.Abs(fb) {
+			a += BRACKET_GROWTH * (a - b)
+			fa = c.eval(a)
+		} else {
+			b += BRACKET_GROWTH * (b - a)
+			fb = c.eval(b)
+		}
+	}
+	return Bracket{Lower: a, Middle: 0.5 * (a + b), Upper: b, Found: changesSign(fa, fb), Evaluations: c.evaluations}
+}
+
+// BracketMinimum walks downhill from a and b with golden-ratio steps and parabolic
+// extrapolation until it finds three points with the middle one lowest (Press et al.,
+// Numerical Recipes, 10.1).
+func BracketMinimum(f Func, a, b float64, options ...func(*solveOptions)) Bracket {
+	opts := newSolveOptions(options...)
+	if a == b {
+		b = a + 1
+	}
+	c := &counter{f: f}
+	fa, fb := c.eval(a), c.eval(b)
+	if fb > fa {
+		a, b, fa, fb = b, a, fb, fa
+	}
+	x := b + BRACKET_GROWTH*(b-a)
+	fx := c.eval(x)
+	for k := 0; k < opts.maxIterations && fb > fx; k++ {
+		// the vertex of the parabola through a, b, x, limited to a growth factor
+		r := (b - a) * (fb - fx)
+		q := (b - x) * (fb - fa)
+		d := q - r
+		if math.Abs(d) < 1e-20 {
+			d = math.Copysign(1e-20, d)
+		}
+		u := b - ((b-x)*q-(b-a)*r)/(2*d)
+		limit := b + BRACKET_MAX_GROWTH*(x-b)
+		var fu float64
+		switch {
+		case (b-u)*(u-x) > 0:
+			// the vertex is between b and x
+			fu = c.eval(u)
+			if fu < fx {
+				return minimumBracket(b, u, x, c)
+			}
+			if fu > fb {
+				return minimumBracket(a, b, u, c)
+			}
+			u = x + BRACKET_GROWTH*(x-b)
+			fu = c.eval(u)
+		case (x-u)*(u-limit) > 0:
+			// the vertex is beyond x, within the limit
+			fu = c.eval(u)
+			if fu < fx {
+				b, x, fb, fx = x, u, fx, fu
+				u = x + BRACKET_GROWTH*(x-b)
+				fu = c.eval(u)
+			}
+		case (u-limit)*(limit-x) >= 0:
+			u = limit
+			fu = c.eval(u)
+		default:
+			u = x + BRACKET_GROWTH*(x-b)
+			fu = c.eval(u)
+		}
+		a, b, x = b, x, u
+		fa, fb, fx = fb, fx, fu
+	}
+	br := minimumBracket(a, b, x, c)
+	br.Found = fb <= fx && fb <= fa
+	return br
+}
+
+func minimumBracket(a, b, x float64, c *counter) Bracket {
+	return Bracket{
+		Lower:       math.Min(a, x),
+		Middle:      b,
+		Upper:       math.Max(a, x),
+		Found:       true,
+		Evaluations: c.evaluations,
+	}
+}
+
+// changesSign returns whether a root lies between points with values fa and fb.
+func changesSign(fa, fb float64) bool {
+	return (fa <= 0 && fb >= 0) || (fa >= 0 && fb <= 0)
+}